@@ -2,22 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/galchammat/kadeem/internal/api"
+	"github.com/galchammat/kadeem/internal/api/middleware"
 	"github.com/galchammat/kadeem/internal/logging"
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	riotapi "github.com/galchammat/kadeem/internal/riot/api"
-	riotpostgres "github.com/galchammat/kadeem/internal/riot/postgres"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	matchsync "github.com/galchammat/kadeem/internal/riot/syncer/match"
 	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/streaming"
 	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/twitch/artifact"
+	"github.com/galchammat/kadeem/internal/twitch/chat"
 	twitchmodels "github.com/galchammat/kadeem/internal/twitch/models"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+	"github.com/golang-migrate/migrate/v4"
 	"github.com/joho/godotenv"
 )
 
@@ -27,17 +37,29 @@ func init() {
 
 type daemon struct {
 	db           *platformdb.DB
-	riotStore    *riotpostgres.DB
-	twitchStore  *twitchstore.Store
+	riotStore    riotstore.Store
+	twitchStore  twitchstore.Store
+	platforms    *streaming.Registry
 	matches      *service.MatchService
+	matchSyncer  *matchsync.MatchSyncer
 	ranks        *service.RankService
 	streamEvents *service.StreamEventsService
+	eventSub     *service.EventSubService
+	liveSessions *service.LiveSessionService
+	vods         *service.VODAlignmentService
+	artifacts    *service.ArtifactService
+	champions    *service.ChampionStatsService
 }
 
 func main() {
 	logging.Init(os.Stderr, slog.LevelInfo)
 	logging.Info("Starting Kadeem daemon")
 
+	if err := middleware.CheckSignedURLSecret(); err != nil {
+		logging.Error("Refusing to start, set SIGNED_URL_ALLOW_RANDOM_SECRET=true to use a random secret in development", "error", err)
+		os.Exit(1)
+	}
+
 	db, err := platformdb.OpenDB()
 	if err != nil {
 		logging.Error("Failed to connect to database", "error", err)
@@ -45,17 +67,48 @@ func main() {
 	}
 	defer db.SQL.Close()
 
+	// A local SQLite database has no separate migrate step, so bring its schema up on start.
+	if db.Driver == platformdb.DriverSQLite {
+		if err := migrateSQLite(db); err != nil {
+			logging.Error("Failed to migrate SQLite database", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	riotClient := riotapi.NewClient()
 	twitchClient := twitchapi.NewTwitchClient(context.Background())
-	riotStore := riotpostgres.New(db)
+	riotStore := riotstore.New(db)
+	replays, err := replay.NewFromEnv()
+	if err != nil {
+		logging.Error("Failed to create replay store", "error", err)
+		os.Exit(1)
+	}
 	twitchStore := twitchstore.New(db)
+	platforms := streaming.NewRegistryFromEnv(context.Background(), twitchClient)
+	eventSubConfig := service.EventSubConfigFromEnv()
+	var artifactSource artifact.Source
+	if source, err := artifact.NewTwitchSource(); err == nil {
+		artifactSource = source
+	}
+	matchSyncer, err := matchsync.NewMatchSyncer(riotClient, riotStore)
+	if err != nil {
+		logging.Error("Failed to create match syncer", "error", err)
+		os.Exit(1)
+	}
 	d := &daemon{
 		db:           db,
 		riotStore:    riotStore,
 		twitchStore:  twitchStore,
-		matches:      service.NewMatchService(riotStore, riotClient),
+		platforms:    platforms,
+		matches:      service.NewMatchService(riotStore, riotClient, replays),
+		matchSyncer:  matchSyncer,
 		ranks:        service.NewRankService(riotStore, riotClient),
-		streamEvents: service.NewStreamEventsService(twitchStore, twitchClient),
+		streamEvents: service.NewStreamEventsService(twitchStore, platforms),
+		eventSub:     service.NewEventSubService(twitchStore, twitchClient, eventSubConfig),
+		liveSessions: service.NewLiveSessionService(twitchStore, platforms),
+		vods:         service.NewVODAlignmentService(riotStore),
+		champions:    service.NewChampionStatsService(riotStore),
+		artifacts:    service.NewArtifactService(twitchStore, riotStore, replays, artifactSource, service.ArtifactConfigFromEnv()),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -69,6 +122,21 @@ func main() {
 		d.runSyncLoop(ctx, 15*time.Minute, "match", d.syncMatches)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.runSyncLoop(ctx, 15*time.Minute, "match_discovery", func() { d.discoverMatches(ctx) })
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cfg := matchQueueConfig()
+		logging.Info("Starting match queue workers", "workers", cfg.Workers, "lease", cfg.Lease)
+		d.matchSyncer.RunQueue(ctx, cfg)
+		logging.Info("Match queue workers stopped")
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -81,6 +149,62 @@ func main() {
 		d.runSyncLoop(ctx, 30*time.Minute, "stream_events", d.syncStreamEvents)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.runSyncLoop(ctx, service.LivePollInterval(), "live_sessions", d.pollLiveSessions)
+	}()
+
+	if eventSubConfig.Enabled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.runSyncLoop(ctx, 15*time.Minute, "eventsub_subscriptions", d.syncEventSubSubscriptions)
+		}()
+	} else {
+		logging.Info("TWITCH_EVENTSUB_CALLBACK_URL or TWITCH_EVENTSUB_SECRET not set, not subscribing to EventSub")
+	}
+
+	if chatEnabled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logging.Info("Starting Twitch chat ingestion")
+			chat.NewIngester(twitchStore, chat.ConfigFromEnv()).Run(ctx)
+			logging.Info("Twitch chat ingestion stopped")
+		}()
+	} else {
+		logging.Info("TWITCH_CHAT_ENABLED is false, not ingesting Twitch chat")
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.runSyncLoop(ctx, 15*time.Minute, "vod_alignment", d.alignVODs)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.runSyncLoop(ctx, 15*time.Minute, "champion_stats", func() { d.refreshChampionStats(ctx) })
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.runSyncLoop(ctx, replayGCInterval(), "replay_gc", func() { d.collectReplays(ctx) })
+	}()
+
+	if artifactSource != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.runSyncLoop(ctx, 30*time.Minute, "stream_artifacts", func() { d.downloadArtifacts(ctx) })
+		}()
+	} else {
+		logging.Info("TWITCH_GQL_CLIENT_ID not set, not downloading stream artifacts")
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -89,7 +213,7 @@ func main() {
 			port = "8080"
 		}
 		logging.Info("Starting API server", "port", port)
-		if err := api.StartServer(ctx, db, riotStore, twitchStore, port); err != nil {
+		if err := api.StartServer(ctx, db, riotStore, twitchStore, replays, port); err != nil {
 			logging.Error("API server stopped", "error", err)
 		}
 	}()
@@ -131,8 +255,10 @@ func (d *daemon) runSyncLoop(ctx context.Context, interval time.Duration, name s
 	}
 }
 
+// syncMatches downloads new replays of tracked accounts. Match details are left to the
+// queue workers; unknown matches are only enqueued.
 func (d *daemon) syncMatches() {
-	logging.Info("Starting match sync")
+	logging.Info("Starting match replay sync")
 	accounts, err := d.riotStore.GetTrackedAccountsForSync()
 	if err != nil {
 		logging.Error("Failed to list accounts for sync", "error", err)
@@ -145,7 +271,85 @@ func (d *daemon) syncMatches() {
 			logging.Info("Synced matches", "puuid", account.PUUID)
 		}
 	}
-	logging.Info("Match sync completed")
+	logging.Info("Match replay sync completed")
+}
+
+func (d *daemon) discoverMatches(ctx context.Context) {
+	logging.Info("Starting match discovery")
+	if err := d.matchSyncer.Sync(ctx); err != nil {
+		logging.Error("Failed to discover matches", "error", err)
+		return
+	}
+	logging.Info("Match discovery completed")
+}
+
+// matchQueueConfig reads MATCH_QUEUE_WORKERS and MATCH_QUEUE_LEASE (a Go duration),
+// falling back to the syncer defaults.
+func matchQueueConfig() matchsync.QueueConfig {
+	cfg := matchsync.DefaultQueueConfig
+	if raw := os.Getenv("MATCH_QUEUE_WORKERS"); raw != "" {
+		if workers, err := strconv.Atoi(raw); err == nil && workers > 0 {
+			cfg.Workers = workers
+		} else {
+			logging.Warn("Ignoring invalid MATCH_QUEUE_WORKERS", "value", raw)
+		}
+	}
+	if raw := os.Getenv("MATCH_QUEUE_LEASE"); raw != "" {
+		if lease, err := time.ParseDuration(raw); err == nil && lease > 0 {
+			cfg.Lease = lease
+		} else {
+			logging.Warn("Ignoring invalid MATCH_QUEUE_LEASE", "value", raw)
+		}
+	}
+	return cfg
+}
+
+// replayGCInterval reads REPLAY_GC_INTERVAL (a Go duration), defaulting to 6h.
+func replayGCInterval() time.Duration {
+	if raw := os.Getenv("REPLAY_GC_INTERVAL"); raw != "" {
+		if interval, err := time.ParseDuration(raw); err == nil && interval > 0 {
+			return interval
+		}
+		logging.Warn("Ignoring invalid REPLAY_GC_INTERVAL", "value", raw)
+	}
+	return 6 * time.Hour
+}
+
+// chatEnabled reads TWITCH_CHAT_ENABLED, defaulting to true.
+func chatEnabled() bool {
+	if raw := os.Getenv("TWITCH_CHAT_ENABLED"); raw != "" {
+		if enabled, err := strconv.ParseBool(raw); err == nil {
+			return enabled
+		}
+		logging.Warn("Ignoring invalid TWITCH_CHAT_ENABLED", "value", raw)
+	}
+	return true
+}
+
+// collectReplays applies the replay retention policy. With REPLAY_GC_DRY_RUN=true it only
+// logs what would be deleted.
+func (d *daemon) collectReplays(ctx context.Context) {
+	dryRun, _ := strconv.ParseBool(os.Getenv("REPLAY_GC_DRY_RUN"))
+	logging.Info("Starting replay garbage collection", "dryRun", dryRun)
+	report, err := d.matches.CollectReplays(ctx, service.ReplayRetentionFromEnv(), dryRun)
+	if err != nil {
+		logging.Error("Failed to collect replays", "error", err)
+		return
+	}
+	for _, e := range report.Expired {
+		logging.Info("Expired replay", "dryRun", dryRun, "matchID", e.MatchID, "key", e.Key, "size", e.Size, "reason", e.Reason)
+	}
+	logging.Info(
+		"Replay garbage collection completed",
+		"dryRun", dryRun,
+		"scanned", report.Scanned,
+		"kept", report.Kept,
+		"keptBytes", report.KeptBytes,
+		"expired", len(report.Expired),
+		"expiredBytes", report.ExpiredBytes,
+		"missing", len(report.Missing),
+		"failed", len(report.Failed),
+	)
 }
 
 func (d *daemon) syncRanks() {
@@ -167,18 +371,110 @@ func (d *daemon) syncRanks() {
 
 func (d *daemon) syncStreamEvents() {
 	logging.Info("Starting stream events sync")
-	platform := "twitch"
-	channels, err := d.twitchStore.ListChannels(&twitchmodels.ChannelFilter{Platform: &platform}, 1000, 0)
+	for _, platform := range d.platforms.All() {
+		name := platform.Name()
+		channels, err := d.twitchStore.ListChannels(&twitchmodels.ChannelFilter{Platform: &name}, 1000, 0)
+		if err != nil {
+			logging.Error("Failed to list channels for stream events sync", "platform", name, "error", err)
+			continue
+		}
+		for _, ch := range channels {
+			if err := d.streamEvents.SyncChannelEvents(ch.ID); err != nil {
+				logging.Error("Failed to sync stream events", "platform", name, "channel_id", ch.ID, "error", err)
+			} else {
+				logging.Info("Synced stream events", "platform", name, "channel_id", ch.ID)
+			}
+		}
+	}
+	logging.Info("Stream events sync completed")
+}
+
+func (d *daemon) pollLiveSessions() {
+	result, err := d.liveSessions.Poll()
 	if err != nil {
-		logging.Error("Failed to list channels for stream events sync", "error", err)
+		logging.Error("Failed to poll live sessions", "error", err)
 		return
 	}
-	for _, ch := range channels {
-		if err := d.streamEvents.SyncChannelEvents(ch.ID); err != nil {
-			logging.Error("Failed to sync stream events", "channel_id", ch.ID, "error", err)
+	logging.Info("Polled live sessions", "live", result.Live, "started", result.Started, "ended", result.Ended)
+}
+
+func (d *daemon) syncEventSubSubscriptions() {
+	logging.Info("Starting EventSub subscription sync")
+	result, err := d.eventSub.SyncSubscriptions()
+	if err != nil {
+		logging.Error("Failed to sync EventSub subscriptions", "error", err)
+		return
+	}
+	logging.Info("EventSub subscription sync completed", "created", result.Created, "deleted", result.Deleted, "kept", result.Kept)
+}
+
+func (d *daemon) alignVODs() {
+	logging.Info("Starting VOD alignment")
+	streamers, err := d.twitchStore.GetTrackedStreamersForSync()
+	if err != nil {
+		logging.Error("Failed to list streamers for VOD alignment", "error", err)
+		return
+	}
+	for _, streamer := range streamers {
+		n, err := d.vods.AlignStreamer(streamer.ID)
+		if err != nil {
+			logging.Error("Failed to align VODs", "streamer_id", streamer.ID, "error", err)
 		} else {
-			logging.Info("Synced stream events", "channel_id", ch.ID)
+			logging.Info("Aligned VODs", "streamer_id", streamer.ID, "matches", n)
 		}
 	}
-	logging.Info("Stream events sync completed")
+	logging.Info("VOD alignment completed")
+}
+
+// refreshChampionStats adds newly synced matches of tracked accounts to their champion stats,
+// so reading them only has the last few matches left to add.
+func (d *daemon) refreshChampionStats(ctx context.Context) {
+	logging.Info("Starting champion stats refresh")
+	accounts, err := d.riotStore.GetTrackedAccountsForSync()
+	if err != nil {
+		logging.Error("Failed to list accounts for champion stats refresh", "error", err)
+		return
+	}
+	for _, account := range accounts {
+		n, err := d.champions.Refresh(ctx, account.PUUID)
+		if err != nil {
+			logging.Error("Failed to refresh champion stats", "puuid", account.PUUID, "error", err)
+		} else if n > 0 {
+			logging.Info("Refreshed champion stats", "puuid", account.PUUID, "matches", n)
+		}
+	}
+	logging.Info("Champion stats refresh completed")
+}
+
+// downloadArtifacts queues new clips and highlights, then downloads whatever is due.
+func (d *daemon) downloadArtifacts(ctx context.Context) {
+	logging.Info("Starting stream artifact sync")
+	added, err := d.artifacts.Discover()
+	if err != nil {
+		logging.Error("Failed to discover stream artifacts", "error", err)
+	}
+	result, err := d.artifacts.DownloadPending(ctx)
+	if err != nil {
+		logging.Error("Failed to download stream artifacts", "error", err)
+		return
+	}
+	logging.Info("Stream artifact sync completed", "queued", added, "downloaded", result.Downloaded, "retrying", result.Retrying, "failed", result.Failed)
+}
+
+// migrateSQLite applies the SQLite migrations from MIGRATIONS_DIR (default ./migrations).
+func migrateSQLite(db *platformdb.DB) error {
+	dir := os.Getenv("MIGRATIONS_DIR")
+	if dir == "" {
+		dir = "./migrations"
+	}
+	m, err := platformdb.NewMigrate(db, dir)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	version, _, _ := m.Version()
+	logging.Info("SQLite schema is up to date", "version", version)
+	return nil
 }
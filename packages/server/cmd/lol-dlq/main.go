@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/models"
+	"github.com/galchammat/kadeem/internal/platform/database"
+	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/joho/godotenv"
+)
+
+const usage = "Usage: lol-dlq [list [limit]|requeue <matchID|all>]"
+
+func init() {
+	_ = godotenv.Load()
+}
+
+func main() {
+	logging.Init(os.Stderr, slog.LevelInfo)
+
+	command := "list"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if command != "list" && command != "requeue" {
+		logging.Error("Invalid command", "command", command)
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	if command == "requeue" && len(os.Args) < 3 {
+		logging.Error("requeue command requires a match ID or 'all'")
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.OpenDB()
+	if err != nil {
+		logging.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.SQL.Close()
+	store := riotstore.New(db)
+
+	switch command {
+	case "list":
+		limit := 100
+		if len(os.Args) > 2 {
+			if limit, err = strconv.Atoi(os.Args[2]); err != nil {
+				logging.Error("Invalid limit", "limit", os.Args[2], "error", err)
+				os.Exit(1)
+			}
+		}
+		status := models.StatusDLQ
+		matches, err := store.ListLolMatches(&riotmodels.MatchFilter{Status: &status}, limit, 0)
+		if err != nil {
+			logging.Error("failed to list dlq matches", "error", err)
+			os.Exit(1)
+		}
+		for _, match := range matches {
+			lastError := ""
+			if match.Summary.LastError != nil {
+				lastError = *match.Summary.LastError
+			}
+			fmt.Printf("%s_%d\tattempts=%d\t%s\n", match.Summary.Region, match.Summary.ID, match.Summary.Attempts, lastError)
+		}
+		logging.Info("listed dlq matches", "count", len(matches))
+
+	case "requeue":
+		var matchID *int64
+		if os.Args[2] != "all" {
+			id, err := strconv.ParseInt(os.Args[2], 10, 64)
+			if err != nil {
+				logging.Error("Invalid match ID", "matchID", os.Args[2], "error", err)
+				os.Exit(1)
+			}
+			matchID = &id
+		}
+		n, err := store.RequeueMatch(ctx, matchID)
+		if err != nil {
+			logging.Error("failed to requeue dlq matches", "error", err)
+			os.Exit(1)
+		}
+		logging.Info("requeued dlq matches", "count", n)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/riot/datadragon"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	_ = godotenv.Load()
+}
+
+// lol-replay-backfill fills participant rows from local replay files for matches
+// the Riot match API no longer serves.
+func main() {
+	logging.Init(os.Stderr, slog.LevelInfo)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.OpenDB()
+	if err != nil {
+		logging.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.SQL.Close()
+
+	replays, err := replay.NewFromEnv()
+	if err != nil {
+		logging.Error("failed to create replay store", "error", err)
+		os.Exit(1)
+	}
+
+	matches := service.NewMatchService(riotstore.New(db), api.NewClient(), replays)
+	champions := datadragon.NewDataDragonClient(ctx, "bin/datadragon")
+
+	n, err := matches.BackfillParticipantsFromReplays(ctx, champions)
+	if err != nil {
+		logging.Error("failed to backfill participants from replays", "backfilled", n, "error", err)
+		os.Exit(1)
+	}
+	logging.Info("backfilled participants from replays", "count", n)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/joho/godotenv"
+)
+
+const usage = "Usage: lol-replay-gc [report|run]"
+
+func init() {
+	_ = godotenv.Load()
+}
+
+// lol-replay-gc applies the replay retention policy once. "report" (the default) only
+// prints what would be deleted.
+func main() {
+	logging.Init(os.Stderr, slog.LevelInfo)
+
+	command := "report"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if command != "report" && command != "run" {
+		logging.Error("Invalid command", "command", command)
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.OpenDB()
+	if err != nil {
+		logging.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.SQL.Close()
+
+	replays, err := replay.NewFromEnv()
+	if err != nil {
+		logging.Error("failed to create replay store", "error", err)
+		os.Exit(1)
+	}
+
+	matches := service.NewMatchService(riotstore.New(db), api.NewClient(), replays)
+	report, err := matches.CollectReplays(ctx, service.ReplayRetentionFromEnv(), command == "report")
+	if err != nil {
+		logging.Error("failed to collect replays", "error", err)
+		os.Exit(1)
+	}
+
+	for _, e := range report.Expired {
+		fmt.Printf("%d\t%s\t%d\t%s\n", e.MatchID, e.Key, e.Size, e.Reason)
+	}
+	logging.Info(
+		"collected replays",
+		"dryRun", report.DryRun,
+		"scanned", report.Scanned,
+		"kept", report.Kept,
+		"keptBytes", report.KeptBytes,
+		"expired", len(report.Expired),
+		"expiredBytes", report.ExpiredBytes,
+		"missing", len(report.Missing),
+		"failed", len(report.Failed),
+	)
+}
@@ -8,10 +8,7 @@ import (
 	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 
 	"github.com/galchammat/kadeem/internal/logging"
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
@@ -52,18 +49,7 @@ func main() {
 	}
 	defer db.SQL.Close()
 
-	driver, err := postgres.WithInstance(db.SQL, &postgres.Config{
-		MigrationsTable: "schema_migrations",
-		DatabaseName:    "kadeem",
-	})
-	if err != nil {
-		logging.Error("Error creating database driver", "error", err)
-		os.Exit(1)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://./migrations",
-		"postgres", driver)
+	m, err := platformdb.NewMigrate(db, "./migrations")
 	if err != nil {
 		logging.Error("Error creating migration instance", "error", err)
 		os.Exit(1)
@@ -162,6 +148,15 @@ func main() {
 	case "drop-all":
 		// Drop all tables in the public schema
 		logging.Info("Dropping all tables in public schema...")
+		if db.Driver == platformdb.DriverSQLite {
+			// The SQLite driver drops every table itself.
+			if err := m.Drop(); err != nil {
+				logging.Error("Error dropping all tables", "error", err)
+				os.Exit(1)
+			}
+			logging.Info("All tables dropped successfully")
+			return
+		}
 		_, err := db.SQL.Exec(`
 			DO $$ DECLARE
 				r RECORD;
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/riottest"
+)
+
+const usage = "Usage: riot-fake [addr [fixtures dir]]"
+
+// riot-fake serves the Riot API fixtures for local development. Point the daemon at it
+// with RIOT_API_BASE_URL=http://localhost:8089.
+func main() {
+	logging.Init(os.Stderr, slog.LevelInfo)
+
+	addr, dir := "localhost:8089", "./tests/data/riot/raw"
+	if len(os.Args) > 3 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	if len(os.Args) > 1 {
+		addr = os.Args[1]
+	}
+	if len(os.Args) > 2 {
+		dir = os.Args[2]
+	}
+
+	fake := riottest.NewHandler()
+	if err := fake.LoadFixtures(dir); err != nil {
+		logging.Error("Failed to load fixtures", "dir", dir, "error", err)
+		os.Exit(1)
+	}
+
+	logging.Info("Serving fake Riot API", "addr", addr, "fixtures", dir)
+	if err := http.ListenAndServe(addr, fake); err != nil {
+		logging.Error("Fake Riot API stopped", "error", err)
+		os.Exit(1)
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/galchammat/kadeem/internal/logging"
 	"github.com/galchammat/kadeem/internal/platform/database"
 	"github.com/galchammat/kadeem/internal/riot/api"
-	"github.com/galchammat/kadeem/internal/riot/postgres"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 	"github.com/galchammat/kadeem/internal/riot/syncer/match"
 	"github.com/joho/godotenv"
 )
@@ -32,7 +32,7 @@ func main() {
 	}
 	defer db.SQL.Close()
 
-	store := postgres.New(db)
+	store := riotstore.New(db)
 	apiClient := api.NewClient()
 
 	matchSyncer, err := matchsync.NewMatchSyncer(apiClient, store)
@@ -46,4 +46,11 @@ func main() {
 	}
 
 	logging.Info("synced lol match ids")
+
+	if err := matchSyncer.Drain(ctx, matchsync.DefaultQueueConfig); err != nil {
+		logging.Error("failed to process queued lol matches", "error", err)
+		os.Exit(1)
+	}
+
+	logging.Info("processed queued lol matches")
 }
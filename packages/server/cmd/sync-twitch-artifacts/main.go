@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/galchammat/kadeem/internal/logging"
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/twitch/artifact"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 	"github.com/joho/godotenv"
 )
 
@@ -12,8 +21,44 @@ func init() {
 	_ = godotenv.Load()
 }
 
+// sync-twitch-artifacts queues the clips and match highlights of tracked channels once and
+// downloads every artifact that is due into the replay store.
 func main() {
 	logging.Init(os.Stderr, slog.LevelInfo)
-	logging.Error("twitch artifact sync is not implemented yet")
-	os.Exit(1)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := platformdb.OpenDB()
+	if err != nil {
+		logging.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.SQL.Close()
+
+	files, err := replay.NewFromEnv()
+	if err != nil {
+		logging.Error("failed to create replay store", "error", err)
+		os.Exit(1)
+	}
+
+	source, err := artifact.NewTwitchSource()
+	if err != nil {
+		logging.Error("failed to create twitch artifact source", "error", err)
+		os.Exit(1)
+	}
+
+	artifacts := service.NewArtifactService(twitchstore.New(db), riotstore.New(db), files, source, service.ArtifactConfigFromEnv())
+	added, err := artifacts.Discover()
+	if err != nil {
+		logging.Error("failed to discover twitch artifacts", "error", err)
+		os.Exit(1)
+	}
+	result, err := artifacts.DownloadPending(ctx)
+	if err != nil {
+		logging.Error("failed to download twitch artifacts", "error", err)
+		os.Exit(1)
+	}
+
+	logging.Info("synced twitch artifacts", "queued", added, "downloaded", result.Downloaded, "retrying", result.Retrying, "failed", result.Failed)
 }
@@ -49,7 +49,7 @@ type twitchEventStore interface {
 }
 
 func syncTwitchEvents(ctx context.Context, client *twitchapi.TwitchClient, store twitchEventStore) error {
-	platform := "twitch"
+	platform := twitchmodels.PlatformTwitch
 	channels, err := store.ListChannels(&twitchmodels.ChannelFilter{Platform: &platform}, 1000, 0)
 	if err != nil {
 		return fmt.Errorf("list twitch channels: %w", err)
@@ -60,17 +60,12 @@ func syncTwitchEvents(ctx context.Context, client *twitchapi.TwitchClient, store
 			return err
 		}
 
-		hypeEvents, err := client.FetchHypeTrainEvents(channel.ID)
+		// A manual sync backfills every event Twitch keeps; the daemon only fetches new ones.
+		events, err := client.FetchEvents(channel.ID, 0)
 		if err != nil {
-			return fmt.Errorf("fetch hype train events for channel %q: %w", channel.ID, err)
+			return fmt.Errorf("fetch events for channel %q: %w", channel.ID, err)
 		}
 
-		clipEvents, err := client.FetchTopClips(channel.ID)
-		if err != nil {
-			return fmt.Errorf("fetch clip events for channel %q: %w", channel.ID, err)
-		}
-
-		events := append(hypeEvents, clipEvents...)
 		if err := store.UpsertStreamEvents(events); err != nil {
 			return fmt.Errorf("upsert stream events for channel %q: %w", channel.ID, err)
 		}
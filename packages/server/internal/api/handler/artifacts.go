@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/api/middleware"
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// ArtifactHandler handles downloaded clip and VOD segment HTTP requests.
+type ArtifactHandler struct {
+	artifacts *service.ArtifactService
+	signer    *middleware.URLSigner
+}
+
+// NewArtifactHandler creates a new ArtifactHandler.
+func NewArtifactHandler(artifacts *service.ArtifactService, signer *middleware.URLSigner) *ArtifactHandler {
+	return &ArtifactHandler{artifacts: artifacts, signer: signer}
+}
+
+// ListArtifacts returns artifacts, newest first, with the number of artifacts in each status.
+// They can be filtered by streamerId, channelId, matchId, kind and status.
+func (h *ArtifactHandler) ListArtifacts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.ArtifactFilter{}
+	if raw := query.Get("streamerId"); raw != "" {
+		streamerID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid streamer ID")
+			return
+		}
+		filter.StreamerID = &streamerID
+	}
+	if channelID := query.Get("channelId"); channelID != "" {
+		filter.ChannelID = &channelID
+	}
+	if raw := query.Get("matchId"); raw != "" {
+		matchID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid match ID")
+			return
+		}
+		filter.MatchID = &matchID
+	}
+	if raw := query.Get("kind"); raw != "" {
+		kind := models.ArtifactKind(raw)
+		if kind != models.ArtifactClip && kind != models.ArtifactVODSegment {
+			respondError(w, http.StatusBadRequest, "invalid kind")
+			return
+		}
+		filter.Kind = &kind
+	}
+	if raw := query.Get("status"); raw != "" {
+		status := models.ArtifactStatus(raw)
+		if status != models.ArtifactPending && status != models.ArtifactDownloaded && status != models.ArtifactFailed {
+			respondError(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+		filter.Status = &status
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	artifacts, err := h.artifacts.ListArtifacts(filter, limit, offset)
+	if err != nil {
+		logging.Error("failed to list artifacts", "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to list artifacts")
+		return
+	}
+	statuses, err := h.artifacts.CountArtifacts(filter)
+	if err != nil {
+		logging.Error("failed to count artifacts", "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to list artifacts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"artifacts": artifacts,
+		"count":     len(artifacts),
+		"statuses":  statuses,
+	})
+}
+
+// RetryArtifact queues a failed artifact for download again.
+func (h *ArtifactHandler) RetryArtifact(w http.ResponseWriter, r *http.Request) {
+	artifactID, err := strconv.ParseInt(chi.URLParam(r, "artifactID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid artifact ID")
+		return
+	}
+
+	ok, err := h.artifacts.RetryArtifact(artifactID)
+	if err != nil {
+		logging.Error("failed to retry artifact", "artifact_id", artifactID, "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to retry artifact")
+		return
+	}
+	if !ok {
+		respondError(w, http.StatusNotFound, "no failed artifact with this ID")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"status": models.ArtifactPending})
+}
+
+// ServeArtifactFile streams a downloaded artifact. Range and conditional requests are handled
+// by http.ServeContent.
+func (h *ArtifactHandler) ServeArtifactFile(w http.ResponseWriter, r *http.Request) {
+	artifactID, err := strconv.ParseInt(chi.URLParam(r, "artifactID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid artifact ID")
+		return
+	}
+
+	obj, a, err := h.artifacts.OpenArtifact(r.Context(), artifactID)
+	if errors.Is(err, replay.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Artifact not found")
+		return
+	}
+	if err != nil {
+		logging.Error("Failed to open artifact", "artifactID", artifactID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to open artifact")
+		return
+	}
+	defer obj.Close()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(replayWriteTimeout)); err != nil {
+		logging.Warn("Failed to extend write deadline for artifact", "artifactID", artifactID, "error", err)
+	}
+
+	contentType := "video/mp2t"
+	if a.Kind == models.ArtifactClip {
+		contentType = "video/mp4"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, path.Base(a.StorageKey)))
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeContent(w, r, "", obj.Info.ModTime, obj)
+}
+
+// CreateArtifactLink returns a short-lived signed URL for a downloaded artifact.
+func (h *ArtifactHandler) CreateArtifactLink(w http.ResponseWriter, r *http.Request) {
+	artifactID, err := strconv.ParseInt(chi.URLParam(r, "artifactID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid artifact ID")
+		return
+	}
+
+	obj, _, err := h.artifacts.OpenArtifact(r.Context(), artifactID)
+	if errors.Is(err, replay.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Artifact not found")
+		return
+	}
+	if err != nil {
+		logging.Error("Failed to open artifact", "artifactID", artifactID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create artifact link")
+		return
+	}
+	obj.Close()
+
+	path := fmt.Sprintf("/api/v0/artifacts/%d/file", artifactID)
+	query, expires := h.signer.Sign(path)
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"url":       path + "?" + query.Encode(),
+		"expiresAt": expires.Unix(),
+	})
+}
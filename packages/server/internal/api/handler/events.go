@@ -20,7 +20,7 @@ func NewEventsHandler(events *service.StreamEventsService) *EventsHandler {
 	return &EventsHandler{events: events}
 }
 
-// SyncChannelEvents triggers a sync of hype train and clip events for the given channel.
+// SyncChannelEvents triggers a sync of the platform events of the given channel.
 func (h *EventsHandler) SyncChannelEvents(w http.ResponseWriter, r *http.Request) {
 	channelID := chi.URLParam(r, "channelID")
 	if err := h.events.SyncChannelEvents(channelID); err != nil {
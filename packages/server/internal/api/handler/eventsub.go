@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// eventSubMaxAge is how old a message may be before it is rejected as a replay. Message
+	// IDs are remembered for as long, so redeliveries within the window are dropped.
+	eventSubMaxAge = 10 * time.Minute
+	// eventSubMaxBody caps the webhook request body; notifications are a few KB.
+	eventSubMaxBody = 1 << 20
+)
+
+// EventSubHandler receives Twitch EventSub webhooks and manages the subscriptions.
+type EventSubHandler struct {
+	events *service.EventSubService
+	secret []byte
+	now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewEventSubHandler creates a new EventSubHandler verifying messages with secret.
+func NewEventSubHandler(events *service.EventSubService, secret string) *EventSubHandler {
+	return &EventSubHandler{
+		events: events,
+		secret: []byte(secret),
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Webhook is the EventSub callback. It checks the Twitch-Eventsub-Message-Signature HMAC and
+// the message age, answers the verification challenge and hands notifications to the
+// service once per message ID.
+func (h *EventSubHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if len(h.secret) == 0 {
+		respondError(w, http.StatusServiceUnavailable, "eventsub is not configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, eventSubMaxBody+1))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	if len(body) > eventSubMaxBody {
+		respondError(w, http.StatusRequestEntityTooLarge, "body too large")
+		return
+	}
+
+	messageID := r.Header.Get("Twitch-Eventsub-Message-Id")
+	timestamp := r.Header.Get("Twitch-Eventsub-Message-Timestamp")
+	if !h.validSignature(messageID, timestamp, body, r.Header.Get("Twitch-Eventsub-Message-Signature")) {
+		respondError(w, http.StatusForbidden, "invalid signature")
+		return
+	}
+	sent, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid message timestamp")
+		return
+	}
+	if age := h.now().Sub(sent); age > eventSubMaxAge || age < -eventSubMaxAge {
+		respondError(w, http.StatusForbidden, "message too old")
+		return
+	}
+
+	var msg models.EventSubMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid message body")
+		return
+	}
+
+	switch r.Header.Get("Twitch-Eventsub-Message-Type") {
+	case models.EventSubMessageVerification:
+		logging.Info("Verifying EventSub subscription", "id", msg.Subscription.ID, "type", msg.Subscription.Type)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, msg.Challenge)
+
+	case models.EventSubMessageRevocation:
+		logging.Warn("EventSub subscription revoked", "id", msg.Subscription.ID, "type", msg.Subscription.Type,
+			"status", msg.Subscription.Status, "channel_id", msg.Subscription.Condition.BroadcasterUserID)
+		w.WriteHeader(http.StatusNoContent)
+
+	case models.EventSubMessageNotification:
+		if !h.claim(messageID, sent) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if err := h.events.HandleNotification(messageID, sent, msg); err != nil {
+			// Let Twitch retry the message.
+			h.release(messageID)
+			logging.Error("Failed to handle EventSub notification", "message_id", messageID, "type", msg.Subscription.Type, "error", err)
+			respondError(w, http.StatusInternalServerError, "failed to handle notification")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		respondError(w, http.StatusBadRequest, "unknown message type")
+	}
+}
+
+// validSignature checks signature ("sha256=<hex>") against the HMAC of id, timestamp and body.
+func (h *EventSubHandler) validSignature(messageID, timestamp string, body []byte, signature string) bool {
+	if messageID == "" || timestamp == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// claim records messageID and reports whether it was new. Entries older than eventSubMaxAge
+// are forgotten, since such messages are rejected by age anyway.
+func (h *EventSubHandler) claim(messageID string, sent time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := h.now().Add(-eventSubMaxAge)
+	for id, at := range h.seen {
+		if at.Before(cutoff) {
+			delete(h.seen, id)
+		}
+	}
+	if _, ok := h.seen[messageID]; ok {
+		return false
+	}
+	h.seen[messageID] = sent
+	return true
+}
+
+func (h *EventSubHandler) release(messageID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.seen, messageID)
+}
+
+// ListSubscriptions returns the app's EventSub subscriptions.
+func (h *EventSubHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.events.ListSubscriptions()
+	if err != nil {
+		logging.Error("failed to list eventsub subscriptions", "error", err)
+		respondError(w, http.StatusBadGateway, "failed to list subscriptions")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"subscriptions": subscriptions,
+		"count":         len(subscriptions),
+	})
+}
+
+// SyncSubscriptions creates and deletes subscriptions so every tracked channel has one per
+// supported event type.
+func (h *EventSubHandler) SyncSubscriptions(w http.ResponseWriter, r *http.Request) {
+	result, err := h.events.SyncSubscriptions()
+	if err != nil {
+		logging.Error("failed to sync eventsub subscriptions", "error", err)
+		respondError(w, http.StatusBadGateway, "failed to sync subscriptions")
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+// DeleteSubscription removes one EventSub subscription.
+func (h *EventSubHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "subscriptionID")
+	if err := h.events.DeleteSubscription(id); err != nil {
+		logging.Error("failed to delete eventsub subscription", "id", id, "error", err)
+		respondError(w, http.StatusBadGateway, "failed to delete subscription")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
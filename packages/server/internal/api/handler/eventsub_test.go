@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/service"
+	twitch "github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+	"github.com/galchammat/kadeem/internal/twitch/twitchtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testEventSubSecret   = "eventsub-test-secret"
+	testEventSubCallback = "https://kadeem.test/api/v0/twitch/eventsub"
+	testChannelID        = "100000001"
+)
+
+func newEventSubTest(t *testing.T) (*EventSubHandler, twitchstore.Store, *twitchtest.Server) {
+	t.Helper()
+	db, err := platformdb.OpenSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.SQL.Close() })
+	m, err := platformdb.NewMigrate(db, "../../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	store := twitchstore.New(db)
+	streamer, err := store.FindOrCreateStreamer("kadeemtest")
+	require.NoError(t, err)
+	_, err = store.SaveChannel(twitch.Channel{ID: testChannelID, StreamerID: streamer.ID, Platform: "twitch", ChannelName: "KadeemTest"})
+	require.NoError(t, err)
+
+	fake := twitchtest.NewServer()
+	t.Cleanup(fake.Close)
+	events := service.NewEventSubService(store, fake.Client(context.Background()), service.EventSubConfig{
+		CallbackURL: testEventSubCallback,
+		Secret:      testEventSubSecret,
+	})
+	return NewEventSubHandler(events, testEventSubSecret), store, fake
+}
+
+// deliver sends a signed EventSub message to the webhook.
+func deliver(h *EventSubHandler, messageType, messageID string, sent time.Time, body string) *httptest.ResponseRecorder {
+	timestamp := sent.UTC().Format(time.RFC3339Nano)
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/twitch/eventsub", strings.NewReader(body))
+	req.Header.Set("Twitch-Eventsub-Message-Id", messageID)
+	req.Header.Set("Twitch-Eventsub-Message-Timestamp", timestamp)
+	req.Header.Set("Twitch-Eventsub-Message-Type", messageType)
+	req.Header.Set("Twitch-Eventsub-Message-Signature", twitchtest.SignEventSub(testEventSubSecret, messageID, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	h.Webhook(rec, req)
+	return rec
+}
+
+func notification(subType, event string) string {
+	return `{"subscription":{"id":"sub-1","status":"enabled","type":"` + subType + `","version":"1",` +
+		`"condition":{"broadcaster_user_id":"` + testChannelID + `"},"transport":{"method":"webhook","callback":"` + testEventSubCallback + `"}},` +
+		`"event":` + event + `}`
+}
+
+func TestEventSubWebhookChallenge(t *testing.T) {
+	h, _, _ := newEventSubTest(t)
+
+	body := `{"challenge":"pogchamp-kappa-360noscope","subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"100000001"}}}`
+	rec := deliver(h, twitch.EventSubMessageVerification, "msg-1", time.Now(), body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "pogchamp-kappa-360noscope", rec.Body.String())
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+}
+
+func TestEventSubWebhookRejectsUnverifiedMessages(t *testing.T) {
+	h, _, _ := newEventSubTest(t)
+	body := notification(twitch.EventSubStreamOffline, `{"broadcaster_user_id":"100000001"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/twitch/eventsub", strings.NewReader(body))
+	req.Header.Set("Twitch-Eventsub-Message-Id", "msg-1")
+	req.Header.Set("Twitch-Eventsub-Message-Timestamp", time.Now().UTC().Format(time.RFC3339Nano))
+	req.Header.Set("Twitch-Eventsub-Message-Type", twitch.EventSubMessageNotification)
+	req.Header.Set("Twitch-Eventsub-Message-Signature", "sha256=00")
+	rec := httptest.NewRecorder()
+	h.Webhook(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = deliver(h, twitch.EventSubMessageNotification, "msg-2", time.Now().Add(-time.Hour), body)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestEventSubWebhookRecordsLiveSession(t *testing.T) {
+	h, store, _ := newEventSubTest(t)
+	start := time.Now().Add(-2 * time.Minute).UTC().Truncate(time.Second)
+
+	online := notification(twitch.EventSubStreamOnline,
+		`{"id":"40000000007","broadcaster_user_id":"100000001","broadcaster_user_login":"kadeemtest","broadcaster_user_name":"KadeemTest","type":"live","started_at":"`+start.Format(time.RFC3339)+`"}`)
+	assert.Equal(t, http.StatusNoContent, deliver(h, twitch.EventSubMessageNotification, "msg-online", time.Now(), online).Code)
+	// Twitch redelivers until it sees a 2xx; the duplicate must not be recorded twice.
+	assert.Equal(t, http.StatusNoContent, deliver(h, twitch.EventSubMessageNotification, "msg-online", time.Now(), online).Code)
+
+	update := notification(twitch.EventSubChannelUpdate,
+		`{"broadcaster_user_id":"100000001","title":"climbing to challenger","language":"en","category_id":"21779","category_name":"League of Legends"}`)
+	assert.Equal(t, http.StatusNoContent, deliver(h, twitch.EventSubMessageNotification, "msg-update", time.Now(), update).Code)
+
+	live := true
+	sessions, err := store.ListLiveSessions(&twitch.LiveSessionFilter{Live: &live}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "40000000007", sessions[0].StreamID)
+	assert.Equal(t, start.Unix(), sessions[0].StartedAt)
+	assert.Equal(t, "climbing to challenger", sessions[0].Title)
+	assert.Equal(t, "League of Legends", sessions[0].Category)
+
+	offline := notification(twitch.EventSubStreamOffline, `{"broadcaster_user_id":"100000001"}`)
+	assert.Equal(t, http.StatusNoContent, deliver(h, twitch.EventSubMessageNotification, "msg-offline", time.Now(), offline).Code)
+
+	sessions, err = store.ListLiveSessions(nil, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.NotNil(t, sessions[0].EndedAt)
+
+	channelID := testChannelID
+	events, err := store.ListStreamEvents(&twitch.StreamEventFilter{ChannelID: &channelID}, 10, 0)
+	require.NoError(t, err)
+	types := make([]twitch.StreamEventType, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.EventType)
+	}
+	assert.ElementsMatch(t, []twitch.StreamEventType{twitch.StreamEventOnline, twitch.StreamEventUpdate, twitch.StreamEventOffline}, types)
+}
+
+func TestEventSubSyncSubscriptions(t *testing.T) {
+	h, store, fake := newEventSubTest(t)
+
+	sync := func() service.EventSubSyncResult {
+		rec := httptest.NewRecorder()
+		h.SyncSubscriptions(rec, httptest.NewRequest(http.MethodPost, "/api/v0/twitch/eventsub/subscriptions/sync", nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var result service.EventSubSyncResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	assert.Equal(t, service.EventSubSyncResult{Created: 3}, sync())
+	assert.Equal(t, service.EventSubSyncResult{Kept: 3}, sync())
+
+	subs := fake.Subscriptions()
+	require.Len(t, subs, 3)
+	fake.SetSubscriptionStatus(subs[0].ID, "authorization_revoked")
+	assert.Equal(t, service.EventSubSyncResult{Created: 1, Deleted: 1, Kept: 2}, sync())
+
+	_, err := store.DeleteChannel(testChannelID)
+	require.NoError(t, err)
+	assert.Equal(t, service.EventSubSyncResult{Deleted: 3}, sync())
+	assert.Empty(t, fake.Subscriptions())
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// LiveSessionHandler handles live session HTTP requests.
+type LiveSessionHandler struct {
+	sessions *service.LiveSessionService
+}
+
+// NewLiveSessionHandler creates a new LiveSessionHandler.
+func NewLiveSessionHandler(sessions *service.LiveSessionService) *LiveSessionHandler {
+	return &LiveSessionHandler{sessions: sessions}
+}
+
+// ListLiveSessions returns the channel's live sessions, newest first.
+func (h *LiveSessionHandler) ListLiveSessions(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelID")
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	sessions, err := h.sessions.ListLiveSessions(channelID, limit, offset)
+	if err != nil {
+		logging.Error("failed to list live sessions", "channel_id", channelID, "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to list live sessions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// ListViewers returns the viewer count samples of one live session, oldest first.
+func (h *LiveSessionHandler) ListViewers(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelID")
+	sessionID, err := strconv.ParseInt(chi.URLParam(r, "sessionID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	session, samples, err := h.sessions.ViewerSeries(channelID, sessionID)
+	if err != nil {
+		logging.Error("failed to list viewer samples", "channel_id", channelID, "session_id", sessionID, "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to list viewer samples")
+		return
+	}
+	if session == nil {
+		respondError(w, http.StatusNotFound, "live session not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"session": session,
+		"samples": samples,
+		"count":   len(samples),
+	})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/api/middleware"
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// replayWriteTimeout replaces the server write timeout while a replay is streamed.
+const replayWriteTimeout = 10 * time.Minute
+
+// ReplayHandler serves stored replay files.
+type ReplayHandler struct {
+	matches *service.MatchService
+	signer  *middleware.URLSigner
+}
+
+// NewReplayHandler creates a new ReplayHandler.
+func NewReplayHandler(matches *service.MatchService, signer *middleware.URLSigner) *ReplayHandler {
+	return &ReplayHandler{matches: matches, signer: signer}
+}
+
+// ServeReplayFile streams the .rofl file of a match. Range and conditional requests are
+// handled by http.ServeContent.
+func (h *ReplayHandler) ServeReplayFile(w http.ResponseWriter, r *http.Request) {
+	matchID, err := strconv.ParseInt(chi.URLParam(r, "matchID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	obj, err := h.matches.OpenReplay(r.Context(), matchID)
+	if errors.Is(err, replay.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Replay not found")
+		return
+	}
+	if err != nil {
+		logging.Error("Failed to open replay", "matchID", matchID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to open replay")
+		return
+	}
+	defer obj.Close()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(replayWriteTimeout)); err != nil {
+		logging.Warn("Failed to extend write deadline for replay", "matchID", matchID, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%d.rofl"`, matchID))
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeContent(w, r, "", obj.Info.ModTime, obj)
+}
+
+// CreateReplayLink returns a short-lived signed URL for the replay file of a match.
+func (h *ReplayHandler) CreateReplayLink(w http.ResponseWriter, r *http.Request) {
+	matchID, err := strconv.ParseInt(chi.URLParam(r, "matchID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	obj, err := h.matches.OpenReplay(r.Context(), matchID)
+	if errors.Is(err, replay.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Replay not found")
+		return
+	}
+	if err != nil {
+		logging.Error("Failed to open replay", "matchID", matchID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create replay link")
+		return
+	}
+	obj.Close()
+
+	path := fmt.Sprintf("/api/v0/riot/matches/%d/replay/file", matchID)
+	query, expires := h.signer.Sign(path)
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"url":       path + "?" + query.Encode(),
+		"expiresAt": expires.Unix(),
+	})
+}
@@ -9,21 +9,21 @@ import (
 	apiModels "github.com/galchammat/kadeem/internal/api/models"
 	"github.com/galchammat/kadeem/internal/logging"
 	riot "github.com/galchammat/kadeem/internal/riot/models"
-	riotpostgres "github.com/galchammat/kadeem/internal/riot/postgres"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 	"github.com/galchammat/kadeem/internal/service"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type RiotHandler struct {
-	db       *riotpostgres.DB
-	twitch   *twitchstore.Store
+	db       riotstore.Store
+	twitch   twitchstore.Store
 	accounts *service.AccountService
 	matches  *service.MatchService
 	ranks    *service.RankService
 }
 
-func NewRiotHandler(db *riotpostgres.DB, twitchStore *twitchstore.Store, accounts *service.AccountService, matches *service.MatchService, ranks *service.RankService) *RiotHandler {
+func NewRiotHandler(db riotstore.Store, twitchStore twitchstore.Store, accounts *service.AccountService, matches *service.MatchService, ranks *service.RankService) *RiotHandler {
 	return &RiotHandler{
 		db:       db,
 		twitch:   twitchStore,
@@ -294,6 +294,65 @@ func (h *RiotHandler) SyncMatchReplay(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListDLQMatches lists matches whose detail fetch exhausted all retries
+func (h *RiotHandler) ListDLQMatches(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 {
+		limit = 100
+	}
+
+	matches, err := h.matches.ListDLQMatches(limit, offset)
+	if err != nil {
+		logging.Error("Failed to list DLQ matches", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list DLQ matches")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"matches": matches,
+		"count":   len(matches),
+	})
+}
+
+// RequeueMatch moves a single DLQ match back to the retry queue
+func (h *RiotHandler) RequeueMatch(w http.ResponseWriter, r *http.Request) {
+	matchID, err := strconv.ParseInt(chi.URLParam(r, "matchID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	n, err := h.matches.RequeueMatch(r.Context(), &matchID)
+	if err != nil {
+		logging.Error("Failed to requeue match", "matchID", matchID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to requeue match")
+		return
+	}
+	if n == 0 {
+		respondError(w, http.StatusNotFound, "Match not found in DLQ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, apiModels.SuccessResponse{
+		Message: "Match requeued successfully",
+	})
+}
+
+// RequeueDLQMatches moves every DLQ match back to the retry queue
+func (h *RiotHandler) RequeueDLQMatches(w http.ResponseWriter, r *http.Request) {
+	n, err := h.matches.RequeueMatch(r.Context(), nil)
+	if err != nil {
+		logging.Error("Failed to requeue DLQ matches", "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to requeue matches")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"requeued": n,
+	})
+}
+
 // FetchReplayURLs fetches replay URLs for an account
 func (h *RiotHandler) FetchReplayURLs(w http.ResponseWriter, r *http.Request) {
 	userID, _ := middleware.GetUserID(r)
@@ -320,7 +379,7 @@ func (h *RiotHandler) FetchReplayURLs(w http.ResponseWriter, r *http.Request) {
 		region = account.Region
 	}
 
-	urls, err := h.matches.FetchReplayURLs(account.PUUID, region)
+	urls, err := h.matches.FetchReplayURLs(r.Context(), account.PUUID, region)
 	if err != nil {
 		logging.Error("Failed to fetch replay URLs", "puuid", accountPUUID, "error", err)
 		respondError(w, http.StatusInternalServerError, "Failed to fetch replay URLs")
@@ -353,7 +412,7 @@ func (h *RiotHandler) FetchMatchSummary(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	matchIDs, err := h.matches.FetchMatchIDs(account.PUUID, account.Region, nil)
+	matchIDs, err := h.matches.FetchMatchIDs(r.Context(), account.PUUID, account.Region, nil)
 	if err != nil {
 		logging.Error("Failed to fetch match IDs", "puuid", accountPUUID, "error", err)
 		respondError(w, http.StatusInternalServerError, "Failed to fetch match summaries")
@@ -380,7 +439,7 @@ func (h *RiotHandler) SyncMatchSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.matches.SyncMatchSummary(matchID, req.FullMatchID, req.Region)
+	err = h.matches.SyncMatchSummary(r.Context(), matchID, req.FullMatchID, req.Region)
 	if err != nil {
 		logging.Error("Failed to sync match summary", "matchID", matchID, "error", err)
 		respondError(w, http.StatusInternalServerError, "Failed to sync match summary")
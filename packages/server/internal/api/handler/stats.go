@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/api/middleware"
+	"github.com/galchammat/kadeem/internal/logging"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// StatsHandler serves aggregated match history stats of tracked accounts.
+type StatsHandler struct {
+	db        riotstore.Store
+	champions *service.ChampionStatsService
+	matchups  *service.MatchupService
+	lp        *service.LPService
+	sessions  *service.PlaySessionService
+	teammates *service.TeammateService
+}
+
+// NewStatsHandler creates a new StatsHandler.
+func NewStatsHandler(db riotstore.Store, champions *service.ChampionStatsService, matchups *service.MatchupService, lp *service.LPService, sessions *service.PlaySessionService, teammates *service.TeammateService) *StatsHandler {
+	return &StatsHandler{
+		db:        db,
+		champions: champions,
+		matchups:  matchups,
+		lp:        lp,
+		sessions:  sessions,
+		teammates: teammates,
+	}
+}
+
+// ChampionStats returns the account's stats per champion, lane and queue. The window is set
+// with fromDate and toDate (inclusive UTC dates, YYYY-MM-DD) or patchMin and patchMax (or
+// patch for a single patch), and can be narrowed with queueId, championId and lane.
+func (h *StatsHandler) ChampionStats(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &riot.ChampionStatsFilter{}
+	if !parseDateParam(w, query, "fromDate", &filter.FromDay) ||
+		!parseDateParam(w, query, "toDate", &filter.ToDay) ||
+		!parseIntParam(w, query, "queueId", &filter.QueueID) ||
+		!parseIntParam(w, query, "championId", &filter.ChampionID) {
+		return
+	}
+	if patch := query.Get("patch"); patch != "" {
+		filter.PatchMin, filter.PatchMax = &patch, &patch
+	}
+	if patchMin := query.Get("patchMin"); patchMin != "" {
+		filter.PatchMin = &patchMin
+	}
+	if patchMax := query.Get("patchMax"); patchMax != "" {
+		filter.PatchMax = &patchMax
+	}
+	if lane := query.Get("lane"); lane != "" {
+		filter.Lane = &lane
+	}
+
+	stats, err := h.champions.ChampionStats(r.Context(), puuid, filter)
+	if err != nil {
+		logging.Error("Failed to compute champion stats", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to compute champion stats")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"champions": stats,
+		"count":     len(stats),
+	})
+}
+
+// LaneMatchups returns the account's results against each enemy champion it met in lane,
+// optionally only for its own champion championId. Matchups with fewer than minGames games
+// (default 1) are left out; lane (a team position: TOP, JUNGLE, MIDDLE, BOTTOM or UTILITY),
+// queueId, from and to (unix seconds) narrow the games down.
+func (h *StatsHandler) LaneMatchups(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &riot.MatchupFilter{}
+	var minGames *int
+	if !parseIntParam(w, query, "championId", &filter.ChampionID) ||
+		!parseIntParam(w, query, "queueId", &filter.QueueID) ||
+		!parseInt64Param(w, query, "from", &filter.From) ||
+		!parseInt64Param(w, query, "to", &filter.To) ||
+		!parseIntParam(w, query, "minGames", &minGames) {
+		return
+	}
+	if minGames != nil {
+		filter.MinGames = *minGames
+	}
+	if lane := query.Get("lane"); lane != "" {
+		filter.Lane = &lane
+	}
+
+	matchups, err := h.matchups.LaneMatchups(r.Context(), puuid, filter)
+	if err != nil {
+		logging.Error("Failed to compute lane matchups", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to compute lane matchups")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"matchups": matchups,
+		"count":    len(matchups),
+	})
+}
+
+// LPChanges returns the LP the account gained or lost in each ranked game of queueId (420 by
+// default, or 440) between from and to (unix seconds).
+func (h *StatsHandler) LPChanges(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+	queueID, from, to, ok := parseRankedWindow(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	changes, err := h.lp.MatchLPChanges(r.Context(), puuid, queueID, from, to)
+	if err != nil {
+		logging.Error("Failed to compute LP changes", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to compute LP changes")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"changes": changes,
+		"count":   len(changes),
+	})
+}
+
+// LPHistory returns the account's rank in queueId (420 by default, or 440) between from and to
+// (unix seconds) as an LP-over-time series.
+func (h *StatsHandler) LPHistory(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+	queueID, from, to, ok := parseRankedWindow(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	points, err := h.lp.LPSeries(r.Context(), puuid, queueID, from, to)
+	if err != nil {
+		logging.Error("Failed to load LP history", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to load LP history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"points": points,
+		"count":  len(points),
+	})
+}
+
+// AccountSessions returns the account's play sessions between from and to (unix seconds),
+// newest first. gapMinutes overrides the break that splits two sessions.
+func (h *StatsHandler) AccountSessions(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+	from, to, gap, limit, ok := parseSessionParams(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessions.AccountSessions(r.Context(), puuid, from, to, gap)
+	if err != nil {
+		logging.Error("Failed to compute play sessions", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to compute play sessions")
+		return
+	}
+	respondSessions(w, sessions, limit)
+}
+
+// StreamerSessions returns the play sessions of the streamer over all of their accounts. It
+// takes the same parameters as AccountSessions.
+func (h *StatsHandler) StreamerSessions(w http.ResponseWriter, r *http.Request) {
+	streamerID, err := strconv.Atoi(chi.URLParam(r, "streamerID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid streamer ID")
+		return
+	}
+	from, to, gap, limit, ok := parseSessionParams(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessions.StreamerSessions(r.Context(), streamerID, from, to, gap)
+	if err != nil {
+		logging.Error("Failed to compute play sessions", "streamer_id", streamerID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to compute play sessions")
+		return
+	}
+	respondSessions(w, sessions, limit)
+}
+
+// Teammates returns the players who were on the account's team in at least minGames games
+// (default 2), most frequent first, with a suggestion to add the ones that are not accounts yet.
+func (h *StatsHandler) Teammates(w http.ResponseWriter, r *http.Request) {
+	puuid, ok := h.trackedAccount(w, r)
+	if !ok {
+		return
+	}
+	var minGames, limit *int
+	if !parseIntParam(w, r.URL.Query(), "minGames", &minGames) ||
+		!parseIntParam(w, r.URL.Query(), "limit", &limit) {
+		return
+	}
+	games, n := 2, 20
+	if minGames != nil {
+		games = *minGames
+	}
+	if limit != nil && *limit > 0 && *limit <= 500 {
+		n = *limit
+	}
+
+	teammates, err := h.teammates.Teammates(r.Context(), puuid, games, n)
+	if err != nil {
+		logging.Error("Failed to find teammates", "puuid", puuid, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to find teammates")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"teammates": teammates,
+		"count":     len(teammates),
+	})
+}
+
+// StreamerMeetings returns the games in which the streamer met other streamers, newest first.
+func (h *StatsHandler) StreamerMeetings(w http.ResponseWriter, r *http.Request) {
+	streamerID, err := strconv.Atoi(chi.URLParam(r, "streamerID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid streamer ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	meetings, err := h.teammates.StreamerMeetings(r.Context(), streamerID, limit, offset)
+	if err != nil {
+		logging.Error("Failed to list streamer meetings", "streamer_id", streamerID, "error", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list streamer meetings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"meetings": meetings,
+		"count":    len(meetings),
+	})
+}
+
+// trackedAccount returns the account of the request path if the user tracks it, and responds
+// with an error otherwise.
+func (h *StatsHandler) trackedAccount(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, _ := middleware.GetUserID(r)
+	puuid := chi.URLParam(r, "accountID")
+	if puuid == "" {
+		respondError(w, http.StatusBadRequest, "Missing account ID")
+		return "", false
+	}
+
+	isTracking, err := h.db.IsTrackingAccount(userID, puuid)
+	if err != nil || !isTracking {
+		respondError(w, http.StatusForbidden, "Not tracking this account")
+		return "", false
+	}
+	return puuid, true
+}
+
+// parseIntParam stores the integer query parameter name in dst if it is set. It responds with
+// 400 and returns false if the parameter is not an integer.
+func parseIntParam(w http.ResponseWriter, query url.Values, name string, dst **int) bool {
+	raw := query.Get(name)
+	if raw == "" {
+		return true
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid "+name)
+		return false
+	}
+	*dst = &v
+	return true
+}
+
+// parseInt64Param is parseIntParam for int64 parameters such as timestamps.
+func parseInt64Param(w http.ResponseWriter, query url.Values, name string, dst **int64) bool {
+	raw := query.Get(name)
+	if raw == "" {
+		return true
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid "+name)
+		return false
+	}
+	*dst = &v
+	return true
+}
+
+// parseDateParam stores the date query parameter name (YYYY-MM-DD, UTC) in dst as days since
+// the epoch if it is set. It responds with 400 and returns false if the parameter is not a date.
+func parseDateParam(w http.ResponseWriter, query url.Values, name string, dst **int64) bool {
+	raw := query.Get(name)
+	if raw == "" {
+		return true
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid "+name)
+		return false
+	}
+	day := date.Unix() / 86400
+	*dst = &day
+	return true
+}
+
+// parseRankedWindow reads the ranked queue and the from and to timestamps of an LP request.
+// The window defaults to everything up to now.
+func parseRankedWindow(w http.ResponseWriter, query url.Values) (int, int64, int64, bool) {
+	var queueID *int
+	var from, to *int64
+	if !parseIntParam(w, query, "queueId", &queueID) ||
+		!parseInt64Param(w, query, "from", &from) ||
+		!parseInt64Param(w, query, "to", &to) {
+		return 0, 0, 0, false
+	}
+
+	queue := 420
+	if queueID != nil {
+		if *queueID != 420 && *queueID != 440 {
+			respondError(w, http.StatusBadRequest, "Invalid queueId")
+			return 0, 0, 0, false
+		}
+		queue = *queueID
+	}
+	start, end := int64(0), time.Now().Unix()
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+	return queue, start, end, true
+}
+
+// parseSessionParams reads the window, gap and limit of a play session request. The window
+// defaults to everything up to now and the limit to 20 sessions.
+func parseSessionParams(w http.ResponseWriter, query url.Values) (int64, int64, time.Duration, int, bool) {
+	var from, to *int64
+	var gapMinutes, limit *int
+	if !parseInt64Param(w, query, "from", &from) ||
+		!parseInt64Param(w, query, "to", &to) ||
+		!parseIntParam(w, query, "gapMinutes", &gapMinutes) ||
+		!parseIntParam(w, query, "limit", &limit) {
+		return 0, 0, 0, 0, false
+	}
+
+	start, end := int64(0), time.Now().Unix()
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+	var gap time.Duration
+	if gapMinutes != nil {
+		if *gapMinutes <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid gapMinutes")
+			return 0, 0, 0, 0, false
+		}
+		gap = time.Duration(*gapMinutes) * time.Minute
+	}
+	n := 20
+	if limit != nil && *limit > 0 && *limit <= 500 {
+		n = *limit
+	}
+	return start, end, gap, n, true
+}
+
+func respondSessions(w http.ResponseWriter, sessions []riot.PlaySession, limit int) {
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
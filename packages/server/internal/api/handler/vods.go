@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// VODHandler handles match-to-VOD alignment HTTP requests.
+type VODHandler struct {
+	vods *service.VODAlignmentService
+}
+
+// NewVODHandler creates a new VODHandler.
+func NewVODHandler(vods *service.VODAlignmentService) *VODHandler {
+	return &VODHandler{vods: vods}
+}
+
+// ListMatchesOnStream returns the streamer's matches with their VOD offsets and deep links.
+func (h *VODHandler) ListMatchesOnStream(w http.ResponseWriter, r *http.Request) {
+	streamerID, err := strconv.ParseInt(chi.URLParam(r, "streamerID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid streamer ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	matches, err := h.vods.ListMatchesOnStream(streamerID, limit, offset)
+	if err != nil {
+		logging.Error("failed to list matches on stream", "streamer_id", streamerID, "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to list matches on stream")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"matches": matches,
+		"count":   len(matches),
+	})
+}
+
+// AlignStreamer recomputes the VOD offsets of the streamer's matches.
+func (h *VODHandler) AlignStreamer(w http.ResponseWriter, r *http.Request) {
+	streamerID, err := strconv.ParseInt(chi.URLParam(r, "streamerID"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid streamer ID")
+		return
+	}
+
+	n, err := h.vods.AlignStreamer(streamerID)
+	if err != nil {
+		logging.Error("failed to align streamer matches", "streamer_id", streamerID, "error", err)
+		respondError(w, http.StatusInternalServerError, "failed to align matches")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"aligned": n,
+	})
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+)
+
+const defaultSignedURLTTL = 5 * time.Minute
+
+// URLSigner signs and verifies short-lived links with HMAC-SHA256. A signature covers the
+// request path and its expiry, so a link only opens the resource it was issued for.
+type URLSigner struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewURLSigner creates a signer whose links expire after ttl.
+func NewURLSigner(secret []byte, ttl time.Duration) *URLSigner {
+	return &URLSigner{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// ErrNoSignedURLSecret is returned by CheckSignedURLSecret when SIGNED_URL_SECRET is not set.
+var ErrNoSignedURLSecret = errors.New("SIGNED_URL_SECRET is not set")
+
+// CheckSignedURLSecret fails with ErrNoSignedURLSecret if SIGNED_URL_SECRET is not set, unless
+// SIGNED_URL_ALLOW_RANDOM_SECRET allows NewURLSignerFromEnv to fall back to a random secret.
+func CheckSignedURLSecret() error {
+	if os.Getenv("SIGNED_URL_SECRET") != "" {
+		return nil
+	}
+	if allow, _ := strconv.ParseBool(os.Getenv("SIGNED_URL_ALLOW_RANDOM_SECRET")); allow {
+		return nil
+	}
+	return ErrNoSignedURLSecret
+}
+
+// NewURLSignerFromEnv reads SIGNED_URL_SECRET and SIGNED_URL_TTL (a Go duration, 5m by default).
+// Without a secret a random one is generated, so links stop working when the process restarts
+// and differ between replicas; callers check CheckSignedURLSecret first.
+func NewURLSignerFromEnv() *URLSigner {
+	ttl := defaultSignedURLTTL
+	if raw := os.Getenv("SIGNED_URL_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			ttl = d
+		} else {
+			logging.Warn("Ignoring invalid SIGNED_URL_TTL", "value", raw)
+		}
+	}
+
+	secret := []byte(os.Getenv("SIGNED_URL_SECRET"))
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic("failed to generate signed URL secret: " + err.Error())
+		}
+		logging.Warn("SIGNED_URL_SECRET not set, using a random secret")
+	}
+	return NewURLSigner(secret, ttl)
+}
+
+// Sign returns the query parameters that authorize a request to path, and when they expire.
+func (s *URLSigner) Sign(path string) (url.Values, time.Time) {
+	expires := s.now().Add(s.ttl).Truncate(time.Second)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", s.signature(path, expires.Unix()))
+	return query, expires
+}
+
+// Verify checks the expires and signature parameters of a request to path.
+func (s *URLSigner) Verify(path string, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid expiry")
+	}
+	if s.now().Unix() > expires {
+		return errors.New("link expired")
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(s.signature(path, expires))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (s *URLSigner) signature(path string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignedOrAuth lets requests carrying a signature through when it is valid for the path,
+// and hands every other request to auth.
+func SignedOrAuth(signer *URLSigner, auth func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authed := auth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !r.URL.Query().Has("signature") {
+				authed.ServeHTTP(w, r)
+				return
+			}
+			if err := signer.Verify(r.URL.Path, r.URL.Query()); err != nil {
+				logging.Warn("Signed URL rejected", "path", r.URL.Path, "error", err.Error())
+				writeUnauthorizedResponse(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := NewURLSigner([]byte("secret"), 5*time.Minute)
+	signer.now = func() time.Time { return now }
+
+	query, expires := signer.Sign("/api/v0/riot/matches/1/replay/file")
+	assert.Equal(t, now.Add(5*time.Minute), expires)
+	require.NoError(t, signer.Verify("/api/v0/riot/matches/1/replay/file", query))
+
+	assert.Error(t, signer.Verify("/api/v0/riot/matches/2/replay/file", query), "other path")
+
+	tampered := query
+	tampered.Set("expires", "1800000000")
+	assert.Error(t, signer.Verify("/api/v0/riot/matches/1/replay/file", tampered), "extended expiry")
+
+	query, _ = signer.Sign("/api/v0/riot/matches/1/replay/file")
+	signer.now = func() time.Time { return now.Add(6 * time.Minute) }
+	assert.Error(t, signer.Verify("/api/v0/riot/matches/1/replay/file", query), "expired")
+}
+
+func TestCheckSignedURLSecret(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "")
+	t.Setenv("SIGNED_URL_ALLOW_RANDOM_SECRET", "")
+	assert.ErrorIs(t, CheckSignedURLSecret(), ErrNoSignedURLSecret)
+
+	t.Setenv("SIGNED_URL_ALLOW_RANDOM_SECRET", "true")
+	assert.NoError(t, CheckSignedURLSecret())
+
+	t.Setenv("SIGNED_URL_ALLOW_RANDOM_SECRET", "false")
+	t.Setenv("SIGNED_URL_SECRET", "secret")
+	assert.NoError(t, CheckSignedURLSecret())
+}
+
+func TestSignedOrAuth(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"), time.Minute)
+	denyAll := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeUnauthorizedResponse(w)
+		})
+	}
+	handler := SignedOrAuth(signer, denyAll)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(target string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	query, _ := signer.Sign("/file")
+	assert.Equal(t, http.StatusNoContent, serve("/file?"+query.Encode()))
+	assert.Equal(t, http.StatusUnauthorized, serve("/other?"+query.Encode()))
+	assert.Equal(t, http.StatusUnauthorized, serve("/file"))
+}
@@ -25,6 +25,15 @@ func (s *Server) setupRoutes(jwksURL string) {
 		r.Get("/datadragon/runes", s.dataDragonHandler.GetRuneData)
 		r.Get("/datadragon/summoner-spells", s.dataDragonHandler.GetSummonerSpellData)
 
+		// Replay and artifact files accept a signed link instead of a JWT
+		r.With(middleware.SignedOrAuth(s.urlSigner, middleware.AuthMiddleware(jwksURL))).
+			Get("/riot/matches/{matchID}/replay/file", s.replayHandler.ServeReplayFile)
+		r.With(middleware.SignedOrAuth(s.urlSigner, middleware.AuthMiddleware(jwksURL))).
+			Get("/artifacts/{artifactID}/file", s.artifactHandler.ServeArtifactFile)
+
+		// Twitch EventSub callback, authenticated by its HMAC signature
+		r.Post("/twitch/eventsub", s.eventSubHandler.Webhook)
+
 		// Protected routes (require authentication)
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.AuthMiddleware(jwksURL))
@@ -40,14 +49,28 @@ func (s *Server) setupRoutes(jwksURL string) {
 			r.Post("/riot/accounts/{accountID}/matches/sync", s.riotHandler.SyncMatches)
 			r.Get("/riot/matches", s.riotHandler.ListMatches)
 			r.Post("/riot/matches/{matchID}/replay", s.riotHandler.SyncMatchReplay)
+			r.Post("/riot/matches/{matchID}/replay/link", s.replayHandler.CreateReplayLink)
 			r.Get("/riot/accounts/{accountID}/replays", s.riotHandler.FetchReplayURLs)
 			r.Get("/riot/accounts/{accountID}/match-summaries", s.riotHandler.FetchMatchSummary)
 			r.Post("/riot/matches/{matchID}/summary", s.riotHandler.SyncMatchSummary)
+			r.Get("/riot/matches/dlq", s.riotHandler.ListDLQMatches)
+			r.Post("/riot/matches/dlq/requeue", s.riotHandler.RequeueDLQMatches)
+			r.Post("/riot/matches/{matchID}/requeue", s.riotHandler.RequeueMatch)
 
 			// Riot ranks
 			r.Get("/riot/accounts/{accountID}/rank-at-time", s.riotHandler.GetPlayerRankAtTime)
 			r.Post("/riot/accounts/{accountID}/rank/sync", s.riotHandler.SyncRank)
 
+			// Riot stats
+			r.Get("/riot/accounts/{accountID}/champion-stats", s.statsHandler.ChampionStats)
+			r.Get("/riot/accounts/{accountID}/matchups", s.statsHandler.LaneMatchups)
+			r.Get("/riot/accounts/{accountID}/lp-changes", s.statsHandler.LPChanges)
+			r.Get("/riot/accounts/{accountID}/lp-history", s.statsHandler.LPHistory)
+			r.Get("/riot/accounts/{accountID}/sessions", s.statsHandler.AccountSessions)
+			r.Get("/streamers/{streamerID}/sessions", s.statsHandler.StreamerSessions)
+			r.Get("/riot/accounts/{accountID}/teammates", s.statsHandler.Teammates)
+			r.Get("/streamers/{streamerID}/meetings", s.statsHandler.StreamerMeetings)
+
 			// Streamers
 			r.Get("/streamers", s.livestreamHandler.ListStreamersWithDetails)
 			r.Post("/streamers", s.livestreamHandler.AddStreamer)
@@ -65,6 +88,24 @@ func (s *Server) setupRoutes(jwksURL string) {
 			r.Post("/channels/{channelID}/events/sync", s.eventsHandler.SyncChannelEvents)
 			r.Get("/channels/{channelID}/events", s.eventsHandler.ListChannelEvents)
 			r.Get("/streamers/{streamerID}/events", s.eventsHandler.ListStreamerEvents)
+
+			// Live sessions
+			r.Get("/channels/{channelID}/live-sessions", s.liveHandler.ListLiveSessions)
+			r.Get("/channels/{channelID}/live-sessions/{sessionID}/viewers", s.liveHandler.ListViewers)
+
+			// EventSub subscriptions
+			r.Get("/twitch/eventsub/subscriptions", s.eventSubHandler.ListSubscriptions)
+			r.Post("/twitch/eventsub/subscriptions/sync", s.eventSubHandler.SyncSubscriptions)
+			r.Delete("/twitch/eventsub/subscriptions/{subscriptionID}", s.eventSubHandler.DeleteSubscription)
+
+			// Downloaded clips and VOD segments
+			r.Get("/artifacts", s.artifactHandler.ListArtifacts)
+			r.Post("/artifacts/{artifactID}/retry", s.artifactHandler.RetryArtifact)
+			r.Post("/artifacts/{artifactID}/link", s.artifactHandler.CreateArtifactLink)
+
+			// Matches on stream
+			r.Get("/streamers/{streamerID}/matches-on-stream", s.vodHandler.ListMatchesOnStream)
+			r.Post("/streamers/{streamerID}/matches-on-stream/sync", s.vodHandler.AlignStreamer)
 		})
 	})
 }
@@ -8,12 +8,15 @@ import (
 	"time"
 
 	"github.com/galchammat/kadeem/internal/api/handler"
+	"github.com/galchammat/kadeem/internal/api/middleware"
 	"github.com/galchammat/kadeem/internal/logging"
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	riotapi "github.com/galchammat/kadeem/internal/riot/api"
 	"github.com/galchammat/kadeem/internal/riot/datadragon"
-	riotpostgres "github.com/galchammat/kadeem/internal/riot/postgres"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/streaming"
 	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 	"github.com/go-chi/chi/v5"
@@ -29,22 +32,41 @@ type Server struct {
 	dataDragonHandler *handler.DataDragonHandler
 	livestreamHandler *handler.LivestreamHandler
 	eventsHandler     *handler.EventsHandler
+	vodHandler        *handler.VODHandler
+	replayHandler     *handler.ReplayHandler
+	eventSubHandler   *handler.EventSubHandler
+	liveHandler       *handler.LiveSessionHandler
+	artifactHandler   *handler.ArtifactHandler
+	statsHandler      *handler.StatsHandler
+	urlSigner         *middleware.URLSigner
 }
 
 // NewServer creates a new API server
-func NewServer(db *platformdb.DB, riotStore *riotpostgres.DB, twitchStore *twitchstore.Store, port string) *Server {
+func NewServer(db *platformdb.DB, riotStore riotstore.Store, twitchStore twitchstore.Store, replays replay.ReplayStore, port string) *Server {
 	// Create clients
 	ctx := context.Background()
 	riotClient := riotapi.NewClient()
 	dataDragonClient := datadragon.NewDataDragonClient(ctx, "bin/datadragon")
 	twitchClient := twitchapi.NewTwitchClient(ctx)
+	platforms := streaming.NewRegistryFromEnv(ctx, twitchClient)
 
 	// Create services
 	accountSvc := service.NewAccountService(riotStore, riotClient)
-	matchSvc := service.NewMatchService(riotStore, riotClient)
+	matchSvc := service.NewMatchService(riotStore, riotClient, replays)
 	rankSvc := service.NewRankService(riotStore, riotClient)
-	streamerSvc := service.NewStreamerService(twitchStore, twitchClient)
-	streamEventsSvc := service.NewStreamEventsService(twitchStore, twitchClient)
+	championStatsSvc := service.NewChampionStatsService(riotStore)
+	matchupSvc := service.NewMatchupService(riotStore)
+	lpSvc := service.NewLPService(riotStore)
+	sessionSvc := service.NewPlaySessionService(riotStore, lpSvc, service.SessionGapFromEnv())
+	teammateSvc := service.NewTeammateService(riotStore)
+	streamerSvc := service.NewStreamerService(twitchStore, platforms)
+	streamEventsSvc := service.NewStreamEventsService(twitchStore, platforms)
+	vodSvc := service.NewVODAlignmentService(riotStore)
+	eventSubConfig := service.EventSubConfigFromEnv()
+	eventSubSvc := service.NewEventSubService(twitchStore, twitchClient, eventSubConfig)
+	liveSvc := service.NewLiveSessionService(twitchStore, platforms)
+	// The API only lists and serves artifacts; the daemon downloads them, so it needs no source.
+	artifactSvc := service.NewArtifactService(twitchStore, riotStore, replays, nil, service.ArtifactConfigFromEnv())
 
 	// Get frontend domain from env
 	frontendDomain := os.Getenv("FRONTEND_DOMAIN")
@@ -66,6 +88,8 @@ func NewServer(db *platformdb.DB, riotStore *riotpostgres.DB, twitchStore *twitc
 		logging.Info("SUPABASE_JWKS_URL not set, using default")
 	}
 
+	urlSigner := middleware.NewURLSignerFromEnv()
+
 	s := &Server{
 		router:            chi.NewRouter(),
 		allowedOrigins:    allowedOrigins,
@@ -75,6 +99,13 @@ func NewServer(db *platformdb.DB, riotStore *riotpostgres.DB, twitchStore *twitc
 		dataDragonHandler: handler.NewDataDragonHandler(dataDragonClient),
 		livestreamHandler: handler.NewLivestreamHandler(streamerSvc),
 		eventsHandler:     handler.NewEventsHandler(streamEventsSvc),
+		vodHandler:        handler.NewVODHandler(vodSvc),
+		replayHandler:     handler.NewReplayHandler(matchSvc, urlSigner),
+		eventSubHandler:   handler.NewEventSubHandler(eventSubSvc, eventSubConfig.Secret),
+		liveHandler:       handler.NewLiveSessionHandler(liveSvc),
+		artifactHandler:   handler.NewArtifactHandler(artifactSvc, urlSigner),
+		statsHandler:      handler.NewStatsHandler(riotStore, championStatsSvc, matchupSvc, lpSvc, sessionSvc, teammateSvc),
+		urlSigner:         urlSigner,
 	}
 
 	// Setup routes
@@ -106,8 +137,8 @@ func (s *Server) Shutdown(ctx context.Context) error {
 }
 
 // StartServer starts the API server (for daemon integration)
-func StartServer(ctx context.Context, db *platformdb.DB, riotStore *riotpostgres.DB, twitchStore *twitchstore.Store, port string) error {
-	server := NewServer(db, riotStore, twitchStore, port)
+func StartServer(ctx context.Context, db *platformdb.DB, riotStore riotstore.Store, twitchStore twitchstore.Store, replays replay.ReplayStore, port string) error {
+	server := NewServer(db, riotStore, twitchStore, replays, port)
 
 	go func() {
 		if err := server.Start(); err != nil && err != http.ErrServerClosed {
@@ -3,7 +3,9 @@ package models
 type Status string
 
 const (
-	StatusDone  Status = "done"
-	StatusRetry Status = "retry"
-	StatusDLQ   Status = "dlq"
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusDone       Status = "done"
+	StatusRetry      Status = "retry"
+	StatusDLQ        Status = "dlq"
 )
@@ -4,20 +4,35 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
+// Drivers an OpenDB connection can use.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
 type DB struct {
 	SQL *sql.DB
+	// Driver is DriverPostgres or DriverSQLite.
+	Driver string
 }
 
+// OpenDB connects to DATABASE_URL. A sqlite:// URL (e.g. sqlite://./kadeem.db) opens a
+// local SQLite file, and sqlite://:memory: a throwaway in-memory database; anything else is
+// handed to the Postgres driver.
 func OpenDB() (*DB, error) {
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL environment variable not set")
 	}
+	if path, ok := strings.CutPrefix(dbURL, "sqlite://"); ok {
+		return OpenSQLite(path)
+	}
 
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
@@ -35,5 +50,43 @@ func OpenDB() (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{SQL: db}, nil
+	return &DB{SQL: db, Driver: DriverPostgres}, nil
+}
+
+// OpenSQLite opens the SQLite database at path, creating the file if needed. Foreign keys
+// are enforced like in Postgres, and write transactions take the lock up front so
+// concurrent writers wait for each other instead of failing. Queries may use the Postgres
+// $N placeholders. The path ":memory:" opens an in-memory database that lives as long as the
+// returned DB.
+func OpenSQLite(path string) (*DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is empty")
+	}
+	dsn := "file:" + path
+	params := "_foreign_keys=on&_busy_timeout=10000&_journal_mode=WAL&_txlock=immediate&_loc=UTC"
+	if strings.Contains(dsn, "?") {
+		dsn += "&" + params
+	} else {
+		dsn += "?" + params
+	}
+
+	db, err := sql.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(4)
+	if path == ":memory:" {
+		// Every connection would get its own empty database, so keep exactly one open.
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &DB{SQL: db, Driver: DriverSQLite}, nil
 }
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// NewMigrate returns a migrator for db. Postgres migrations are read from dir and SQLite
+// migrations from dir/sqlite; both sets share version numbers.
+func NewMigrate(db *DB, dir string) (*migrate.Migrate, error) {
+	var (
+		driver database.Driver
+		err    error
+	)
+	switch db.Driver {
+	case DriverSQLite:
+		dir = filepath.Join(dir, "sqlite")
+		driver, err = sqlite3.WithInstance(db.SQL, &sqlite3.Config{MigrationsTable: "schema_migrations"})
+	default:
+		driver, err = postgres.WithInstance(db.SQL, &postgres.Config{
+			MigrationsTable: "schema_migrations",
+			DatabaseName:    "kadeem",
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s migration driver: %w", db.Driver, err)
+	}
+
+	return migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(dir), db.Driver, driver)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the SQLite driver that OpenSQLite connects with. It accepts the Postgres
+// $N placeholders, so a query text can be shared by both backends.
+const sqliteDriverName = "sqlite3_pgbind"
+
+func init() {
+	sql.Register(sqliteDriverName, &pgBindDriver{})
+}
+
+type pgBindDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *pgBindDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &pgBindConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// pgBindConn rewrites the placeholders of every statement before SQLite prepares it.
+type pgBindConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *pgBindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(rebindPostgres(query))
+}
+
+func (c *pgBindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, rebindPostgres(query))
+}
+
+func (c *pgBindConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return c.SQLiteConn.Exec(rebindPostgres(query), args)
+}
+
+func (c *pgBindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, rebindPostgres(query), args)
+}
+
+func (c *pgBindConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return c.SQLiteConn.Query(rebindPostgres(query), args)
+}
+
+func (c *pgBindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, rebindPostgres(query), args)
+}
+
+// rebindPostgres turns the Postgres placeholders $1, $2, ... into SQLite's ?1, ?2, ..., which
+// bind by number as well. String literals and quoted identifiers are left alone.
+func rebindPostgres(query string) string {
+	if !strings.Contains(query, "$") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query))
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			ch = '?'
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
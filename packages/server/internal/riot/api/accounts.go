@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -9,13 +10,13 @@ import (
 )
 
 // FetchAccount fetches account data from the Riot API by gameName and tagLine.
-func (c *Client) FetchAccount(region, gameName, tagLine string) (*models.Account, error) {
+func (c *Client) FetchAccount(ctx context.Context, region, gameName, tagLine string) (*models.Account, error) {
 	if gameName == "" || tagLine == "" || region == "" {
 		return nil, fmt.Errorf("gameName, tagLine, and region cannot be empty")
 	}
 
 	url := c.buildURL(region, fmt.Sprintf("/riot/account/v1/accounts/by-riot-id/%s/%s", gameName, tagLine))
-	body, statusCode, err := c.makeRequest(url)
+	body, statusCode, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to fetch account from Riot API", "gameName", gameName, "tagLine", tagLine, "region", region, "statusCode", statusCode, "error", err)
 		if statusCode == 404 {
@@ -34,9 +35,9 @@ func (c *Client) FetchAccount(region, gameName, tagLine string) (*models.Account
 }
 
 // FetchAccountByPUUID fetches account data from the Riot API by PUUID.
-func (c *Client) FetchAccountByPUUID(region, puuid string) (*models.Account, error) {
+func (c *Client) FetchAccountByPUUID(ctx context.Context, region, puuid string) (*models.Account, error) {
 	url := c.buildURL(region, fmt.Sprintf("/riot/account/v1/accounts/by-puuid/%s", puuid))
-	body, _, err := c.makeRequest(url)
+	body, _, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to fetch account from Riot API", "puuid", puuid, "region", region, "error", err)
 		return nil, err
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/galchammat/kadeem/internal/logging"
@@ -26,15 +28,41 @@ func (t *riotTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return t.base.RoundTrip(req)
 }
 
+// maxRateLimitRetries is how many times a request is retried after a 429 response.
+const maxRateLimitRetries = 3
+
 // Client is a pure HTTP client for the Riot Games API.
 // It has no database dependency.
 type Client struct {
 	httpClient *http.Client
+	limiter    *rateLimiter
+	// baseURL replaces the regional Riot hosts when set.
+	baseURL string
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithBaseURL sends every request to baseURL instead of the regional Riot hosts,
+// e.g. to a riottest fake. It overrides RIOT_API_BASE_URL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
 }
 
-func NewClient() *Client {
+// WithTimeout sets the timeout of a single HTTP request (30s by default).
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// NewClient returns a client authenticated with RIOT_API_KEY. RIOT_API_BASE_URL, when set,
+// points it at another server than the Riot API.
+func NewClient(opts ...ClientOption) *Client {
 	apiKey := os.Getenv("RIOT_API_KEY")
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 			Transport: &riotTransport{
@@ -42,10 +70,19 @@ func NewClient() *Client {
 				base:   http.DefaultTransport,
 			},
 		},
+		limiter: newRateLimiter(),
+		baseURL: strings.TrimSuffix(os.Getenv("RIOT_API_BASE_URL"), "/"),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func (c *Client) buildURL(region, endpoint string) string {
+	if c.baseURL != "" {
+		return c.baseURL + endpoint
+	}
 	generalRegion, err := GetAPIRegion(region)
 	if err != nil {
 		logging.Error("Failed to generalize API region", "region", region)
@@ -53,30 +90,48 @@ func (c *Client) buildURL(region, endpoint string) string {
 	return fmt.Sprintf("https://%s.api.riotgames.com%s", generalRegion, endpoint)
 }
 
-func (c *Client) makeRequest(url string) ([]byte, int, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, 400, err
-	}
+// makeRequest performs a rate limited GET request, waiting for a free slot in the
+// routing and method buckets and retrying when Riot answers with 429. Cancelling ctx stops
+// both the waits and the request.
+func (c *Client) makeRequest(ctx context.Context, url string) ([]byte, int, error) {
+	routing, method := rateLimitKeys(url)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		logging.Error(err.Error())
-		return nil, 500, err
-	}
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx, routing, method); err != nil {
+			return nil, 500, err
+		}
 
-	body, err := io.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
-		logging.Error(err.Error())
-		return nil, 500, err
-	}
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, 400, err
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("HTTP request failed. url %s. status %d. body %s", url, resp.StatusCode, string(body))
-		logging.Error(err.Error())
-		return nil, resp.StatusCode, err
-	}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			logging.Error(err.Error())
+			return nil, 500, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			logging.Error(err.Error())
+			return nil, 500, err
+		}
+		c.limiter.update(routing, method, resp.Header)
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			retryAfter := c.limiter.block(routing, method, resp.Header)
+			logging.Warn("Riot API rate limit hit, retrying", "routing", routing, "method", method, "retryAfter", retryAfter, "limitType", resp.Header.Get("X-Rate-Limit-Type"), "attempt", attempt+1)
+			continue
+		}
 
-	return body, resp.StatusCode, nil
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("HTTP request failed. url %s. status %d. body %s", url, resp.StatusCode, string(body))
+			logging.Error(err.Error())
+			return nil, resp.StatusCode, err
+		}
+
+		return body, resp.StatusCode, nil
+	}
 }
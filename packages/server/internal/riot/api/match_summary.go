@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -12,7 +13,7 @@ import (
 // FetchMatchIDs fetches match IDs for a PUUID from the Riot API.
 // startTime is optional (unix timestamp in milliseconds, exclusive lower bound).
 // Always uses count=100 (maximum allowed by Riot API).
-func (c *Client) FetchMatchIDPage(puuid, region string, startTime *int64, start, count int) ([]string, error) {
+func (c *Client) FetchMatchIDPage(ctx context.Context, puuid, region string, startTime *int64, start, count int) ([]string, error) {
 	if puuid == "" {
 		return nil, fmt.Errorf("puuid cannot be empty")
 	}
@@ -29,7 +30,7 @@ func (c *Client) FetchMatchIDPage(puuid, region string, startTime *int64, start,
 	url := c.buildURL(region, endpoint) + query
 	fmt.Println(url)
 
-	body, _, err := c.makeRequest(url)
+	body, _, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to fetch match IDs from Riot API", "puuid", puuid, "url", url, "error", err)
 		return nil, err
@@ -45,10 +46,10 @@ func (c *Client) FetchMatchIDPage(puuid, region string, startTime *int64, start,
 }
 
 // FetchMatchDetail fetches full match detail for a given match ID.
-func (c *Client) FetchMatchDetails(matchID int64, region string) (*models.MatchDetails, error) {
+func (c *Client) FetchMatchDetails(ctx context.Context, matchID int64, region string) (*models.MatchDetails, error) {
 	fullMatchID := fmt.Sprintf("%s_%d", region, matchID)
 	url := c.buildURL(region, fmt.Sprintf("/lol/match/v5/matches/%s", fullMatchID))
-	body, _, err := c.makeRequest(url)
+	body, _, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to fetch match detail from Riot API", "fullMatchID", fullMatchID, "url", url, "error", err)
 		return nil, err
@@ -63,11 +64,30 @@ func (c *Client) FetchMatchDetails(matchID int64, region string) (*models.MatchD
 	return &response, nil
 }
 
+// FetchMatchTimeline fetches the event timeline for a given match ID.
+func (c *Client) FetchMatchTimeline(ctx context.Context, matchID int64, region string) (*models.MatchTimeline, error) {
+	fullMatchID := fmt.Sprintf("%s_%d", region, matchID)
+	url := c.buildURL(region, fmt.Sprintf("/lol/match/v5/matches/%s/timeline", fullMatchID))
+	body, _, err := c.makeRequest(ctx, url)
+	if err != nil {
+		logging.Error("Failed to fetch match timeline from Riot API", "fullMatchID", fullMatchID, "url", url, "error", err)
+		return nil, err
+	}
+
+	var response models.MatchTimeline
+	if err := json.Unmarshal(body, &response); err != nil {
+		logging.Error("Failed to unmarshal match timeline", "fullMatchID", fullMatchID, "error", err)
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 // FetchReplayURLs fetches replay download URLs for a PUUID.
-func (c *Client) FetchReplayURLs(puuid, region string) ([]string, error) {
+func (c *Client) FetchReplayURLs(ctx context.Context, puuid, region string) ([]string, error) {
 	endpoint := fmt.Sprintf("/lol/match/v5/matches/by-puuid/%s/replays", puuid)
 	url := c.buildURL(region, endpoint)
-	body, statusCode, err := c.makeRequest(url)
+	body, statusCode, err := c.makeRequest(ctx, url)
 	if err != nil || statusCode != 200 {
 		logging.Error("Failed to fetch replay URLs from Riot API", "puuid", puuid, "region", region, "statusCode", statusCode, "error", err)
 		return nil, fmt.Errorf("error fetching replay URLs: %v Status Code: %d", err, statusCode)
@@ -92,9 +112,9 @@ type RankEntry struct {
 }
 
 // FetchSummonerID fetches the summoner ID for a PUUID.
-func (c *Client) FetchSummonerID(puuid, region string) (string, error) {
+func (c *Client) FetchSummonerID(ctx context.Context, puuid, region string) (string, error) {
 	url := c.buildURL(region, fmt.Sprintf("/lol/summoner/v4/summoners/by-puuid/%s", puuid))
-	body, _, err := c.makeRequest(url)
+	body, _, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to get summoner ID", "puuid", puuid, "error", err)
 		return "", fmt.Errorf("failed to get summoner ID: %w", err)
@@ -111,9 +131,9 @@ func (c *Client) FetchSummonerID(puuid, region string) (string, error) {
 }
 
 // FetchRankEntries fetches rank entries for a summoner.
-func (c *Client) FetchRankEntries(summonerID, region string) ([]RankEntry, error) {
+func (c *Client) FetchRankEntries(ctx context.Context, summonerID, region string) ([]RankEntry, error) {
 	url := c.buildURL(region, fmt.Sprintf("/lol/league/v4/entries/by-summoner/%s", summonerID))
-	body, _, err := c.makeRequest(url)
+	body, _, err := c.makeRequest(ctx, url)
 	if err != nil {
 		logging.Error("Failed to fetch rank", "summonerID", summonerID, "error", err)
 		return nil, fmt.Errorf("failed to fetch rank: %w", err)
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultAppRateLimit is the development key limit, used until Riot reports the real one.
+const defaultAppRateLimit = "20:1,100:120"
+
+// defaultRetryAfter is used when a 429 response carries no Retry-After header.
+const defaultRetryAfter = time.Second
+
+// rateWindow tracks one "limit:seconds" pair of a Riot rate limit header.
+type rateWindow struct {
+	limit   int
+	window  time.Duration
+	count   int
+	resetAt time.Time
+}
+
+// rateBucket holds every window that applies to one key, plus a hard block set by 429s.
+type rateBucket struct {
+	windows      []*rateWindow
+	blockedUntil time.Time
+}
+
+// delay returns how long a caller has to wait before the bucket has a free slot.
+func (b *rateBucket) delay(now time.Time) time.Duration {
+	var wait time.Duration
+	if now.Before(b.blockedUntil) {
+		wait = b.blockedUntil.Sub(now)
+	}
+	for _, w := range b.windows {
+		if now.Before(w.resetAt) && w.count >= w.limit {
+			wait = max(wait, w.resetAt.Sub(now))
+		}
+	}
+	return wait
+}
+
+func (b *rateBucket) consume(now time.Time) {
+	for _, w := range b.windows {
+		if !now.Before(w.resetAt) {
+			w.count = 0
+			w.resetAt = now.Add(w.window)
+		}
+		w.count++
+	}
+}
+
+// sync replaces the bucket limits with the ones reported by Riot, keeping the
+// highest known count for windows that already existed.
+func (b *rateBucket) sync(now time.Time, limits, counts string) {
+	parsed := parseRateWindows(limits)
+	if len(parsed) == 0 {
+		return
+	}
+	reported := make(map[time.Duration]int)
+	for _, c := range parseRateWindows(counts) {
+		reported[c.window] = c.limit
+	}
+
+	existing := make(map[time.Duration]*rateWindow, len(b.windows))
+	for _, w := range b.windows {
+		existing[w.window] = w
+	}
+
+	windows := make([]*rateWindow, 0, len(parsed))
+	for _, p := range parsed {
+		w, ok := existing[p.window]
+		if !ok || !now.Before(w.resetAt) {
+			w = &rateWindow{window: p.window, resetAt: now.Add(p.window)}
+		}
+		w.limit = p.limit
+		w.count = max(w.count, reported[p.window])
+		windows = append(windows, w)
+	}
+	b.windows = windows
+}
+
+// rateLimiter paces Riot API requests with one application bucket per routing
+// value (americas, europe, na1, ...) and one method bucket per routing value and endpoint.
+type rateLimiter struct {
+	mu      sync.Mutex
+	apps    map[string]*rateBucket
+	methods map[string]*rateBucket
+	now     func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		apps:    make(map[string]*rateBucket),
+		methods: make(map[string]*rateBucket),
+		now:     time.Now,
+	}
+}
+
+func (l *rateLimiter) buckets(routing, method string) (*rateBucket, *rateBucket) {
+	app, ok := l.apps[routing]
+	if !ok {
+		app = &rateBucket{}
+		app.sync(l.now(), defaultAppRateLimit, "")
+		l.apps[routing] = app
+	}
+	key := routing + " " + method
+	m, ok := l.methods[key]
+	if !ok {
+		m = &rateBucket{}
+		l.methods[key] = m
+	}
+	return app, m
+}
+
+// wait blocks until both the application and method buckets have a free slot, then takes it.
+func (l *rateLimiter) wait(ctx context.Context, routing, method string) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		app, m := l.buckets(routing, method)
+		delay := max(app.delay(now), m.delay(now))
+		if delay <= 0 {
+			app.consume(now)
+			m.consume(now)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// update syncs the buckets with the rate limit headers of a Riot response.
+func (l *rateLimiter) update(routing, method string, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	app, m := l.buckets(routing, method)
+	app.sync(now, header.Get("X-App-Rate-Limit"), header.Get("X-App-Rate-Limit-Count"))
+	m.sync(now, header.Get("X-Method-Rate-Limit"), header.Get("X-Method-Rate-Limit-Count"))
+}
+
+// block honours the Retry-After header of a 429 response and returns the wait it applied.
+// Application limits block the whole routing value; method and service limits only the endpoint.
+func (l *rateLimiter) block(routing, method string, header http.Header) time.Duration {
+	retryAfter := defaultRetryAfter
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.now().Add(retryAfter)
+	app, m := l.buckets(routing, method)
+	target := m
+	if strings.EqualFold(header.Get("X-Rate-Limit-Type"), "application") {
+		target = app
+	}
+	if until.After(target.blockedUntil) {
+		target.blockedUntil = until
+	}
+	return retryAfter
+}
+
+// parseRateWindows parses headers like "20:1,100:120" into limit/window pairs.
+func parseRateWindows(header string) []rateWindow {
+	var windows []rateWindow
+	for _, part := range strings.Split(header, ",") {
+		limit, seconds, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			continue
+		}
+		s, err := strconv.Atoi(seconds)
+		if err != nil || s <= 0 {
+			continue
+		}
+		windows = append(windows, rateWindow{limit: l, window: time.Duration(s) * time.Second})
+	}
+	return windows
+}
+
+// riotMethods maps request paths to the endpoint names Riot uses for method rate limits.
+var riotMethods = []struct {
+	pattern *regexp.Regexp
+	name    string
+}{
+	{regexp.MustCompile(`^/riot/account/v1/accounts/by-riot-id/[^/]+/[^/]+$`), "account-v1.by-riot-id"},
+	{regexp.MustCompile(`^/riot/account/v1/accounts/by-puuid/[^/]+$`), "account-v1.by-puuid"},
+	{regexp.MustCompile(`^/lol/match/v5/matches/by-puuid/[^/]+/ids$`), "match-v5.ids"},
+	{regexp.MustCompile(`^/lol/match/v5/matches/by-puuid/[^/]+/replays$`), "match-v5.replays"},
+	{regexp.MustCompile(`^/lol/match/v5/matches/[^/]+/timeline$`), "match-v5.timeline"},
+	{regexp.MustCompile(`^/lol/match/v5/matches/[^/]+$`), "match-v5.match"},
+	{regexp.MustCompile(`^/lol/summoner/v4/summoners/by-puuid/[^/]+$`), "summoner-v4.by-puuid"},
+	{regexp.MustCompile(`^/lol/league/v4/entries/by-summoner/[^/]+$`), "league-v4.by-summoner"},
+	{regexp.MustCompile(`^/lol/league/v4/entries/by-puuid/[^/]+$`), "league-v4.by-puuid"},
+}
+
+// rateLimitKeys returns the routing value and method name a request URL is limited under.
+func rateLimitKeys(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", rawURL
+	}
+	routing, _, _ := strings.Cut(u.Hostname(), ".")
+	for _, m := range riotMethods {
+		if m.pattern.MatchString(u.EscapedPath()) {
+			return routing, m.name
+		}
+	}
+	return routing, u.EscapedPath()
+}
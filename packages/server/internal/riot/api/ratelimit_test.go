@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateWindows(t *testing.T) {
+	windows := parseRateWindows("20:1, 100:120,bogus,5:0")
+	require.Len(t, windows, 2)
+	assert.Equal(t, 20, windows[0].limit)
+	assert.Equal(t, time.Second, windows[0].window)
+	assert.Equal(t, 100, windows[1].limit)
+	assert.Equal(t, 120*time.Second, windows[1].window)
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	routing, method := rateLimitKeys("https://europe.api.riotgames.com/lol/match/v5/matches/by-puuid/abc-DEF_1/ids?start=0&count=20")
+	assert.Equal(t, "europe", routing)
+	assert.Equal(t, "match-v5.ids", method)
+
+	routing, method = rateLimitKeys("https://euw1.api.riotgames.com/lol/league/v4/entries/by-summoner/xyz")
+	assert.Equal(t, "euw1", routing)
+	assert.Equal(t, "league-v4.by-summoner", method)
+
+	_, method = rateLimitKeys("https://europe.api.riotgames.com/lol/match/v5/matches/EUW1_1/timeline")
+	assert.Equal(t, "match-v5.timeline", method)
+}
+
+func TestRateLimiterDelaysFullWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newRateLimiter()
+	l.now = func() time.Time { return now }
+
+	l.update("americas", "m", http.Header{
+		"X-App-Rate-Limit":       {"2:10"},
+		"X-App-Rate-Limit-Count": {"0:10"},
+	})
+	app, method := l.buckets("americas", "m")
+	app.consume(now)
+	app.consume(now)
+
+	assert.Equal(t, 10*time.Second, max(app.delay(now), method.delay(now)))
+
+	// Other routing values have their own bucket.
+	other, _ := l.buckets("europe", "m")
+	assert.Zero(t, other.delay(now))
+
+	now = now.Add(10 * time.Second)
+	assert.Zero(t, app.delay(now))
+}
+
+func TestRateLimiterBlockUsesLimitType(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newRateLimiter()
+	l.now = func() time.Time { return now }
+
+	l.block("europe", "match-v5.match", http.Header{"Retry-After": {"7"}})
+	app, method := l.buckets("europe", "match-v5.match")
+	assert.Zero(t, app.delay(now))
+	assert.Equal(t, 7*time.Second, method.delay(now))
+
+	l.block("europe", "match-v5.match", http.Header{"Retry-After": {"3"}, "X-Rate-Limit-Type": {"application"}})
+	assert.Equal(t, 3*time.Second, app.delay(now))
+}
+
+func TestMakeRequestRetriesAfter429(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("X-Rate-Limit-Type", "method")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-App-Rate-Limit", "20:1,100:120")
+		w.Header().Set("X-App-Rate-Limit-Count", "2:1,2:120")
+		_, _ = w.Write([]byte(`["EUW1_1"]`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	body, status, err := c.makeRequest(context.Background(), server.URL+"/lol/match/v5/matches/by-puuid/p/ids")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `["EUW1_1"]`, string(body))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMakeRequestStopsWaitingWhenContextEnds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := NewClient().makeRequest(ctx, server.URL+"/lol/match/v5/matches/by-puuid/p/ids")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -11,6 +10,12 @@ import (
 	"github.com/klauspost/compress/zstd"
 )
 
+const (
+	roflHeaderLen      = 0x10
+	roflSignatureLen   = 0x100
+	roflChunkHeaderLen = 17
+)
+
 type Chunk struct {
 	ID              uint32
 	Type            uint8
@@ -18,97 +23,232 @@ type Chunk struct {
 	UncompressedLen uint32
 	CompressedLen   uint32
 	Payload         []byte
+
+	src    io.ReaderAt
+	offset int64
 }
 
-func ParseROFL(path string) ([]Chunk, error) {
-	raw, err := os.ReadFile(path)
+// Open returns the decompressed payload of the chunk. Nothing is read or decompressed
+// until the returned reader is used.
+func (c *Chunk) Open() (io.ReadCloser, error) {
+	if c.src == nil {
+		return nil, errors.New("chunk has no source")
+	}
+	if c.CompressedLen == 0 {
+		return io.NopCloser(io.NewSectionReader(c.src, c.offset, int64(c.UncompressedLen))), nil
+	}
+
+	decoder, err := zstd.NewReader(
+		io.NewSectionReader(c.src, c.offset, int64(c.CompressedLen)),
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderLowmem(true),
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to init zstd decoder: %w", err)
 	}
+	return decoder.IOReadCloser(), nil
+}
+
+// ROFLReader reads a replay file chunk by chunk. Opening it only reads the metadata and
+// the chunk headers; payloads are read and decompressed when a chunk is opened.
+type ROFLReader struct {
+	Metadata *ROFLMetadata
+
+	chunks []Chunk
+	next   int
+	closer io.Closer
+}
 
-	if len(raw) < 4 {
-		return nil, errors.New("file too short")
+// OpenROFL opens a replay file for streaming. The caller must Close the reader.
+func OpenROFL(path string) (*ROFLReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	// --- Remove metadata + signature ---
-	metaLen := binary.LittleEndian.Uint32(raw[len(raw)-4:])
-	if len(raw) < int(metaLen)+4 {
-		return nil, errors.New("file is corrupted (metadata length too large)")
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	r, err := NewROFLReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewROFLReader indexes the replay in src, which is size bytes long.
+func NewROFLReader(src io.ReaderAt, size int64) (*ROFLReader, error) {
+	metadata, metaLen, err := readROFLMetadata(src, size)
+	if err != nil {
+		return nil, err
 	}
-	raw = raw[:len(raw)-int(metaLen)-4]
-	if len(raw) < 0x100 {
+
+	// --- Remove metadata + signature ---
+	end := size - metaLen - 4
+	if end < roflSignatureLen {
 		return nil, errors.New("file too short after signature removal")
 	}
-	raw = raw[:len(raw)-0x100]
+	end -= roflSignatureLen
 
 	// --- Remove ROFL header ---
-	if len(raw) < 0x10 {
+	if end < roflHeaderLen {
 		return nil, errors.New("file too short for header removal")
 	}
-	raw = raw[0x10:]
-	if len(raw) < 0xD {
+	start := int64(roflHeaderLen)
+	if end-start < 0xD {
 		return nil, errors.New("file too short for secondary header removal")
 	}
-	if raw[0xC] == 1 {
-		if len(raw) < 0xC {
-			return nil, errors.New("header step exceeds buffer")
-		}
-		raw = raw[0xC:]
+	var step [1]byte
+	if _, err := src.ReadAt(step[:], start+0xC); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if step[0] == 1 {
+		start += 0xC
 	} else {
-		if len(raw) < 0xD {
-			return nil, errors.New("header step exceeds buffer")
-		}
-		raw = raw[0xD:]
+		start += 0xD
 	}
 
-	// Parse Chunks
+	chunks, err := indexROFLChunks(src, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &ROFLReader{Metadata: metadata, chunks: chunks}, nil
+}
+
+// indexROFLChunks reads the chunk headers between start and end without touching the payloads.
+func indexROFLChunks(src io.ReaderAt, start, end int64) ([]Chunk, error) {
 	var chunks []Chunk
-	buf := bytes.NewReader(raw)
+	header := make([]byte, roflChunkHeaderLen)
+	for offset := start; end-offset >= roflChunkHeaderLen; {
+		if _, err := src.ReadAt(header, offset); err != nil {
+			return nil, fmt.Errorf("error reading chunk header: %w", err)
+		}
+		offset += roflChunkHeaderLen
 
-	decoder, err := zstd.NewReader(nil)
+		chunk := Chunk{
+			ID:              binary.LittleEndian.Uint32(header[0:4]),
+			Type:            header[4],
+			ID2:             binary.LittleEndian.Uint32(header[5:9]),
+			UncompressedLen: binary.LittleEndian.Uint32(header[9:13]),
+			CompressedLen:   binary.LittleEndian.Uint32(header[13:17]),
+			src:             src,
+			offset:          offset,
+		}
+
+		if chunk.CompressedLen != 0 {
+			if offset+int64(chunk.CompressedLen) > end {
+				return nil, fmt.Errorf("reading compressed payload: %w", io.ErrUnexpectedEOF)
+			}
+			offset += int64(chunk.CompressedLen)
+		} else {
+			// There might be uncompressed payload; usually it's not present
+			offset += int64(chunk.UncompressedLen)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// Index returns the headers of all chunks in file order.
+func (r *ROFLReader) Index() []Chunk {
+	return r.chunks
+}
+
+// Next returns the next chunk, or io.EOF after the last one.
+func (r *ROFLReader) Next() (*Chunk, error) {
+	if r.next >= len(r.chunks) {
+		return nil, io.EOF
+	}
+	chunk := &r.chunks[r.next]
+	r.next++
+	return chunk, nil
+}
+
+// Close closes the file opened by OpenROFL.
+func (r *ROFLReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// ParseROFL reads a replay file and returns its metadata together with the decompressed chunks.
+// It holds every payload in memory; use OpenROFL to stream large replays.
+func ParseROFL(path string) (*ROFLMetadata, []Chunk, error) {
+	r, err := OpenROFL(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init zstd decoder: %w", err)
+		return nil, nil, err
 	}
-	defer decoder.Close()
+	defer r.Close()
 
+	var chunks []Chunk
 	for {
-		// Check if enough data remains for a chunk header (4+1+4+4+4=17 bytes)
-		header := make([]byte, 17)
-		n, err := buf.Read(header)
-		if err == io.EOF || n < 17 {
+		chunk, err := r.Next()
+		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading chunk header: %w", err)
+			return nil, nil, err
 		}
-		chunk := Chunk{}
-		chunk.ID = binary.LittleEndian.Uint32(header[0:4])
-		chunk.Type = header[4]
-		chunk.ID2 = binary.LittleEndian.Uint32(header[5:9])
-		chunk.UncompressedLen = binary.LittleEndian.Uint32(header[9:13])
-		chunk.CompressedLen = binary.LittleEndian.Uint32(header[13:17])
-
-		// --- Read payload ---
+
 		if chunk.CompressedLen != 0 {
-			compressed := make([]byte, chunk.CompressedLen)
-			_, err = io.ReadFull(buf, compressed)
+			payload, err := readChunk(chunk)
 			if err != nil {
-				return nil, fmt.Errorf("reading compressed payload: %w", err)
-			}
-			payload, err := decoder.DecodeAll(compressed, nil)
-			if err != nil {
-				return nil, fmt.Errorf("zstd decompress failed: %w", err)
+				return nil, nil, fmt.Errorf("zstd decompress failed: %w", err)
 			}
 			chunk.Payload = payload
-		} else if chunk.UncompressedLen != 0 {
-			// There might be uncompressed payload; usually it's not present
-			_, err := buf.Seek(int64(chunk.UncompressedLen), io.SeekCurrent)
-			if err != nil {
-				return nil, fmt.Errorf("seeking past uncompressed payload: %w", err)
-			}
-			// chunk.Payload = nil
 		}
-		chunks = append(chunks, chunk)
+		chunks = append(chunks, *chunk)
 	}
 
-	return chunks, nil
+	return r.Metadata, chunks, nil
+}
+
+// VerifyROFL checks that a replay file is complete: the metadata decodes and every chunk
+// decompresses. It makes the same checks as ParseROFL without keeping the payloads in memory.
+func VerifyROFL(path string) error {
+	r, err := OpenROFL(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		chunk, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if chunk.CompressedLen == 0 {
+			continue
+		}
+
+		rc, err := chunk.Open()
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("zstd decompress failed for chunk %d: %w", chunk.ID, err)
+		}
+		if n != int64(chunk.UncompressedLen) {
+			return fmt.Errorf("chunk %d decompressed to %d bytes, expected %d", chunk.ID, n, chunk.UncompressedLen)
+		}
+	}
+}
+
+func readChunk(chunk *Chunk) ([]byte, error) {
+	rc, err := chunk.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
 }
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// roflHeaderScanLen is how much of the file start is searched for the header fields.
+const roflHeaderScanLen = 0x40
+
+var roflGameVersion = regexp.MustCompile(`\d+\.\d+\.\d+(\.\d+)?`)
+
+// ROFLMetadata is the header and trailing metadata of a replay file.
+type ROFLMetadata struct {
+	Magic         string `json:"magic"`
+	FormatVersion uint16 `json:"formatVersion"`
+	GameVersion   string `json:"gameVersion"`
+	// GameLength is in milliseconds.
+	GameLength      int64             `json:"gameLength"`
+	LastGameChunkID int               `json:"lastGameChunkId"`
+	LastKeyFrameID  int               `json:"lastKeyFrameId"`
+	Players         []ROFLPlayerStats `json:"players"`
+}
+
+// ROFLPlayerStats are the end-of-game stats of one player from the replay's statsJson.
+// Stats without a typed field are still available in Raw.
+type ROFLPlayerStats struct {
+	Name                        string `json:"name"`
+	PUUID                       string `json:"puuid"`
+	RiotIDGameName              string `json:"riotIdGameName"`
+	RiotIDTagline               string `json:"riotIdTagline"`
+	Champion                    string `json:"champion"`
+	Team                        int    `json:"team"`
+	Win                         bool   `json:"win"`
+	Position                    string `json:"position"`
+	Level                       int    `json:"level"`
+	Kills                       int    `json:"kills"`
+	Deaths                      int    `json:"deaths"`
+	Assists                     int    `json:"assists"`
+	MinionsKilled               int    `json:"minionsKilled"`
+	NeutralMinionsKilled        int    `json:"neutralMinionsKilled"`
+	GoldEarned                  int    `json:"goldEarned"`
+	DoubleKills                 int    `json:"doubleKills"`
+	TripleKills                 int    `json:"tripleKills"`
+	QuadraKills                 int    `json:"quadraKills"`
+	PentaKills                  int    `json:"pentaKills"`
+	TotalDamageDealtToChampions int    `json:"totalDamageDealtToChampions"`
+	TotalDamageTaken            int    `json:"totalDamageTaken"`
+	VisionScore                 int    `json:"visionScore"`
+	Items                       [7]int `json:"items"`
+
+	Raw map[string]string `json:"raw"`
+}
+
+// ReadROFLMetadata reads only the header and the trailing metadata of a replay file.
+func ReadROFLMetadata(path string) (*ROFLMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	metadata, _, err := readROFLMetadata(f, info.Size())
+	return metadata, err
+}
+
+// readROFLMetadata parses the header and trailing metadata of src and returns the metadata length.
+func readROFLMetadata(src io.ReaderAt, size int64) (*ROFLMetadata, int64, error) {
+	if size < 4 {
+		return nil, 0, errors.New("file too short")
+	}
+
+	header := make([]byte, min(size, roflHeaderScanLen))
+	if _, err := src.ReadAt(header, 0); err != nil {
+		return nil, 0, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var lenBuf [4]byte
+	if _, err := src.ReadAt(lenBuf[:], size-4); err != nil {
+		return nil, 0, fmt.Errorf("failed to read metadata length: %w", err)
+	}
+	metaLen := int64(binary.LittleEndian.Uint32(lenBuf[:]))
+	if size < metaLen+4 {
+		return nil, 0, errors.New("file is corrupted (metadata length too large)")
+	}
+
+	meta := make([]byte, metaLen)
+	if _, err := src.ReadAt(meta, size-4-metaLen); err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	metadata, err := parseROFLMetadata(header, meta)
+	if err != nil {
+		return nil, 0, err
+	}
+	return metadata, metaLen, nil
+}
+
+func parseROFLMetadata(header, meta []byte) (*ROFLMetadata, error) {
+	var raw struct {
+		GameVersion     string `json:"gameVersion"`
+		GameLength      int64  `json:"gameLength"`
+		LastGameChunkID int    `json:"lastGameChunkId"`
+		LastKeyFrameID  int    `json:"lastKeyFrameId"`
+		StatsJSON       string `json:"statsJson"`
+	}
+	if err := json.Unmarshal(meta, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	metadata := &ROFLMetadata{
+		GameVersion:     raw.GameVersion,
+		GameLength:      raw.GameLength,
+		LastGameChunkID: raw.LastGameChunkID,
+		LastKeyFrameID:  raw.LastKeyFrameID,
+	}
+	if len(header) >= 6 {
+		metadata.Magic = string(header[:4])
+		metadata.FormatVersion = binary.LittleEndian.Uint16(header[4:6])
+	}
+	if metadata.Magic != "RIOT" {
+		return nil, fmt.Errorf("not a replay file (magic %q)", metadata.Magic)
+	}
+	// Newer replays only carry the game version in the header.
+	if metadata.GameVersion == "" {
+		metadata.GameVersion = string(roflGameVersion.Find(header))
+	}
+
+	if raw.StatsJSON != "" {
+		var stats []map[string]any
+		if err := json.Unmarshal([]byte(raw.StatsJSON), &stats); err != nil {
+			return nil, fmt.Errorf("failed to decode statsJson: %w", err)
+		}
+		for _, s := range stats {
+			metadata.Players = append(metadata.Players, mapROFLPlayerStats(s))
+		}
+	}
+
+	return metadata, nil
+}
+
+func mapROFLPlayerStats(stats map[string]any) ROFLPlayerStats {
+	raw := make(map[string]string, len(stats))
+	for k, v := range stats {
+		switch v := v.(type) {
+		case string:
+			raw[k] = v
+		case nil:
+		default:
+			raw[k] = fmt.Sprint(v)
+		}
+	}
+	num := func(key string) int {
+		n, _ := strconv.Atoi(raw[key])
+		return n
+	}
+
+	position := raw["TEAM_POSITION"]
+	if position == "" {
+		position = raw["INDIVIDUAL_POSITION"]
+	}
+
+	player := ROFLPlayerStats{
+		Name:                        raw["NAME"],
+		PUUID:                       raw["PUUID"],
+		RiotIDGameName:              raw["RIOT_ID_GAME_NAME"],
+		RiotIDTagline:               raw["RIOT_ID_TAG_LINE"],
+		Champion:                    raw["SKIN"],
+		Team:                        num("TEAM"),
+		Win:                         strings.EqualFold(raw["WIN"], "Win"),
+		Position:                    position,
+		Level:                       num("LEVEL"),
+		Kills:                       num("CHAMPIONS_KILLED"),
+		Deaths:                      num("NUM_DEATHS"),
+		Assists:                     num("ASSISTS"),
+		MinionsKilled:               num("MINIONS_KILLED"),
+		NeutralMinionsKilled:        num("NEUTRAL_MINIONS_KILLED"),
+		GoldEarned:                  num("GOLD_EARNED"),
+		DoubleKills:                 num("DOUBLE_KILLS"),
+		TripleKills:                 num("TRIPLE_KILLS"),
+		QuadraKills:                 num("QUADRA_KILLS"),
+		PentaKills:                  num("PENTA_KILLS"),
+		TotalDamageDealtToChampions: num("TOTAL_DAMAGE_DEALT_TO_CHAMPIONS"),
+		TotalDamageTaken:            num("TOTAL_DAMAGE_TAKEN"),
+		VisionScore:                 num("VISION_SCORE"),
+		Raw:                         raw,
+	}
+	for i := range player.Items {
+		player.Items[i] = num(fmt.Sprintf("ITEM%d", i))
+	}
+	return player
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestROFL builds a minimal replay file: header, zstd chunks, signature and metadata.
+func writeTestROFL(t *testing.T, gameVersion string, metadata map[string]any, payloads ...[]byte) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	header := make([]byte, 0x10+0xD)
+	copy(header, "RIOT\x02\x00")
+	copy(header[6:], gameVersion)
+	buf.Write(header)
+
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer encoder.Close()
+	for i, payload := range payloads {
+		compressed := encoder.EncodeAll(payload, nil)
+		chunkHeader := make([]byte, 17)
+		binary.LittleEndian.PutUint32(chunkHeader[0:4], uint32(i+1))
+		chunkHeader[4] = 1
+		binary.LittleEndian.PutUint32(chunkHeader[9:13], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(chunkHeader[13:17], uint32(len(compressed)))
+		buf.Write(chunkHeader)
+		buf.Write(compressed)
+	}
+
+	buf.Write(make([]byte, 0x100)) // signature
+
+	meta, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	buf.Write(meta)
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(len(meta))))
+
+	path := filepath.Join(t.TempDir(), "test.rofl")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	return path
+}
+
+func testROFLMetadata() map[string]any {
+	stats, _ := json.Marshal([]map[string]string{
+		{
+			"NAME": "", "PUUID": "puuid-1", "RIOT_ID_GAME_NAME": "Faker", "RIOT_ID_TAG_LINE": "KR1",
+			"SKIN": "Ahri", "TEAM": "100", "WIN": "Win", "TEAM_POSITION": "MIDDLE", "LEVEL": "17",
+			"CHAMPIONS_KILLED": "9", "NUM_DEATHS": "2", "ASSISTS": "11", "MINIONS_KILLED": "231",
+			"PENTA_KILLS": "1", "ITEM0": "3089", "ITEM6": "3340", "VISION_SCORE": "31",
+		},
+		{"PUUID": "puuid-2", "SKIN": "MonkeyKing", "TEAM": "200", "WIN": "Fail", "INDIVIDUAL_POSITION": "JUNGLE"},
+	})
+	return map[string]any{
+		"gameLength":      1834567,
+		"lastGameChunkId": 31,
+		"lastKeyFrameId":  15,
+		"statsJson":       string(stats),
+	}
+}
+
+func TestReadROFLMetadata(t *testing.T) {
+	path := writeTestROFL(t, "15.3.652.4355", testROFLMetadata(), []byte("chunk one"))
+
+	metadata, err := ReadROFLMetadata(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "RIOT", metadata.Magic)
+	assert.Equal(t, uint16(2), metadata.FormatVersion)
+	assert.Equal(t, "15.3.652.4355", metadata.GameVersion)
+	assert.Equal(t, int64(1834567), metadata.GameLength)
+	assert.Equal(t, 31, metadata.LastGameChunkID)
+	assert.Equal(t, 15, metadata.LastKeyFrameID)
+
+	require.Len(t, metadata.Players, 2)
+	p := metadata.Players[0]
+	assert.Equal(t, "puuid-1", p.PUUID)
+	assert.Equal(t, "Ahri", p.Champion)
+	assert.Equal(t, 100, p.Team)
+	assert.True(t, p.Win)
+	assert.Equal(t, "MIDDLE", p.Position)
+	assert.Equal(t, 9, p.Kills)
+	assert.Equal(t, 2, p.Deaths)
+	assert.Equal(t, 11, p.Assists)
+	assert.Equal(t, 1, p.PentaKills)
+	assert.Equal(t, [7]int{3089, 0, 0, 0, 0, 0, 3340}, p.Items)
+	assert.Equal(t, "31", p.Raw["VISION_SCORE"])
+
+	assert.False(t, metadata.Players[1].Win)
+	assert.Equal(t, "JUNGLE", metadata.Players[1].Position)
+}
+
+func TestParseROFLReturnsMetadataAndChunks(t *testing.T) {
+	path := writeTestROFL(t, "15.3.652.4355", testROFLMetadata(), []byte("chunk one"), []byte("chunk two"))
+
+	metadata, chunks, err := ParseROFL(path)
+	require.NoError(t, err)
+	assert.Equal(t, 31, metadata.LastGameChunkID)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, []byte("chunk one"), chunks[0].Payload)
+	assert.Equal(t, uint32(2), chunks[1].ID)
+	assert.Equal(t, []byte("chunk two"), chunks[1].Payload)
+}
+
+func TestReadROFLMetadataRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bogus.rofl")
+	require.NoError(t, os.WriteFile(path, []byte("not a replay{}\x02\x00\x00\x00"), 0o644))
+
+	_, err := ReadROFLMetadata(path)
+	assert.Error(t, err)
+}
+
+func TestROFLReaderStreamsChunks(t *testing.T) {
+	path := writeTestROFL(t, "15.3.652.4355", testROFLMetadata(), []byte("chunk one"), bytes.Repeat([]byte("x"), 1<<16))
+
+	r, err := OpenROFL(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, 31, r.Metadata.LastGameChunkID)
+	require.Len(t, r.Index(), 2)
+	assert.Nil(t, r.Index()[1].Payload)
+
+	var ids []uint32
+	var lens []int
+	for {
+		chunk, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		rc, err := chunk.Open()
+		require.NoError(t, err)
+		n, err := io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		ids = append(ids, chunk.ID)
+		lens = append(lens, int(n))
+	}
+	assert.Equal(t, []uint32{1, 2}, ids)
+	assert.Equal(t, []int{9, 1 << 16}, lens)
+}
+
+func TestNewROFLReaderDetectsTruncatedChunk(t *testing.T) {
+	path := writeTestROFL(t, "15.3.652.4355", testROFLMetadata(), []byte("chunk one"))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Claim a compressed length that runs into the signature.
+	binary.LittleEndian.PutUint32(raw[0x10+0xD+13:], 1<<20)
+	_, err = NewROFLReader(bytes.NewReader(raw), int64(len(raw)))
+	assert.Error(t, err)
+}
+
+func TestVerifyROFL(t *testing.T) {
+	path := writeTestROFL(t, "15.3.652.4355", testROFLMetadata(), []byte("chunk one"), bytes.Repeat([]byte("x"), 1<<16))
+	require.NoError(t, VerifyROFL(path))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	truncated := filepath.Join(t.TempDir(), "truncated.rofl")
+	require.NoError(t, os.WriteFile(truncated, raw[:len(raw)/2], 0o644))
+	assert.Error(t, VerifyROFL(truncated))
+
+	// Flip bytes inside the first compressed payload.
+	corrupt := filepath.Join(t.TempDir(), "corrupt.rofl")
+	broken := bytes.Clone(raw)
+	for i := 0x10 + 0xD + 17; i < 0x10+0xD+17+8; i++ {
+		broken[i] ^= 0xFF
+	}
+	require.NoError(t, os.WriteFile(corrupt, broken, 0o644))
+	assert.Error(t, VerifyROFL(corrupt))
+}
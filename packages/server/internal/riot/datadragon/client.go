@@ -228,6 +228,25 @@ func (c *DataDragonClient) getChampionName(championID int) (string, error) {
 	return name, nil
 }
 
+// ChampionID returns the champion ID for a champion name such as "MonkeyKing", loading the map if needed.
+func (c *DataDragonClient) ChampionID(name string) (int, bool) {
+	c.championMapOnce.Do(func() {
+		if err := c.loadChampionMap(); err != nil {
+			logging.Error("Failed to load champion map", "error", err)
+		}
+	})
+
+	c.championMapMu.RLock()
+	defer c.championMapMu.RUnlock()
+
+	for id, championName := range c.championIDMap {
+		if championName == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
 // loadItemMap fetches item.json and builds ID→ImageName map
 func (c *DataDragonClient) loadItemMap() error {
 	itemData, err := c.GetItemData("en_US")
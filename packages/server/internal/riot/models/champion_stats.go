@@ -0,0 +1,94 @@
+package models
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// Patch returns the patch of a game version, e.g. "15.24" for "15.24.734.7485", or "" if the
+// version has no major and minor number.
+func Patch(gameVersion string) string {
+	parts := strings.SplitN(gameVersion, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	if _, err := strconv.Atoi(parts[0]); err != nil {
+		return ""
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// ComparePatches orders patches numerically, so "15.9" comes before "15.10". It returns -1,
+// 0 or 1. Patches that do not parse sort first.
+func ComparePatches(a, b string) int {
+	aMajor, aMinor := splitPatch(a)
+	bMajor, bMinor := splitPatch(b)
+	if aMajor != bMajor {
+		return cmp.Compare(aMajor, bMajor)
+	}
+	return cmp.Compare(aMinor, bMinor)
+}
+
+func splitPatch(patch string) (int, int) {
+	majorRaw, minorRaw, _ := strings.Cut(patch, ".")
+	major, err := strconv.Atoi(majorRaw)
+	if err != nil {
+		return -1, -1
+	}
+	minor, err := strconv.Atoi(minorRaw)
+	if err != nil {
+		return major, -1
+	}
+	return major, minor
+}
+
+// ChampionStatTotals are the summed stats of an account's games on one champion, lane, queue
+// and patch.
+type ChampionStatTotals struct {
+	ChampionID  int
+	Lane        string
+	QueueID     int
+	Patch       string
+	Games       int
+	Wins        int
+	Kills       int
+	Deaths      int
+	Assists     int
+	CS          int64
+	Damage      int64
+	ChampLevels int
+	Duration    int64 // seconds
+}
+
+// ChampionStatsFilter narrows champion stats down. FromDay and ToDay are UTC days since the
+// epoch, as the stats are kept per day; they and PatchMin and PatchMax are inclusive.
+type ChampionStatsFilter struct {
+	FromDay    *int64
+	ToDay      *int64
+	PatchMin   *string
+	PatchMax   *string
+	QueueID    *int
+	ChampionID *int
+	Lane       *string
+}
+
+// ChampionStats is an account's performance on one champion in one lane and queue.
+type ChampionStats struct {
+	ChampionID    int     `json:"championId"`
+	Lane          string  `json:"lane"`
+	QueueID       int     `json:"queueId"`
+	Games         int     `json:"games"`
+	Wins          int     `json:"wins"`
+	WinRate       float64 `json:"winRate"`
+	Kills         float64 `json:"kills"`
+	Deaths        float64 `json:"deaths"`
+	Assists       float64 `json:"assists"`
+	KDA           float64 `json:"kda"`
+	CSPerMin      float64 `json:"csPerMin"`
+	DamagePerMin  float64 `json:"damagePerMin"`
+	AvgChampLevel float64 `json:"avgChampLevel"`
+}
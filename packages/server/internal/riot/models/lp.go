@@ -0,0 +1,51 @@
+package models
+
+// RankedGame is one of an account's games in a ranked queue. StartedAt is unix milliseconds
+// and Duration seconds.
+type RankedGame struct {
+	MatchID    int64
+	QueueID    int
+	StartedAt  int64
+	Duration   int
+	ChampionID int
+	Win        bool
+}
+
+// EndedAt returns when the game ended in unix milliseconds.
+func (g RankedGame) EndedAt() int64 {
+	return g.StartedAt + int64(g.Duration)*1000
+}
+
+// RankPosition is a place on the ranked ladder.
+type RankPosition struct {
+	Tier         string `json:"tier"`
+	Rank         string `json:"rank"`
+	LeaguePoints int    `json:"leaguePoints"`
+}
+
+// MatchLPChange is the LP an account gained or lost in a ranked game. Before and After are the
+// rank snapshots that bracket the game. When other games fall between them too, the change is
+// split over those games and marked approximate.
+type MatchLPChange struct {
+	MatchID     int64        `json:"matchId"`
+	QueueID     int          `json:"queueId"`
+	StartedAt   int64        `json:"startedAt"`
+	ChampionID  int          `json:"championId"`
+	Win         bool         `json:"win"`
+	LPDelta     int          `json:"lpDelta"`
+	Promotion   bool         `json:"promotion,omitempty"`
+	Demotion    bool         `json:"demotion,omitempty"`
+	Approximate bool         `json:"approximate"`
+	Before      RankPosition `json:"before"`
+	After       RankPosition `json:"after"`
+}
+
+// LPPoint is a rank snapshot on an LP-over-time chart. LadderPoints counts the LP from the
+// bottom of Iron IV so that points of different tiers and divisions can share one axis.
+type LPPoint struct {
+	RankPosition
+	Timestamp    int64 `json:"timestamp"`
+	Wins         int   `json:"wins"`
+	Losses       int   `json:"losses"`
+	LadderPoints int   `json:"ladderPoints"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// MatchupFilter narrows lane matchups down. ChampionID is the account's own champion, From
+// and To are unix seconds, and matchups with fewer than MinGames games are left out.
+type MatchupFilter struct {
+	ChampionID *int
+	Lane       *string
+	QueueID    *int
+	From       *int64
+	To         *int64
+	MinGames   int
+}
+
+// LaneMatchupTotals are the summed results of an account's games against one enemy champion
+// in one lane. Differentials are the account's value minus the opponent's.
+type LaneMatchupTotals struct {
+	EnemyChampionID int
+	Lane            string
+	Games           int
+	Wins            int
+	KillDiff        int
+	DeathDiff       int
+	DamageDiff      int64
+	CSDiff          int64
+}
+
+// LaneMatchup is how an account fares against one enemy champion in one lane. Differentials
+// are per-game averages of the account's value minus its lane opponent's.
+type LaneMatchup struct {
+	EnemyChampionID int     `json:"enemyChampionId"`
+	Lane            string  `json:"lane"`
+	Games           int     `json:"games"`
+	Wins            int     `json:"wins"`
+	WinRate         float64 `json:"winRate"`
+	KillDiff        float64 `json:"killDiff"`
+	DeathDiff       float64 `json:"deathDiff"`
+	DamageDiff      float64 `json:"damageDiff"`
+	CSDiff          float64 `json:"csDiff"`
+}
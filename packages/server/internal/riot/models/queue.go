@@ -0,0 +1,7 @@
+package models
+
+import "errors"
+
+// ErrLeaseLost is returned when a worker acks or nacks a match it no longer holds the lease
+// of, e.g. because the lease expired and the match was reclaimed.
+var ErrLeaseLost = errors.New("match lease lost")
@@ -0,0 +1,39 @@
+package models
+
+// PlayedGame is one of an account's games with its result. StartedAt is unix milliseconds and
+// Duration seconds.
+type PlayedGame struct {
+	MatchID    int64
+	PUUID      string
+	QueueID    int
+	StartedAt  int64
+	Duration   int
+	ChampionID int
+	Win        bool
+	Kills      int
+	Deaths     int
+	Assists    int
+}
+
+// EndedAt returns when the game ended in unix milliseconds.
+func (g PlayedGame) EndedAt() int64 {
+	return g.StartedAt + int64(g.Duration)*1000
+}
+
+// PlaySession is a run of games played with short breaks between them. StartedAt and EndedAt
+// are unix milliseconds. NetLP sums the LP changes of its ranked games, and KDATrend is how
+// much the KDA changed per game over the session.
+type PlaySession struct {
+	StartedAt         int64   `json:"startedAt"`
+	EndedAt           int64   `json:"endedAt"`
+	Games             int     `json:"games"`
+	Wins              int     `json:"wins"`
+	Losses            int     `json:"losses"`
+	NetLP             int     `json:"netLp"`
+	LPApproximate     bool    `json:"lpApproximate"`
+	KDA               float64 `json:"kda"`
+	LongestLossStreak int     `json:"longestLossStreak"`
+	KDATrend          float64 `json:"kdaTrend"`
+	Tilted            bool    `json:"tilted"`
+	MatchIDs          []int64 `json:"matchIds"`
+}
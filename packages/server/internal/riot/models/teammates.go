@@ -0,0 +1,50 @@
+package models
+
+// TeammateTotals are the games an account played on the same team as another player.
+// GameName, TagLine and Region are from the latest of those games, and LastSeen is when it
+// started in unix milliseconds. StreamerID is set when the player is a known account.
+type TeammateTotals struct {
+	PUUID      string
+	GameName   string
+	TagLine    string
+	Region     string
+	Games      int
+	Wins       int
+	LastSeen   int64
+	StreamerID *int
+}
+
+// TrackSuggestion is the body of an add account request for a recurring teammate, to be
+// completed with the streamer the account belongs to.
+type TrackSuggestion struct {
+	Region   string `json:"region"`
+	GameName string `json:"game_name"`
+	TagLine  string `json:"tag_line"`
+}
+
+// Teammate is a player who keeps showing up on an account's team. Players that are not known
+// accounts come with a suggestion to add them.
+type Teammate struct {
+	PUUID      string           `json:"puuid"`
+	GameName   string           `json:"gameName"`
+	TagLine    string           `json:"tagLine"`
+	Games      int              `json:"games"`
+	Wins       int              `json:"wins"`
+	WinRate    float64          `json:"winRate"`
+	LastSeen   int64            `json:"lastSeen"`
+	StreamerID *int             `json:"streamerId,omitempty"`
+	Suggestion *TrackSuggestion `json:"suggestion,omitempty"`
+}
+
+// StreamerMeeting is a game in which an account of a streamer met an account of another
+// streamer. StartedAt is unix milliseconds.
+type StreamerMeeting struct {
+	MatchID           int64  `json:"matchId"`
+	StartedAt         int64  `json:"startedAt"`
+	QueueID           int    `json:"queueId"`
+	PUUID             string `json:"puuid"`
+	OtherStreamerID   int    `json:"otherStreamerId"`
+	OtherStreamerName string `json:"otherStreamerName"`
+	OtherPUUID        string `json:"otherPuuid"`
+	SameTeam          bool   `json:"sameTeam"`
+}
@@ -13,6 +13,57 @@ type APIReplaysResponse struct {
 type Match struct {
 	Summary      MatchSummary              `json:"summary" db:"-"`
 	Participants []MatchParticipantSummary `json:"participants" db:"-"`
+	VODs         []MatchVOD                `json:"vods,omitempty" db:"-"`
+}
+
+// MatchVOD locates (part of) a match inside a streamer's broadcast.
+type MatchVOD struct {
+	MatchID     int64  `json:"gameId" db:"match_id"`
+	BroadcastID int64  `json:"broadcastId" db:"broadcast_id"`
+	StreamerID  int64  `json:"streamerId" db:"streamer_id"`
+	PUUID       string `json:"puuid" db:"puuid"`
+	// VODOffset is the number of seconds into the broadcast where the match shows up.
+	VODOffset int64 `json:"vodOffset" db:"vod_offset"`
+	// MatchOffset is the number of seconds into the match where the broadcast picks up.
+	// It is non-zero when the match started before the broadcast did.
+	MatchOffset int64 `json:"matchOffset" db:"match_offset"`
+	// Overlap is the number of seconds of the match covered by the broadcast.
+	Overlap int64  `json:"overlap" db:"overlap"`
+	URL     string `json:"url" db:"url"`
+}
+
+// StoredReplay is a match with a stored replay and the facts replay retention looks at.
+type StoredReplay struct {
+	MatchID   int64
+	ReplayURI string
+	StartedAt int64 // unix ms
+	Aligned   bool  // the match has a VOD alignment
+	Pentakill bool  // some participant got a pentakill
+}
+
+// MatchBroadcast pairs a match with an overlapping broadcast of the streamer who played it.
+type MatchBroadcast struct {
+	MatchID            int64
+	PUUID              string
+	StreamerID         int64
+	StartedAt          int64 // unix ms
+	Duration           int64 // seconds
+	BroadcastID        int64
+	BroadcastURL       string
+	BroadcastCreatedAt int64 // unix s
+	BroadcastDuration  int64 // seconds
+}
+
+// AlignedKill is a champion kill by the streamer in a match aligned with one of their VODs.
+type AlignedKill struct {
+	MatchID      int64
+	BroadcastID  int64
+	ChannelID    string
+	BroadcastURL string
+	VODOffset    int64 // seconds, see MatchVOD
+	MatchOffset  int64 // seconds, see MatchVOD
+	Overlap      int64 // seconds, see MatchVOD
+	Time         int64 // ms into the match
 }
 
 type MatchSummary struct {
@@ -21,11 +72,15 @@ type MatchSummary struct {
 	StartedAt       int64         `json:"startedAt" db:"started_at"`
 	Duration        int           `json:"duration" db:"duration"`
 	QueueID         int           `json:"queueId" db:"queue_id"`
+	Patch           string        `json:"patch,omitempty" db:"patch"`
 	Status          models.Status `json:"status" db:"status"`
 	UpdatedAt       *time.Time    `json:"updatedAt" db:"updated_at"`
 	ReplayStatus    string        `json:"replayStatus" db:"replay_status"`
 	ReplayURI       *string       `json:"replayUri,omitempty" db:"replay_uri"`
 	ReplayUpdatedAt *time.Time    `json:"replayUpdatedAt,omitempty" db:"replay_updated_at"`
+	Attempts        int           `json:"attempts" db:"attempts"`
+	LastError       *string       `json:"lastError,omitempty" db:"last_error"`
+	NextAttemptAt   *time.Time    `json:"nextAttemptAt,omitempty" db:"next_attempt_at"`
 }
 
 type MatchParticipantSummary struct {
@@ -57,10 +112,13 @@ type MatchParticipantSummary struct {
 	TotalDamageDealtToChampions int    `json:"totalDamageDealtToChampions" db:"total_damage_dealt_to_champions"`
 	TotalDamageTaken            int    `json:"totalDamageTaken" db:"total_damage_taken"`
 	Win                         bool   `json:"win" db:"win"`
+	TeamID                      int    `json:"teamId" db:"team_id"`
+	TeamPosition                string `json:"teamPosition" db:"team_position"`
 }
 
 type MatchFilter struct {
 	MatchID      *int64
+	Status       *models.Status
 	StartedAtMin *int64
 	StartedAtMax *int64
 	HasReplay    *bool
@@ -68,6 +126,10 @@ type MatchFilter struct {
 	ChampionID   *int
 	Lane         *string
 	Win          *bool
+	// StreamerID keeps matches that were found on one of the streamer's broadcasts.
+	StreamerID *int64
+	// HasParticipants filters on whether participant rows were stored for the match.
+	HasParticipants *bool
 }
 
 type Account struct {
@@ -93,15 +155,79 @@ type PlayerRank struct {
 type MatchDetails struct {
 	Info struct {
 		ID           int64                     `json:"gameId"`
-		Region       string                    `json:"region"`
+		Region       string                    `json:"platformId"`
 		QueueID      int                       `json:"queueId"`
 		StartedAt    int64                     `json:"gameStartTimestamp"`
 		Duration     int                       `json:"gameDuration"`
+		GameVersion  string                    `json:"gameVersion"`
 		Participants []MatchParticipantSummary `json:"participants"`
 	} `json:"info"`
 }
 
+type MatchTimeline struct {
+	Info struct {
+		ID     int64                `json:"gameId"`
+		Frames []MatchTimelineFrame `json:"frames"`
+	} `json:"info"`
+}
+
+type MatchTimelineFrame struct {
+	Timestamp int64                `json:"timestamp"`
+	Events    []MatchTimelineEvent `json:"events"`
+}
+
+// MatchTimelineEvent is a raw match-v5 timeline event. Which fields are set depends on Type.
+type MatchTimelineEvent struct {
+	Type                    string `json:"type"`
+	Timestamp               int64  `json:"timestamp"`
+	ParticipantID           int    `json:"participantId"`
+	CreatorID               int    `json:"creatorId"`
+	KillerID                int    `json:"killerId"`
+	VictimID                int    `json:"victimId"`
+	AssistingParticipantIDs []int  `json:"assistingParticipantIds"`
+	ItemID                  int    `json:"itemId"`
+	Level                   int    `json:"level"`
+	WardType                string `json:"wardType"`
+	MonsterType             string `json:"monsterType"`
+	MonsterSubType          string `json:"monsterSubType"`
+	BuildingType            string `json:"buildingType"`
+	TowerType               string `json:"towerType"`
+	LaneType                string `json:"laneType"`
+	TeamID                  int    `json:"teamId"`
+	KillerTeamID            int    `json:"killerTeamId"`
+	Position                *struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"position"`
+}
+
+type MatchEventType string
+
+const (
+	MatchEventChampionKill  MatchEventType = "champion_kill"
+	MatchEventEliteMonster  MatchEventType = "elite_monster_kill"
+	MatchEventBuildingKill  MatchEventType = "building_kill"
+	MatchEventTurretPlate   MatchEventType = "turret_plate_destroyed"
+	MatchEventItemPurchased MatchEventType = "item_purchased"
+	MatchEventLevelUp       MatchEventType = "level_up"
+	MatchEventWardPlaced    MatchEventType = "ward_placed"
+	MatchEventWardKill      MatchEventType = "ward_kill"
+)
+
+// MatchEvent is a normalized timeline event. Time is the in-game timestamp in milliseconds
+// and ParticipantID is the player who caused the event (0 for minions, turrets and monsters).
 type MatchEvent struct {
-	Time      int64  `json:"time" db:"time"`
-	EventType string `json:"eventType" db:"event_type"`
+	MatchID       int64          `json:"matchId" db:"match_id"`
+	EventIndex    int            `json:"eventIndex" db:"event_index"`
+	ParticipantID int            `json:"participantId" db:"participant_id"`
+	Time          int64          `json:"time" db:"time"`
+	EventType     MatchEventType `json:"eventType" db:"event_type"`
+	VictimID      int            `json:"victimId,omitempty" db:"victim_id"`
+	AssistIDs     []int          `json:"assistIds,omitempty" db:"assist_ids"`
+	TeamID        int            `json:"teamId,omitempty" db:"team_id"`
+	ItemID        int            `json:"itemId,omitempty" db:"item_id"`
+	Level         int            `json:"level,omitempty" db:"level"`
+	Subtype       string         `json:"subtype,omitempty" db:"subtype"`
+	PositionX     *int           `json:"positionX,omitempty" db:"position_x"`
+	PositionY     *int           `json:"positionY,omitempty" db:"position_y"`
 }
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// ChampionStatsUpsert adds the account's participant rows of the matches selected by the %s
+// list ($1 is the PUUID) to its champion stats. Matches without a patch are counted under an
+// empty one.
+const ChampionStatsUpsert = `
+	INSERT INTO champion_stats (puuid, champion_id, lane, queue_id, patch, day,
+		games, wins, kills, deaths, assists, cs, damage, champ_levels, duration)
+	SELECT p.puuid, p.champion_id, p.lane, COALESCE(m.queue_id, 0), COALESCE(m.patch, ''), m.started_at / 86400000,
+		COUNT(*), SUM(CASE WHEN p.win THEN 1 ELSE 0 END), SUM(p.kills), SUM(p.deaths), SUM(p.assists),
+		SUM(p.total_minions_killed), SUM(p.total_damage_dealt_to_champions), SUM(p.champ_level), SUM(m.duration)
+	FROM participants p
+	INNER JOIN lol_matches m ON m.id = p.match_id
+	WHERE p.puuid = $1 AND p.match_id IN %s
+	GROUP BY p.puuid, p.champion_id, p.lane, COALESCE(m.queue_id, 0), COALESCE(m.patch, ''), m.started_at / 86400000
+	ON CONFLICT (puuid, champion_id, lane, queue_id, patch, day) DO UPDATE SET
+		games = champion_stats.games + EXCLUDED.games,
+		wins = champion_stats.wins + EXCLUDED.wins,
+		kills = champion_stats.kills + EXCLUDED.kills,
+		deaths = champion_stats.deaths + EXCLUDED.deaths,
+		assists = champion_stats.assists + EXCLUDED.assists,
+		cs = champion_stats.cs + EXCLUDED.cs,
+		damage = champion_stats.damage + EXCLUDED.damage,
+		champ_levels = champion_stats.champ_levels + EXCLUDED.champ_levels,
+		duration = champion_stats.duration + EXCLUDED.duration`
+
+// RefreshChampionStats adds the account's matches of at least minDuration seconds that are
+// not counted yet to its champion stats, and returns how many were added. The matches are
+// claimed in champion_stats_matches and totalled from the claimed rows in one statement, so a
+// match is only counted when this refresh is the one that marked it. Matches an overlapping
+// refresh claimed first are skipped instead of failing the statement. Matches counted before
+// their patch was known are moved to it first.
+func (s *DB) RefreshChampionStats(ctx context.Context, puuid string, minDuration int) (int64, error) {
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := RepatchChampionStats(ctx, tx, puuid); err != nil {
+		return 0, err
+	}
+
+	var added int64
+	err = tx.QueryRowContext(ctx, `
+		WITH claimed AS (
+			INSERT INTO champion_stats_matches (puuid, match_id, patch)
+			SELECT p.puuid, p.match_id, COALESCE(m.patch, '')
+			FROM participants p
+			INNER JOIN lol_matches m ON m.id = p.match_id
+			WHERE p.puuid = $1 AND m.started_at IS NOT NULL AND m.duration >= $2
+				AND NOT EXISTS (SELECT 1 FROM champion_stats_matches c WHERE c.puuid = p.puuid AND c.match_id = p.match_id)
+			ON CONFLICT (puuid, match_id) DO NOTHING
+			RETURNING match_id
+		), totals AS (`+fmt.Sprintf(ChampionStatsUpsert, "(SELECT match_id FROM claimed)")+`
+		)
+		SELECT COUNT(*) FROM claimed
+	`, puuid, minDuration).Scan(&added)
+	if err != nil {
+		return 0, fmt.Errorf("refresh champion stats of %s: %w", puuid, err)
+	}
+	return added, tx.Commit()
+}
+
+// RepatchChampionStats moves the account's matches that were counted without a patch to the
+// patch they have since been given. The matches are marked first, so an overlapping refresh
+// waits for the mark and then finds nothing left to move.
+func RepatchChampionStats(ctx context.Context, tx *sql.Tx, puuid string) error {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE champion_stats_matches AS c SET patch = m.patch
+		FROM lol_matches m
+		WHERE c.puuid = $1 AND c.patch = '' AND m.id = c.match_id AND m.patch IS NOT NULL
+		RETURNING match_id`, puuid)
+	if err != nil {
+		return fmt.Errorf("mark repatched champion stats matches of %s: %w", puuid, err)
+	}
+	var matchIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan repatched match: %w", err)
+		}
+		matchIDs = append(matchIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("mark repatched champion stats matches of %s: %w", puuid, err)
+	}
+	if len(matchIDs) == 0 {
+		return nil
+	}
+
+	in, inArgs := InList(2, matchIDs)
+	args := append([]any{puuid}, inArgs...)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE champion_stats AS s SET
+			games = s.games - d.games, wins = s.wins - d.wins, kills = s.kills - d.kills,
+			deaths = s.deaths - d.deaths, assists = s.assists - d.assists, cs = s.cs - d.cs,
+			damage = s.damage - d.damage, champ_levels = s.champ_levels - d.champ_levels,
+			duration = s.duration - d.duration
+		FROM (
+			SELECT p.champion_id, p.lane, COALESCE(m.queue_id, 0) AS queue_id, m.started_at / 86400000 AS day,
+				COUNT(*) AS games, SUM(CASE WHEN p.win THEN 1 ELSE 0 END) AS wins, SUM(p.kills) AS kills,
+				SUM(p.deaths) AS deaths, SUM(p.assists) AS assists, SUM(p.total_minions_killed) AS cs,
+				SUM(p.total_damage_dealt_to_champions) AS damage, SUM(p.champ_level) AS champ_levels,
+				SUM(m.duration) AS duration
+			FROM participants p
+			INNER JOIN lol_matches m ON m.id = p.match_id
+			WHERE p.puuid = $1 AND p.match_id IN `+in+`
+			GROUP BY p.champion_id, p.lane, COALESCE(m.queue_id, 0), m.started_at / 86400000
+		) AS d
+		WHERE s.puuid = $1 AND s.patch = '' AND s.champion_id = d.champion_id AND s.lane = d.lane
+			AND s.queue_id = d.queue_id AND s.day = d.day`, args...); err != nil {
+		return fmt.Errorf("remove repatched champion stats of %s: %w", puuid, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM champion_stats WHERE puuid = $1 AND patch = '' AND games = 0`, puuid); err != nil {
+		return fmt.Errorf("remove empty champion stats of %s: %w", puuid, err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(ChampionStatsUpsert, in), args...); err != nil {
+		return fmt.Errorf("add repatched champion stats of %s: %w", puuid, err)
+	}
+	return nil
+}
+
+// ListChampionStats returns the account's champion stat totals per champion, lane, queue and
+// patch. The patch window of the filter is left to the caller.
+func (s *DB) ListChampionStats(ctx context.Context, puuid string, filter *riot.ChampionStatsFilter) ([]riot.ChampionStatTotals, error) {
+	where := []string{"puuid = $1"}
+	args := []any{puuid}
+	argN := 2
+
+	if filter != nil {
+		if filter.FromDay != nil {
+			where = append(where, fmt.Sprintf("day >= $%d", argN))
+			args = append(args, *filter.FromDay)
+			argN++
+		}
+		if filter.ToDay != nil {
+			where = append(where, fmt.Sprintf("day <= $%d", argN))
+			args = append(args, *filter.ToDay)
+			argN++
+		}
+		if filter.QueueID != nil {
+			where = append(where, fmt.Sprintf("queue_id = $%d", argN))
+			args = append(args, *filter.QueueID)
+			argN++
+		}
+		if filter.ChampionID != nil {
+			where = append(where, fmt.Sprintf("champion_id = $%d", argN))
+			args = append(args, *filter.ChampionID)
+			argN++
+		}
+		if filter.Lane != nil {
+			where = append(where, fmt.Sprintf("lane = $%d", argN))
+			args = append(args, *filter.Lane)
+		}
+	}
+
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		SELECT champion_id, lane, queue_id, patch, SUM(games), SUM(wins), SUM(kills), SUM(deaths), SUM(assists),
+			SUM(cs), SUM(damage), SUM(champ_levels), SUM(duration)
+		FROM champion_stats
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY champion_id, lane, queue_id, patch`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list champion stats of %s: %w", puuid, err)
+	}
+	defer rows.Close()
+
+	var totals []riot.ChampionStatTotals
+	for rows.Next() {
+		var t riot.ChampionStatTotals
+		if err := rows.Scan(
+			&t.ChampionID, &t.Lane, &t.QueueID, &t.Patch, &t.Games, &t.Wins, &t.Kills, &t.Deaths, &t.Assists,
+			&t.CS, &t.Damage, &t.ChampLevels, &t.Duration,
+		); err != nil {
+			return nil, fmt.Errorf("scan champion stats: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
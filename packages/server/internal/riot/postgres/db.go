@@ -1,6 +1,11 @@
 package postgres
 
-import platformdb "github.com/galchammat/kadeem/internal/platform/database"
+import (
+	"fmt"
+	"strings"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+)
 
 type DB struct {
 	db *platformdb.DB
@@ -9,3 +14,15 @@ type DB struct {
 func New(db *platformdb.DB) *DB {
 	return &DB{db: db}
 }
+
+// InList returns "($first, $first+1, ...)" with one placeholder per id, and the ids as
+// arguments. Unlike = ANY($1) it also works on SQLite.
+func InList(first int, ids []int64) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", first+i)
+		args[i] = id
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", args
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/lib/pq"
+)
+
+func (s *DB) SaveMatchEventBatch(ctx context.Context, events []models.MatchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	matchIDs := make([]int64, len(events))
+	eventIndexes := make([]int, len(events))
+	participantIDs := make([]int, len(events))
+	times := make([]int64, len(events))
+	eventTypes := make([]string, len(events))
+	victimIDs := make([]int, len(events))
+	assistIDs := make([]string, len(events))
+	teamIDs := make([]int, len(events))
+	itemIDs := make([]int, len(events))
+	levels := make([]int, len(events))
+	subtypes := make([]string, len(events))
+	positionXs := make([]*int, len(events))
+	positionYs := make([]*int, len(events))
+
+	for i, event := range events {
+		matchIDs[i] = event.MatchID
+		eventIndexes[i] = event.EventIndex
+		participantIDs[i] = event.ParticipantID
+		times[i] = event.Time
+		eventTypes[i] = string(event.EventType)
+		victimIDs[i] = event.VictimID
+		assistIDs[i] = IntArrayLiteral(event.AssistIDs)
+		teamIDs[i] = event.TeamID
+		itemIDs[i] = event.ItemID
+		levels[i] = event.Level
+		subtypes[i] = event.Subtype
+		positionXs[i] = event.PositionX
+		positionYs[i] = event.PositionY
+	}
+
+	_, err := s.db.SQL.ExecContext(ctx, `
+		INSERT INTO match_events (
+			match_id,
+			event_index,
+			participant_id,
+			time,
+			event_type,
+			victim_id,
+			assist_ids,
+			team_id,
+			item_id,
+			level,
+			subtype,
+			position_x,
+			position_y
+		)
+		SELECT
+			match_id,
+			event_index,
+			participant_id,
+			time,
+			event_type,
+			victim_id,
+			assist_ids::integer[],
+			team_id,
+			item_id,
+			level,
+			subtype,
+			position_x,
+			position_y
+		FROM unnest(
+			$1::bigint[],
+			$2::integer[],
+			$3::integer[],
+			$4::bigint[],
+			$5::text[],
+			$6::integer[],
+			$7::text[],
+			$8::integer[],
+			$9::integer[],
+			$10::integer[],
+			$11::text[],
+			$12::integer[],
+			$13::integer[]
+		) AS batch(
+			match_id,
+			event_index,
+			participant_id,
+			time,
+			event_type,
+			victim_id,
+			assist_ids,
+			team_id,
+			item_id,
+			level,
+			subtype,
+			position_x,
+			position_y
+		)
+		ON CONFLICT (match_id, event_index) DO UPDATE SET
+			participant_id = EXCLUDED.participant_id,
+			time = EXCLUDED.time,
+			event_type = EXCLUDED.event_type,
+			victim_id = EXCLUDED.victim_id,
+			assist_ids = EXCLUDED.assist_ids,
+			team_id = EXCLUDED.team_id,
+			item_id = EXCLUDED.item_id,
+			level = EXCLUDED.level,
+			subtype = EXCLUDED.subtype,
+			position_x = EXCLUDED.position_x,
+			position_y = EXCLUDED.position_y
+	`,
+		pq.Array(matchIDs),
+		pq.Array(eventIndexes),
+		pq.Array(participantIDs),
+		pq.Array(times),
+		pq.Array(eventTypes),
+		pq.Array(victimIDs),
+		pq.Array(assistIDs),
+		pq.Array(teamIDs),
+		pq.Array(itemIDs),
+		pq.Array(levels),
+		pq.Array(subtypes),
+		pq.Array(positionXs),
+		pq.Array(positionYs),
+	)
+	if err != nil {
+		return fmt.Errorf("save match event batch: %w", err)
+	}
+
+	return nil
+}
+
+// intArrayLiteral renders ids as a Postgres array literal so nested arrays can go through unnest as text.
+func IntArrayLiteral(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
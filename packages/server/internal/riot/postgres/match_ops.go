@@ -2,55 +2,172 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/models"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/lib/pq"
 )
 
-func (s *DB) ClaimPendingMatch(ctx context.Context) (*int64, *string, error) {
-	var matchID *int64
-	var region *string
+// EnqueueMatches inserts discovered matches as pending work. Matches that are already
+// known keep their current status. It returns the number of newly queued matches.
+func (s *DB) EnqueueMatches(ctx context.Context, matches []riot.MatchSummary) (int64, error) {
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(matches))
+	regions := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+		regions[i] = match.Region
+	}
+
+	res, err := s.db.SQL.ExecContext(ctx, `
+		INSERT INTO lol_matches (id, region, status)
+		SELECT id, region, 'pending'
+		FROM unnest($1::bigint[], $2::text[]) AS batch(id, region)
+		ON CONFLICT DO NOTHING
+	`, pq.Array(ids), pq.Array(regions))
+	if err != nil {
+		return 0, fmt.Errorf("enqueue matches: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ClaimPendingMatch leases one pending match, or one retry match whose backoff has elapsed,
+// to workerID for the given duration. It returns nil when there is nothing to claim.
+func (s *DB) ClaimPendingMatch(ctx context.Context, workerID string, lease time.Duration) (*riot.MatchSummary, error) {
+	var match riot.MatchSummary
+	var region sql.NullString
 
 	err := s.db.SQL.QueryRowContext(ctx, `
 	WITH claimed AS (
 		SELECT id
 		FROM lol_matches
 		WHERE status = 'pending'
+		   OR (status = 'retry' AND (next_attempt_at IS NULL OR next_attempt_at <= NOW()))
+		ORDER BY next_attempt_at NULLS FIRST
 		FOR UPDATE SKIP LOCKED
 		LIMIT 1
 	)
 	UPDATE lol_matches m
 	SET status = 'processing',
+		leased_by = $1,
+		lease_expires_at = NOW() + make_interval(secs => $2),
 		updated_at = NOW()
 	FROM claimed
 	WHERE m.id = claimed.id
-	RETURNING m.id, m.region
-`).Scan(&matchID, &region)
+	RETURNING m.id, m.region, m.attempts
+`, workerID, lease.Seconds()).Scan(&match.ID, &region, &match.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("ClaimPendingMatch failed: %w", err)
+		return nil, fmt.Errorf("claim pending match: %w", err)
 	}
 
-	return matchID, region, nil
+	match.Region = region.String
+	match.Status = models.StatusProcessing
+	return &match, nil
 }
 
-func (s *DB) AckMatch(ctx context.Context, matchId int64, region string) error {
-	_, err := s.db.SQL.ExecContext(ctx, `
+// AckMatch marks a match claimed by workerID as done and releases its lease. It returns
+// riot.ErrLeaseLost when the worker no longer holds the lease.
+func (s *DB) AckMatch(ctx context.Context, matchId int64, region, workerID string) error {
+	res, err := s.db.SQL.ExecContext(ctx, `
 		UPDATE lol_matches
-		SET status = 'completed',
+		SET status = 'done',
+			attempts = 0,
+			last_error = NULL,
+			next_attempt_at = NULL,
+			leased_by = NULL,
+			lease_expires_at = NULL,
 			updated_at = NOW()
 		WHERE id = $1
 		  AND region = $2
-	`, matchId, region)
-
-	return err
+		  AND leased_by = $3
+		  AND status = 'processing'
+	`, matchId, region, workerID)
+	if err != nil {
+		return fmt.Errorf("ack match: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return riot.ErrLeaseLost
+	}
+	return nil
 }
 
-func (s *DB) NackMatch(ctx context.Context, matchId int64, region string) error {
-	_, err := s.db.SQL.ExecContext(ctx, `
+// NackMatch releases the lease workerID holds on a match whose detail fetch failed and bumps
+// its attempt counter. The next attempt is scheduled with exponential backoff
+// (baseDelay * 2^(attempts-1), capped at maxDelay), and the match moves to the DLQ once it has
+// failed maxAttempts times. It returns riot.ErrLeaseLost when the worker no longer holds the lease.
+func (s *DB) NackMatch(ctx context.Context, matchId int64, region, workerID, lastError string, maxAttempts int, baseDelay, maxDelay time.Duration) (models.Status, error) {
+	var status models.Status
+	err := s.db.SQL.QueryRowContext(ctx, `
 		UPDATE lol_matches
-		SET status = 'failed',
+		SET attempts = attempts + 1,
+			last_error = $4,
+			status = CASE WHEN attempts + 1 >= $5 THEN 'dlq' ELSE 'retry' END,
+			next_attempt_at = NOW() + make_interval(secs => LEAST($6 * power(2, attempts), $7)),
+			leased_by = NULL,
+			lease_expires_at = NULL,
 			updated_at = NOW()
 		WHERE id = $1
 		  AND region = $2
-	`, matchId, region)
+		  AND leased_by = $3
+		  AND status = 'processing'
+		RETURNING status
+	`, matchId, region, workerID, lastError, maxAttempts, baseDelay.Seconds(), maxDelay.Seconds()).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", riot.ErrLeaseLost
+	}
+	if err != nil {
+		return "", fmt.Errorf("nack match: %w", err)
+	}
+	return status, nil
+}
+
+// ReclaimExpiredLeases puts processing matches whose lease ran out back into the queue,
+// e.g. after the worker that claimed them crashed. A reclaim counts as a failed attempt, so a
+// match that keeps killing its worker moves to the DLQ after maxAttempts. It returns the
+// number of reclaimed matches.
+func (s *DB) ReclaimExpiredLeases(ctx context.Context, maxAttempts int) (int64, error) {
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET attempts = attempts + 1,
+			last_error = 'lease expired',
+			status = CASE WHEN attempts + 1 >= $1 THEN 'dlq' ELSE 'retry' END,
+			next_attempt_at = NOW(),
+			leased_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = NOW()
+		WHERE status = 'processing'
+		  AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+	`, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("reclaim expired leases: %w", err)
+	}
+	return res.RowsAffected()
+}
 
-	return err
+// RequeueMatch moves a DLQ match back to retry with a fresh attempt counter.
+// A nil matchID requeues every DLQ match. It returns the number of requeued matches.
+func (s *DB) RequeueMatch(ctx context.Context, matchID *int64) (int64, error) {
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET status = 'retry',
+			attempts = 0,
+			next_attempt_at = NOW(),
+			updated_at = NOW()
+		WHERE status = 'dlq'
+		  AND ($1::bigint IS NULL OR id = $1)
+	`, matchID)
+	if err != nil {
+		return 0, fmt.Errorf("requeue match: %w", err)
+	}
+	return res.RowsAffected()
 }
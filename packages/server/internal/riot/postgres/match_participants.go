@@ -41,6 +41,8 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 	totalDamageDealtToChampions := make([]int, len(participants))
 	totalDamageTaken := make([]int, len(participants))
 	wins := make([]bool, len(participants))
+	teamIDs := make([]int, len(participants))
+	teamPositions := make([]string, len(participants))
 
 	for i, participant := range participants {
 		matchIDs[i] = participant.GameID
@@ -71,6 +73,8 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 		totalDamageDealtToChampions[i] = participant.TotalDamageDealtToChampions
 		totalDamageTaken[i] = participant.TotalDamageTaken
 		wins[i] = participant.Win
+		teamIDs[i] = participant.TeamID
+		teamPositions[i] = participant.TeamPosition
 	}
 
 	_, err := s.db.SQL.ExecContext(ctx, `
@@ -102,7 +106,9 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 			riot_id_tagline,
 			total_damage_dealt_to_champions,
 			total_damage_taken,
-			win
+			win,
+			team_id,
+			team_position
 		)
 		SELECT *
 		FROM unnest(
@@ -133,7 +139,9 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 			$25::text[],
 			$26::integer[],
 			$27::integer[],
-			$28::boolean[]
+			$28::boolean[],
+			$29::integer[],
+			$30::text[]
 		) AS batch(
 			match_id,
 			champion_id,
@@ -162,7 +170,9 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 			riot_id_tagline,
 			total_damage_dealt_to_champions,
 			total_damage_taken,
-			win
+			win,
+			team_id,
+			team_position
 		)
 		ON CONFLICT (match_id, participant_id) DO UPDATE SET
 			champion_id = EXCLUDED.champion_id,
@@ -190,7 +200,9 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 			riot_id_tagline = EXCLUDED.riot_id_tagline,
 			total_damage_dealt_to_champions = EXCLUDED.total_damage_dealt_to_champions,
 			total_damage_taken = EXCLUDED.total_damage_taken,
-			win = EXCLUDED.win
+			win = EXCLUDED.win,
+			team_id = EXCLUDED.team_id,
+			team_position = EXCLUDED.team_position
 	`,
 		pq.Array(matchIDs),
 		pq.Array(championIDs),
@@ -220,6 +232,8 @@ func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []model
 		pq.Array(totalDamageDealtToChampions),
 		pq.Array(totalDamageTaken),
 		pq.Array(wins),
+		pq.Array(teamIDs),
+		pq.Array(teamPositions),
 	)
 	if err != nil {
 		return fmt.Errorf("save match participant batch: %w", err)
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 
+	coremodels "github.com/galchammat/kadeem/internal/models"
 	"github.com/galchammat/kadeem/internal/riot/models"
 	"github.com/lib/pq"
 )
 
+// SaveMatchSummaryBatch upserts match details. New rows take the summary status (done by
+// default); the status of queued rows is left to AckMatch/NackMatch.
 func (s *DB) SaveMatchSummaryBatch(ctx context.Context, matchSummaries []models.MatchSummary) error {
 	if len(matchSummaries) == 0 {
 		return nil
@@ -18,6 +21,8 @@ func (s *DB) SaveMatchSummaryBatch(ctx context.Context, matchSummaries []models.
 	startedAts := make([]int64, len(matchSummaries))
 	durations := make([]int, len(matchSummaries))
 	queueIDs := make([]int, len(matchSummaries))
+	patches := make([]string, len(matchSummaries))
+	statuses := make([]string, len(matchSummaries))
 
 	for i, summary := range matchSummaries {
 		ids[i] = summary.ID
@@ -25,23 +30,32 @@ func (s *DB) SaveMatchSummaryBatch(ctx context.Context, matchSummaries []models.
 		startedAts[i] = summary.StartedAt
 		durations[i] = summary.Duration
 		queueIDs[i] = summary.QueueID
+		patches[i] = summary.Patch
+		statuses[i] = string(summary.Status)
+		if statuses[i] == "" {
+			statuses[i] = string(coremodels.StatusDone)
+		}
 	}
 
 	_, err := s.db.SQL.ExecContext(ctx, `
-		INSERT INTO lol_matches (id, region, started_at, duration, queue_id)
-		SELECT *
+		INSERT INTO lol_matches (id, region, started_at, duration, queue_id, patch, status)
+		SELECT id, region, started_at, duration, queue_id, NULLIF(patch, ''), status
 		FROM unnest(
 			$1::bigint[],
 			$2::text[],
 			$3::bigint[],
 			$4::integer[],
-			$5::integer[]
-		) AS summaries(id, region, started_at, duration, queue_id)
+			$5::integer[],
+			$6::text[],
+			$7::text[]
+		) AS summaries(id, region, started_at, duration, queue_id, patch, status)
 		ON CONFLICT (id, region) DO UPDATE SET
 			started_at = EXCLUDED.started_at,
 			duration = EXCLUDED.duration,
-			queue_id = EXCLUDED.queue_id
-	`, pq.Array(ids), pq.Array(regions), pq.Array(startedAts), pq.Array(durations), pq.Array(queueIDs))
+			queue_id = EXCLUDED.queue_id,
+			patch = COALESCE(EXCLUDED.patch, lol_matches.patch),
+			updated_at = NOW()
+	`, pq.Array(ids), pq.Array(regions), pq.Array(startedAts), pq.Array(durations), pq.Array(queueIDs), pq.Array(patches), pq.Array(statuses))
 	if err != nil {
 		return fmt.Errorf("save match summary batch: %w", err)
 	}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"fmt"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/lib/pq"
+)
+
+// ListMatchBroadcasts returns every pair of a match played on one of the streamer's accounts
+// and a broadcast of the streamer that overlaps it in time.
+func (s *DB) ListMatchBroadcasts(streamerID int64) ([]riot.MatchBroadcast, error) {
+	rows, err := s.db.SQL.Query(`
+		SELECT m.id, la.puuid, la.streamer_id, m.started_at, m.duration,
+			b.id, b.url, b.created_at, b.duration
+		FROM lol_accounts la
+		INNER JOIN participants p ON p.puuid = la.puuid
+		INNER JOIN lol_matches m ON m.id = p.match_id
+		INNER JOIN channels c ON c.streamer_id = la.streamer_id
+		INNER JOIN broadcasts b ON b.channel_id = c.id
+		WHERE la.streamer_id = $1
+		  AND m.started_at > 0
+		  AND m.duration > 0
+		  AND b.duration > 0
+		  AND m.started_at / 1000 < b.created_at + b.duration
+		  AND m.started_at / 1000 + m.duration > b.created_at
+		ORDER BY m.started_at, b.created_at`, streamerID)
+	if err != nil {
+		return nil, fmt.Errorf("list match broadcasts: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []riot.MatchBroadcast
+	for rows.Next() {
+		var p riot.MatchBroadcast
+		if err := rows.Scan(
+			&p.MatchID, &p.PUUID, &p.StreamerID, &p.StartedAt, &p.Duration,
+			&p.BroadcastID, &p.BroadcastURL, &p.BroadcastCreatedAt, &p.BroadcastDuration,
+		); err != nil {
+			return nil, fmt.Errorf("scan match broadcast: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// ReplaceStreamerMatchVODs swaps the stored VOD alignments of a streamer for vods.
+func (s *DB) ReplaceStreamerMatchVODs(streamerID int64, vods []riot.MatchVOD) error {
+	tx, err := s.db.SQL.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`DELETE FROM match_vods WHERE streamer_id = $1`, streamerID); err != nil {
+		return fmt.Errorf("delete match vods of streamer %d: %w", streamerID, err)
+	}
+
+	if len(vods) > 0 {
+		matchIDs := make([]int64, len(vods))
+		broadcastIDs := make([]int64, len(vods))
+		puuids := make([]string, len(vods))
+		vodOffsets := make([]int64, len(vods))
+		matchOffsets := make([]int64, len(vods))
+		overlaps := make([]int64, len(vods))
+		urls := make([]string, len(vods))
+		for i, vod := range vods {
+			matchIDs[i] = vod.MatchID
+			broadcastIDs[i] = vod.BroadcastID
+			puuids[i] = vod.PUUID
+			vodOffsets[i] = vod.VODOffset
+			matchOffsets[i] = vod.MatchOffset
+			overlaps[i] = vod.Overlap
+			urls[i] = vod.URL
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO match_vods (match_id, broadcast_id, streamer_id, puuid, vod_offset, match_offset, overlap, url)
+			SELECT match_id, broadcast_id, $1, puuid, vod_offset, match_offset, overlap, url
+			FROM unnest(
+				$2::bigint[],
+				$3::integer[],
+				$4::text[],
+				$5::integer[],
+				$6::integer[],
+				$7::integer[],
+				$8::text[]
+			) AS batch(match_id, broadcast_id, puuid, vod_offset, match_offset, overlap, url)
+			ON CONFLICT (match_id, broadcast_id) DO UPDATE SET
+				streamer_id = EXCLUDED.streamer_id,
+				puuid = EXCLUDED.puuid,
+				vod_offset = EXCLUDED.vod_offset,
+				match_offset = EXCLUDED.match_offset,
+				overlap = EXCLUDED.overlap,
+				url = EXCLUDED.url
+		`, streamerID, pq.Array(matchIDs), pq.Array(broadcastIDs), pq.Array(puuids),
+			pq.Array(vodOffsets), pq.Array(matchOffsets), pq.Array(overlaps), pq.Array(urls))
+		if err != nil {
+			return fmt.Errorf("insert match vods of streamer %d: %w", streamerID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListAlignedKills returns the champion kills of the aligned player of every match VOD, in
+// match and time order.
+func (s *DB) ListAlignedKills() ([]riot.AlignedKill, error) {
+	rows, err := s.db.SQL.Query(`
+		SELECT v.match_id, v.broadcast_id, b.channel_id, b.url, v.vod_offset, v.match_offset, v.overlap, e.time
+		FROM match_vods v
+		INNER JOIN broadcasts b ON b.id = v.broadcast_id
+		INNER JOIN participants p ON p.match_id = v.match_id AND p.puuid = v.puuid
+		INNER JOIN match_events e ON e.match_id = v.match_id AND e.participant_id = p.participant_id
+		WHERE e.event_type = $1
+		ORDER BY v.match_id, v.broadcast_id, e.time`, riot.MatchEventChampionKill)
+	if err != nil {
+		return nil, fmt.Errorf("list aligned kills: %w", err)
+	}
+	defer rows.Close()
+
+	var kills []riot.AlignedKill
+	for rows.Next() {
+		var k riot.AlignedKill
+		if err := rows.Scan(
+			&k.MatchID, &k.BroadcastID, &k.ChannelID, &k.BroadcastURL, &k.VODOffset, &k.MatchOffset, &k.Overlap, &k.Time,
+		); err != nil {
+			return nil, fmt.Errorf("scan aligned kill: %w", err)
+		}
+		kills = append(kills, k)
+	}
+	return kills, rows.Err()
+}
+
+func (s *DB) listMatchVODs(matchIDs []int64) (map[int64][]riot.MatchVOD, error) {
+	in, args := InList(1, matchIDs)
+	rows, err := s.db.SQL.Query(`
+		SELECT v.match_id, v.broadcast_id, v.streamer_id, v.puuid, v.vod_offset, v.match_offset, v.overlap, v.url
+		FROM match_vods v
+		INNER JOIN broadcasts b ON b.id = v.broadcast_id
+		WHERE v.match_id IN `+in+`
+		ORDER BY v.match_id, b.created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list match vods: %w", err)
+	}
+	defer rows.Close()
+
+	vods := make(map[int64][]riot.MatchVOD, len(matchIDs))
+	for rows.Next() {
+		var v riot.MatchVOD
+		if err := rows.Scan(&v.MatchID, &v.BroadcastID, &v.StreamerID, &v.PUUID, &v.VODOffset, &v.MatchOffset, &v.Overlap, &v.URL); err != nil {
+			return nil, fmt.Errorf("scan match vod: %w", err)
+		}
+		vods[v.MatchID] = append(vods[v.MatchID], v)
+	}
+	return vods, rows.Err()
+}
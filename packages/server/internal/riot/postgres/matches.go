@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+const matchSummaryColumns = `m.id, COALESCE(m.region, ''), COALESCE(m.started_at, 0), COALESCE(m.duration, 0),
+	COALESCE(m.queue_id, 0), COALESCE(m.patch, ''), m.status, m.updated_at, m.replay_status, m.replay_uri, m.replay_updated_at,
+	m.attempts, m.last_error, m.next_attempt_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMatchSummary(row rowScanner, summary *riot.MatchSummary) error {
+	return row.Scan(
+		&summary.ID, &summary.Region, &summary.StartedAt, &summary.Duration,
+		&summary.QueueID, &summary.Patch, &summary.Status, &summary.UpdatedAt, &summary.ReplayStatus, &summary.ReplayURI, &summary.ReplayUpdatedAt,
+		&summary.Attempts, &summary.LastError, &summary.NextAttemptAt,
+	)
+}
+
+// ListLolMatches lists matches with their participants, newest first.
+// Participant filters (PUUID, ChampionID, Lane, Win) must all match the same participant.
+func (s *DB) ListLolMatches(filter *riot.MatchFilter, limit, offset int) ([]riot.Match, error) {
+	query := `SELECT ` + matchSummaryColumns + ` FROM lol_matches m`
+	var where []string
+	var participantWhere []string
+	var args []any
+	argN := 1
+
+	if filter != nil {
+		if filter.MatchID != nil {
+			where = append(where, fmt.Sprintf("m.id = $%d", argN))
+			args = append(args, *filter.MatchID)
+			argN++
+		}
+		if filter.Status != nil {
+			where = append(where, fmt.Sprintf("m.status = $%d", argN))
+			args = append(args, string(*filter.Status))
+			argN++
+		}
+		if filter.StartedAtMin != nil {
+			where = append(where, fmt.Sprintf("m.started_at >= $%d", argN))
+			args = append(args, *filter.StartedAtMin)
+			argN++
+		}
+		if filter.StartedAtMax != nil {
+			where = append(where, fmt.Sprintf("m.started_at <= $%d", argN))
+			args = append(args, *filter.StartedAtMax)
+			argN++
+		}
+		if filter.HasReplay != nil {
+			if *filter.HasReplay {
+				where = append(where, "m.replay_uri IS NOT NULL")
+			} else {
+				where = append(where, "m.replay_uri IS NULL")
+			}
+		}
+		if filter.HasParticipants != nil {
+			if *filter.HasParticipants {
+				where = append(where, "EXISTS (SELECT 1 FROM participants p WHERE p.match_id = m.id)")
+			} else {
+				where = append(where, "NOT EXISTS (SELECT 1 FROM participants p WHERE p.match_id = m.id)")
+			}
+		}
+		if filter.StreamerID != nil {
+			where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM match_vods v WHERE v.match_id = m.id AND v.streamer_id = $%d)", argN))
+			args = append(args, *filter.StreamerID)
+			argN++
+		}
+		if filter.PUUID != nil {
+			participantWhere = append(participantWhere, fmt.Sprintf("p.puuid = $%d", argN))
+			args = append(args, *filter.PUUID)
+			argN++
+		}
+		if filter.ChampionID != nil {
+			participantWhere = append(participantWhere, fmt.Sprintf("p.champion_id = $%d", argN))
+			args = append(args, *filter.ChampionID)
+			argN++
+		}
+		if filter.Lane != nil {
+			participantWhere = append(participantWhere, fmt.Sprintf("p.lane = $%d", argN))
+			args = append(args, *filter.Lane)
+			argN++
+		}
+		if filter.Win != nil {
+			participantWhere = append(participantWhere, fmt.Sprintf("p.win = $%d", argN))
+			args = append(args, *filter.Win)
+			argN++
+		}
+	}
+
+	if len(participantWhere) > 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM participants p WHERE p.match_id = m.id AND "+strings.Join(participantWhere, " AND ")+")")
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY m.started_at DESC NULLS LAST, m.id DESC LIMIT $%d OFFSET $%d", argN, argN+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.SQL.Query(query, args...)
+	if err != nil {
+		logging.Error("Failed to list matches from database", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []riot.Match{}
+	var matchIDs []int64
+	for rows.Next() {
+		var match riot.Match
+		if err := scanMatchSummary(rows, &match.Summary); err != nil {
+			logging.Error("Failed to scan match row", "error", err)
+			return nil, err
+		}
+		matches = append(matches, match)
+		matchIDs = append(matchIDs, match.Summary.ID)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Error("Error iterating over match rows", "error", err)
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return matches, nil
+	}
+
+	participants, err := s.listParticipants(matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	vods, err := s.listMatchVODs(matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i].Participants = participants[matches[i].Summary.ID]
+		matches[i].VODs = vods[matches[i].Summary.ID]
+	}
+	return matches, nil
+}
+
+func (s *DB) listParticipants(matchIDs []int64) (map[int64][]riot.MatchParticipantSummary, error) {
+	query := `SELECT match_id, champion_id, champ_level, kills, deaths, assists, total_minions_killed,
+		double_kills, triple_kills, quadra_kills, penta_kills,
+		item0, item1, item2, item3, item4, item5, item6,
+		summoner1_id, summoner2_id, lane, participant_id, puuid, riot_id_game_name, riot_id_tagline,
+		total_damage_dealt_to_champions, total_damage_taken, win, COALESCE(team_id, 0), COALESCE(team_position, '')
+		FROM participants WHERE match_id IN %s
+		ORDER BY match_id, participant_id`
+
+	in, args := InList(1, matchIDs)
+	rows, err := s.db.SQL.Query(fmt.Sprintf(query, in), args...)
+	if err != nil {
+		logging.Error("Failed to list participants from database", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := make(map[int64][]riot.MatchParticipantSummary, len(matchIDs))
+	for rows.Next() {
+		var p riot.MatchParticipantSummary
+		if err := rows.Scan(
+			&p.GameID, &p.ChampionID, &p.ChampLevel, &p.Kills, &p.Deaths, &p.Assists, &p.TotalMinionsKilled,
+			&p.DoubleKills, &p.TripleKills, &p.QuadraKills, &p.PentaKills,
+			&p.Item0, &p.Item1, &p.Item2, &p.Item3, &p.Item4, &p.Item5, &p.Item6,
+			&p.Summoner1ID, &p.Summoner2ID, &p.Lane, &p.ParticipantID, &p.PUUID, &p.RiotIDGameName, &p.RiotIDTagline,
+			&p.TotalDamageDealtToChampions, &p.TotalDamageTaken, &p.Win, &p.TeamID, &p.TeamPosition,
+		); err != nil {
+			logging.Error("Failed to scan participant row", "error", err)
+			return nil, err
+		}
+		participants[p.GameID] = append(participants[p.GameID], p)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Error("Error iterating over participant rows", "error", err)
+		return nil, err
+	}
+	return participants, nil
+}
+
+// allowedMatchColumns is the set of columns that can be updated via UpdateLolMatch.
+var allowedMatchColumns = map[string]bool{
+	"status":            true,
+	"duration":          true,
+	"replay_uri":        true,
+	"replay_status":     true,
+	"replay_updated_at": true,
+}
+
+func (s *DB) UpdateLolMatch(matchID int64, updates map[string]any) (bool, error) {
+	var setClauses []string
+	var args []any
+	argN := 1
+
+	for column, value := range updates {
+		if !allowedMatchColumns[column] {
+			return false, fmt.Errorf("disallowed column: %s", column)
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, argN))
+		args = append(args, value)
+		argN++
+	}
+	if len(setClauses) == 0 {
+		return false, nil
+	}
+	args = append(args, matchID)
+
+	query := `UPDATE lol_matches SET ` + strings.Join(setClauses, ", ") + fmt.Sprintf(`, updated_at = NOW() WHERE id = $%d`, argN)
+
+	res, err := s.db.SQL.Exec(query, args...)
+	if err != nil {
+		logging.Error("Failed to update match in database", "matchID", matchID, "error", err)
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return (n != 0), nil
+}
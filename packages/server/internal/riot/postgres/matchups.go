@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// matchupPosition is the position the participant alias is compared on: its team position, or
+// its lane for rows stored before team positions were.
+func matchupPosition(alias string) string {
+	return "COALESCE(NULLIF(" + alias + ".team_position, ''), " + alias + ".lane)"
+}
+
+// ListLaneMatchups pairs the account's participant in every match of at least minDuration
+// seconds with its lane opponent, the one participant of another team in the same position,
+// and sums the results per enemy champion and position, most played first. Positions without
+// exactly one opponent are skipped.
+func (s *DB) ListLaneMatchups(ctx context.Context, puuid string, minDuration int, filter *riot.MatchupFilter) ([]riot.LaneMatchupTotals, error) {
+	position := matchupPosition("p")
+	where := []string{
+		"p.puuid = $1",
+		position + " NOT IN ('', 'NONE', 'Invalid')",
+		"m.duration >= $2",
+		"(SELECT COUNT(*) FROM participants e WHERE e.match_id = p.match_id AND e.team_id <> p.team_id AND " +
+			matchupPosition("e") + " = " + position + ") = 1",
+	}
+	args := []any{puuid, minDuration}
+	argN := 3
+	minGames := 1
+
+	if filter != nil {
+		if filter.ChampionID != nil {
+			where = append(where, fmt.Sprintf("p.champion_id = $%d", argN))
+			args = append(args, *filter.ChampionID)
+			argN++
+		}
+		if filter.Lane != nil {
+			where = append(where, fmt.Sprintf("%s = $%d", position, argN))
+			args = append(args, *filter.Lane)
+			argN++
+		}
+		if filter.QueueID != nil {
+			where = append(where, fmt.Sprintf("m.queue_id = $%d", argN))
+			args = append(args, *filter.QueueID)
+			argN++
+		}
+		if filter.From != nil {
+			where = append(where, fmt.Sprintf("m.started_at >= $%d", argN))
+			args = append(args, *filter.From*1000)
+			argN++
+		}
+		if filter.To != nil {
+			where = append(where, fmt.Sprintf("m.started_at <= $%d", argN))
+			args = append(args, *filter.To*1000)
+			argN++
+		}
+		minGames = max(filter.MinGames, 1)
+	}
+	args = append(args, minGames)
+
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		SELECT o.champion_id, `+position+`, COUNT(*), SUM(CASE WHEN p.win THEN 1 ELSE 0 END),
+			SUM(p.kills - o.kills), SUM(p.deaths - o.deaths),
+			SUM(p.total_damage_dealt_to_champions - o.total_damage_dealt_to_champions),
+			SUM(p.total_minions_killed - o.total_minions_killed)
+		FROM participants p
+		INNER JOIN lol_matches m ON m.id = p.match_id
+		INNER JOIN participants o ON o.match_id = p.match_id AND o.team_id <> p.team_id
+			AND `+matchupPosition("o")+` = `+position+`
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY o.champion_id, `+position+`
+		HAVING COUNT(*) >= `+fmt.Sprintf("$%d", argN)+`
+		ORDER BY COUNT(*) DESC, o.champion_id, `+position, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list lane matchups of %s: %w", puuid, err)
+	}
+	defer rows.Close()
+
+	var matchups []riot.LaneMatchupTotals
+	for rows.Next() {
+		var m riot.LaneMatchupTotals
+		if err := rows.Scan(
+			&m.EnemyChampionID, &m.Lane, &m.Games, &m.Wins, &m.KillDiff, &m.DeathDiff, &m.DamageDiff, &m.CSDiff,
+		); err != nil {
+			return nil, fmt.Errorf("scan lane matchup: %w", err)
+		}
+		matchups = append(matchups, m)
+	}
+	return matchups, rows.Err()
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/galchammat/kadeem/internal/logging"
@@ -50,3 +51,59 @@ func (s *DB) GetRankAtTime(puuid string, queueID int, timestamp int64) (*riot.Pl
 
 	return &rank, nil
 }
+
+// ListPlayerRanks returns the account's rank snapshots of the queue between from and to
+// (unix seconds), oldest first. The last snapshot before from and the first after to are
+// included so that every game of the window is bracketed by two snapshots.
+func (s *DB) ListPlayerRanks(ctx context.Context, puuid string, queueID int, from, to int64) ([]riot.PlayerRank, error) {
+	rows, err := s.db.SQL.QueryContext(ctx, `
+        SELECT puuid, timestamp, tier, rank, league_points, wins, losses, queue_id
+        FROM player_ranks
+        WHERE puuid = $1 AND queue_id = $2
+            AND timestamp >= COALESCE((SELECT MAX(timestamp) FROM player_ranks WHERE puuid = $1 AND queue_id = $2 AND timestamp <= $3), $3)
+            AND timestamp <= COALESCE((SELECT MIN(timestamp) FROM player_ranks WHERE puuid = $1 AND queue_id = $2 AND timestamp >= $4), $4)
+        ORDER BY timestamp`, puuid, queueID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list ranks of %s: %w", puuid, err)
+	}
+	defer rows.Close()
+
+	var ranks []riot.PlayerRank
+	for rows.Next() {
+		var rank riot.PlayerRank
+		if err := rows.Scan(
+			&rank.PUUID, &rank.Timestamp, &rank.Tier, &rank.Rank,
+			&rank.LeaguePoints, &rank.Wins, &rank.Losses, &rank.QueueID,
+		); err != nil {
+			return nil, fmt.Errorf("scan rank: %w", err)
+		}
+		ranks = append(ranks, rank)
+	}
+	return ranks, rows.Err()
+}
+
+// ListRankedGames returns the account's games of the queue that lasted at least minDuration
+// seconds and started between from and to (unix seconds), oldest first.
+func (s *DB) ListRankedGames(ctx context.Context, puuid string, queueID, minDuration int, from, to int64) ([]riot.RankedGame, error) {
+	rows, err := s.db.SQL.QueryContext(ctx, `
+        SELECT m.id, m.queue_id, m.started_at, m.duration, p.champion_id, p.win
+        FROM participants p
+        INNER JOIN lol_matches m ON m.id = p.match_id
+        WHERE p.puuid = $1 AND m.queue_id = $2 AND m.duration >= $3
+            AND m.started_at >= $4 AND m.started_at <= $5
+        ORDER BY m.started_at, m.id`, puuid, queueID, minDuration, from*1000, to*1000)
+	if err != nil {
+		return nil, fmt.Errorf("list ranked games of %s: %w", puuid, err)
+	}
+	defer rows.Close()
+
+	var games []riot.RankedGame
+	for rows.Next() {
+		var g riot.RankedGame
+		if err := rows.Scan(&g.MatchID, &g.QueueID, &g.StartedAt, &g.Duration, &g.ChampionID, &g.Win); err != nil {
+			return nil, fmt.Errorf("scan ranked game: %w", err)
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
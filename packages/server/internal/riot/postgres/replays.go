@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/lib/pq"
+)
+
+// ListStoredReplays returns every match with a stored replay, oldest first. Matches without
+// a start time fall back to when their row was last updated.
+func (s *DB) ListStoredReplays(ctx context.Context) ([]riot.StoredReplay, error) {
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		SELECT m.id, m.replay_uri,
+			COALESCE(NULLIF(m.started_at, 0), (EXTRACT(EPOCH FROM m.updated_at) * 1000)::bigint),
+			EXISTS (SELECT 1 FROM match_vods v WHERE v.match_id = m.id),
+			EXISTS (SELECT 1 FROM participants p WHERE p.match_id = m.id AND p.penta_kills > 0)
+		FROM lol_matches m
+		WHERE m.replay_uri IS NOT NULL
+		ORDER BY 3, m.id`)
+	if err != nil {
+		return nil, fmt.Errorf("list stored replays: %w", err)
+	}
+	defer rows.Close()
+
+	var replays []riot.StoredReplay
+	for rows.Next() {
+		var r riot.StoredReplay
+		if err := rows.Scan(&r.MatchID, &r.ReplayURI, &r.StartedAt, &r.Aligned, &r.Pentakill); err != nil {
+			return nil, fmt.Errorf("scan stored replay: %w", err)
+		}
+		replays = append(replays, r)
+	}
+	return replays, rows.Err()
+}
+
+// ExpireMatchReplays marks the replays of matchIDs as expired and forgets their URIs.
+func (s *DB) ExpireMatchReplays(ctx context.Context, matchIDs []int64) (int64, error) {
+	n, err := s.forgetMatchReplays(ctx, matchIDs, "expired")
+	if err != nil {
+		return 0, fmt.Errorf("expire match replays: %w", err)
+	}
+	return n, nil
+}
+
+// ResetMatchReplays forgets the URIs of the replays of matchIDs and marks them pending, so
+// the next sync downloads them again.
+func (s *DB) ResetMatchReplays(ctx context.Context, matchIDs []int64) (int64, error) {
+	n, err := s.forgetMatchReplays(ctx, matchIDs, "pending")
+	if err != nil {
+		return 0, fmt.Errorf("reset match replays: %w", err)
+	}
+	return n, nil
+}
+
+func (s *DB) forgetMatchReplays(ctx context.Context, matchIDs []int64, status string) (int64, error) {
+	if len(matchIDs) == 0 {
+		return 0, nil
+	}
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET replay_status = $1,
+			replay_uri = NULL,
+			replay_updated_at = NOW(),
+			updated_at = NOW()
+		WHERE id = ANY($2)`, status, pq.Array(matchIDs))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// ListPlayedGames returns the games of the accounts that lasted at least minDuration seconds
+// and started between from and to (unix seconds), oldest first.
+func (s *DB) ListPlayedGames(ctx context.Context, puuids []string, minDuration int, from, to int64) ([]riot.PlayedGame, error) {
+	if len(puuids) == 0 {
+		return nil, nil
+	}
+	args := []any{minDuration, from * 1000, to * 1000}
+	placeholders := make([]string, len(puuids))
+	for i, puuid := range puuids {
+		placeholders[i] = fmt.Sprintf("$%d", len(args)+1)
+		args = append(args, puuid)
+	}
+
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		SELECT m.id, p.puuid, COALESCE(m.queue_id, 0), m.started_at, m.duration, p.champion_id, p.win,
+			p.kills, p.deaths, p.assists
+		FROM participants p
+		INNER JOIN lol_matches m ON m.id = p.match_id
+		WHERE m.duration >= $1 AND m.started_at >= $2 AND m.started_at <= $3
+			AND p.puuid IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY m.started_at, m.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list played games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []riot.PlayedGame
+	for rows.Next() {
+		var g riot.PlayedGame
+		if err := rows.Scan(
+			&g.MatchID, &g.PUUID, &g.QueueID, &g.StartedAt, &g.Duration, &g.ChampionID, &g.Win,
+			&g.Kills, &g.Deaths, &g.Assists,
+		); err != nil {
+			return nil, fmt.Errorf("scan played game: %w", err)
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
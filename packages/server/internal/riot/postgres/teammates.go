@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// ListTeammates returns the players who were on the account's team in at least minGames of
+// its games of at least minDuration seconds, most frequent first.
+func (s *DB) ListTeammates(ctx context.Context, puuid string, minDuration, minGames, limit int) ([]riot.TeammateTotals, error) {
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		WITH shared AS (
+			SELECT t.puuid, t.riot_id_game_name AS game_name, t.riot_id_tagline AS tag_line,
+				COALESCE(m.region, '') AS region, COALESCE(m.started_at, 0) AS started_at, p.win,
+				ROW_NUMBER() OVER (PARTITION BY t.puuid ORDER BY COALESCE(m.started_at, 0) DESC, m.id DESC) AS n
+			FROM participants p
+			INNER JOIN lol_matches m ON m.id = p.match_id
+			INNER JOIN participants t ON t.match_id = p.match_id AND t.puuid <> p.puuid AND t.team_id = p.team_id
+			WHERE p.puuid = $1 AND m.duration >= $2
+		)
+		SELECT s.puuid, s.game_name, s.tag_line, s.region, c.games, c.wins, s.started_at, a.streamer_id
+		FROM shared s
+		INNER JOIN (
+			SELECT puuid, COUNT(*) AS games, SUM(CASE WHEN win THEN 1 ELSE 0 END) AS wins
+			FROM shared
+			GROUP BY puuid
+			HAVING COUNT(*) >= $3
+		) c ON c.puuid = s.puuid
+		LEFT JOIN lol_accounts a ON a.puuid = s.puuid
+		WHERE s.n = 1
+		ORDER BY c.games DESC, s.started_at DESC, s.puuid
+		LIMIT $4`, puuid, minDuration, minGames, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list teammates of %s: %w", puuid, err)
+	}
+	defer rows.Close()
+
+	var teammates []riot.TeammateTotals
+	for rows.Next() {
+		var t riot.TeammateTotals
+		if err := rows.Scan(
+			&t.PUUID, &t.GameName, &t.TagLine, &t.Region, &t.Games, &t.Wins, &t.LastSeen, &t.StreamerID,
+		); err != nil {
+			return nil, fmt.Errorf("scan teammate: %w", err)
+		}
+		teammates = append(teammates, t)
+	}
+	return teammates, rows.Err()
+}
+
+// ListStreamerMeetings returns the games of at least minDuration seconds in which one of the
+// streamer's accounts played with or against an account of another streamer, newest first.
+func (s *DB) ListStreamerMeetings(ctx context.Context, streamerID, minDuration, limit, offset int) ([]riot.StreamerMeeting, error) {
+	rows, err := s.db.SQL.QueryContext(ctx, `
+		SELECT m.id, COALESCE(m.started_at, 0), COALESCE(m.queue_id, 0), a.puuid,
+			lb.streamer_id, st.name, b.puuid, COALESCE(a.team_id = b.team_id, FALSE)
+		FROM participants a
+		INNER JOIN lol_accounts la ON la.puuid = a.puuid
+		INNER JOIN participants b ON b.match_id = a.match_id AND b.puuid <> a.puuid
+		INNER JOIN lol_accounts lb ON lb.puuid = b.puuid AND lb.streamer_id <> la.streamer_id
+		INNER JOIN streamers st ON st.id = lb.streamer_id
+		INNER JOIN lol_matches m ON m.id = a.match_id
+		WHERE la.streamer_id = $1 AND m.duration >= $2
+		ORDER BY COALESCE(m.started_at, 0) DESC, m.id DESC, b.puuid
+		LIMIT $3 OFFSET $4`, streamerID, minDuration, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list meetings of streamer %d: %w", streamerID, err)
+	}
+	defer rows.Close()
+
+	var meetings []riot.StreamerMeeting
+	for rows.Next() {
+		var m riot.StreamerMeeting
+		if err := rows.Scan(
+			&m.MatchID, &m.StartedAt, &m.QueueID, &m.PUUID, &m.OtherStreamerID, &m.OtherStreamerName, &m.OtherPUUID, &m.SameTeam,
+		); err != nil {
+			return nil, fmt.Errorf("scan streamer meeting: %w", err)
+		}
+		meetings = append(meetings, m)
+	}
+	return meetings, rows.Err()
+}
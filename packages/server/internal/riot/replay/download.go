@@ -0,0 +1,100 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StagingDir is where partial downloads are kept until they are verified and stored:
+// $BIN_DIR/replays-partial, or bin/replays-partial.
+func StagingDir() string {
+	if binDir := os.Getenv("BIN_DIR"); binDir != "" {
+		return filepath.Join(binDir, "replays-partial")
+	}
+	return filepath.Join("bin", "replays-partial")
+}
+
+// Download fetches url into partialPath. Bytes left there by an interrupted attempt are kept
+// and only the rest is requested with an HTTP Range header. If the server ignores the range
+// the file is rewritten from the start.
+func Download(ctx context.Context, client *http.Client, url, partialPath string) error {
+	if err := os.MkdirAll(filepath.Dir(partialPath), 0o755); err != nil {
+		return fmt.Errorf("create staging directory: %w", err)
+	}
+
+	f, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open partial replay: %w", err)
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek partial replay: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request replay: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, err := contentRangeStart(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return fmt.Errorf("unexpected content range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+	case http.StatusOK:
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("seek partial replay: %w", err)
+		}
+		if err := f.Truncate(0); err != nil {
+			return fmt.Errorf("truncate partial replay: %w", err)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The previous attempt already got every byte.
+		if offset > 0 {
+			return nil
+		}
+		return fmt.Errorf("replay download failed with status code %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("replay download failed with status code %d", resp.StatusCode)
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return fmt.Errorf("write partial replay: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync partial replay: %w", err)
+	}
+	return nil
+}
+
+// contentRangeStart parses the first byte position of a "bytes 100-199/200" header.
+func contentRangeStart(header string) (int64, error) {
+	rest, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, errors.New("missing bytes unit")
+	}
+	start, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, errors.New("missing range")
+	}
+	return strconv.ParseInt(start, 10, 64)
+}
@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadResumesWithRange(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "1.rofl", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	partial := filepath.Join(t.TempDir(), "1.rofl.part")
+	require.NoError(t, os.WriteFile(partial, content[:4321], 0o644))
+
+	require.NoError(t, Download(context.Background(), server.Client(), server.URL, partial))
+
+	got, err := os.ReadFile(partial)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	assert.Equal(t, []string{"bytes=4321-"}, ranges)
+
+	// Everything is there already; the server answers 416 and the file is left alone.
+	require.NoError(t, Download(context.Background(), server.Client(), server.URL, partial))
+	got, err = os.ReadFile(partial)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+func TestDownloadRestartsWhenRangeIsIgnored(t *testing.T) {
+	content := []byte("fresh replay bytes")
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	partial := filepath.Join(t.TempDir(), "nested", "2.rofl.part")
+	require.NoError(t, os.MkdirAll(filepath.Dir(partial), 0o755))
+	require.NoError(t, os.WriteFile(partial, []byte("stale garbage that is longer"), 0o644))
+
+	require.NoError(t, Download(context.Background(), server.Client(), server.URL, partial))
+
+	got, err := os.ReadFile(partial)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDownloadFailsOnServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := Download(context.Background(), server.Client(), server.URL, filepath.Join(t.TempDir(), "3.rofl.part"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Object is a stored replay opened for random access, for example by http.ServeContent.
+// Each Seek that moves the read position starts a new ranged read from the store.
+type Object struct {
+	Info ObjectInfo
+
+	ctx    context.Context
+	store  ReplayStore
+	offset int64
+	body   io.ReadCloser
+}
+
+// Open stats key and returns it as a seekable object. Nothing is read until Read is called.
+func Open(ctx context.Context, store ReplayStore, key string) (*Object, error) {
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Info: info, ctx: ctx, store: store}, nil
+}
+
+func (o *Object) Read(p []byte) (int, error) {
+	if o.offset >= o.Info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		body, err := o.store.GetRange(o.ctx, o.Info.Key, o.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		o.body = body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *Object) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = o.offset + offset
+	case io.SeekEnd:
+		next = o.Info.Size + offset
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if next < 0 {
+		return 0, errors.New("seek before start of replay")
+	}
+	if next != o.offset {
+		o.closeBody()
+		o.offset = next
+	}
+	return next, nil
+}
+
+func (o *Object) Close() error {
+	return o.closeBody()
+}
+
+func (o *Object) closeBody() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
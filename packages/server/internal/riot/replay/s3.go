@@ -0,0 +1,293 @@
+package replay
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const s3Scheme = "s3://"
+
+// S3Config configures an S3-compatible store (AWS S3, MinIO, R2, ...).
+type S3Config struct {
+	// Endpoint is the base URL of the service, e.g. https://s3.eu-west-1.amazonaws.com
+	// or http://localhost:9000. Requests use path-style addressing.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+
+	HTTPClient *http.Client
+}
+
+// S3Store keeps replays in a bucket of an S3-compatible object store.
+type S3Store struct {
+	endpoint *url.URL
+	cfg      S3Config
+	http     *http.Client
+	now      func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 replay store requires an endpoint and a bucket")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parse s3 endpoint: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+	return &S3Store{endpoint: endpoint, cfg: cfg, http: client, now: time.Now}, nil
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return s.endpoint.String() + "/" + s.cfg.Bucket + "/" + s.cfg.Prefix + key
+}
+
+func (s *S3Store) do(ctx context.Context, method, rawURL string, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	return s.send(req, payloadHash)
+}
+
+// send signs and sends req.
+func (s *S3Store) send(req *http.Request, payloadHash string) (*http.Response, error) {
+	signV4(req, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, payloadHash, s.now())
+	return s.http.Do(req)
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if size < 0 {
+		// S3 needs the content length up front.
+		spooled, n, err := spool(r)
+		if err != nil {
+			return fmt.Errorf("put replay %s: %w", key, err)
+		}
+		defer spooled.Close()
+		r, size = spooled, n
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(key), r, size, unsignedPayload)
+	if err != nil {
+		return fmt.Errorf("put replay %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("put replay %s: %w", key, s3Error(resp))
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key), nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, fmt.Errorf("get replay %s: %w", key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("get replay %s: %w", key, s3Error(resp))
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	resp, err := s.send(req, emptyPayloadHash)
+	if err != nil {
+		return nil, fmt.Errorf("get replay %s: %w", key, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("get replay %s: %w", key, s3Error(resp))
+	}
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	if err := validateKey(key); err != nil {
+		return ObjectInfo{}, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(key), nil, 0, emptyPayloadHash)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("stat replay %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ObjectInfo{}, fmt.Errorf("stat replay %s: status %d", key, resp.StatusCode)
+	}
+
+	info := ObjectInfo{Key: key, Size: resp.ContentLength}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(key), nil, 0, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("delete replay %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete replay %s: %w", key, s3Error(resp))
+	}
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.cfg.Prefix+prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, s.endpoint.String()+"/"+s.cfg.Bucket+"?"+query.Encode(), nil, 0, emptyPayloadHash)
+		if err != nil {
+			return nil, fmt.Errorf("list replays: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("list replays: %w", err)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode replay listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:     strings.TrimPrefix(object.Key, s.cfg.Prefix),
+				Size:    object.Size,
+				ModTime: object.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Store) URI(key string) string {
+	return s3Scheme + s.cfg.Bucket + "/" + s.cfg.Prefix + key
+}
+
+func (s *S3Store) Key(uri string) (string, bool) {
+	key, ok := strings.CutPrefix(uri, s3Scheme+s.cfg.Bucket+"/"+s.cfg.Prefix)
+	return key, ok && validateKey(key) == nil
+}
+
+// s3Error turns an error response into an error with the S3 error code when there is one.
+func s3Error(resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := xml.Unmarshal(raw, &body); err == nil && body.Code != "" {
+		return fmt.Errorf("status %d: %s: %s", resp.StatusCode, body.Code, body.Message)
+	}
+	return fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// spool copies r to a temporary file that is removed when closed.
+func spool(r io.Reader) (*spooledFile, int64, error) {
+	f, err := os.CreateTemp("", "replay-*.tmp")
+	if err != nil {
+		return nil, 0, err
+	}
+	spooled := &spooledFile{f}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spooled.Close()
+		return nil, 0, err
+	}
+	return spooled, n, nil
+}
+
+type spooledFile struct {
+	*os.File
+}
+
+func (f *spooledFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name()) //nolint:errcheck
+	return err
+}
@@ -0,0 +1,85 @@
+// Package replay stores downloaded ROFL replay files.
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a replay does not exist in the store.
+var ErrNotFound = errors.New("replay not found")
+
+// ObjectInfo describes a stored replay.
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// ReplayStore is a backend for replay files. Keys are slash separated relative names
+// such as "7665669531.rofl".
+type ReplayStore interface {
+	// Put stores r under key. size is the length of r, or -1 if unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange reads length bytes starting at offset, or everything after offset if length is -1.
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// URI returns the backend-qualified URI saved in lol_matches.replay_uri.
+	URI(key string) string
+	// Key returns the key of a URI produced by this store.
+	Key(uri string) (string, bool)
+}
+
+// MatchKey is the key of the replay of a match.
+func MatchKey(matchID int64) string {
+	return fmt.Sprintf("%d.rofl", matchID)
+}
+
+// NewFromEnv builds the store selected by REPLAY_STORE ("local" by default, or "s3").
+func NewFromEnv() (ReplayStore, error) {
+	switch backend := os.Getenv("REPLAY_STORE"); backend {
+	case "", "local":
+		return NewLocalStore(DefaultDir()), nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  os.Getenv("REPLAY_S3_ENDPOINT"),
+			Region:    os.Getenv("REPLAY_S3_REGION"),
+			Bucket:    os.Getenv("REPLAY_S3_BUCKET"),
+			Prefix:    os.Getenv("REPLAY_S3_PREFIX"),
+			AccessKey: os.Getenv("REPLAY_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("REPLAY_S3_SECRET_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown replay store %q", backend)
+	}
+}
+
+// DefaultDir is where the local store keeps replays: $BIN_DIR/replays, or bin/replays.
+func DefaultDir() string {
+	if binDir := os.Getenv("BIN_DIR"); binDir != "" {
+		return filepath.Join(binDir, "replays")
+	}
+	return filepath.Join("bin", "replays")
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid replay key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid replay key %q", key)
+		}
+	}
+	return nil
+}
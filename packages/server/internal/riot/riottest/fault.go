@@ -0,0 +1,103 @@
+package riottest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault describes a failure injected into requests, see Server.Inject.
+type Fault struct {
+	// Status, when non-zero, is returned instead of the real response.
+	Status int
+	// RetryAfter is sent as the Retry-After header of a 429 response.
+	RetryAfter time.Duration
+	// LimitType is sent as X-Rate-Limit-Type with a 429 response ("method" when empty).
+	LimitType string
+	// Delay is waited before responding. A Delay without Status slows down the real response.
+	Delay time.Duration
+}
+
+// NotFound answers with 404.
+func NotFound() Fault {
+	return Fault{Status: http.StatusNotFound}
+}
+
+// RateLimited answers with 429 and a Retry-After of retryAfter.
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// ServerError answers with status, which should be a 5xx code.
+func ServerError(status int) Fault {
+	return Fault{Status: status}
+}
+
+// Slow delays the real response by delay.
+func Slow(delay time.Duration) Fault {
+	return Fault{Delay: delay}
+}
+
+type fault struct {
+	Fault
+	prefix    string
+	remaining int // 0 means unlimited
+}
+
+// Inject makes the next times requests whose path starts with prefix fail with f. A times
+// of 0 keeps the fault until Reset. Faults are matched in the order they were injected.
+func (s *Server) Inject(prefix string, f Fault, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{Fault: f, prefix: prefix, remaining: times})
+}
+
+// Reset removes every injected fault.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns the fault for path and uses it up. s.mu must be held.
+func (s *Server) takeFault(path string) *fault {
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.prefix) {
+			continue
+		}
+		if f.remaining > 0 {
+			f.remaining--
+			if f.remaining == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// apply writes the fault and reports whether the real response should still be served.
+func (f *fault) apply(w http.ResponseWriter, r *http.Request) bool {
+	if f.Delay > 0 {
+		select {
+		case <-time.After(f.Delay):
+		case <-r.Context().Done():
+			return false
+		}
+	}
+	if f.Status == 0 {
+		return true
+	}
+
+	if f.Status == http.StatusTooManyRequests {
+		limitType := f.LimitType
+		if limitType == "" {
+			limitType = "method"
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+		w.Header().Set("X-Rate-Limit-Type", limitType)
+	}
+	writeStatus(w, f.Status, http.StatusText(f.Status))
+	return false
+}
@@ -0,0 +1,59 @@
+package riottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// replayMatchID finds the full match ID in a replay download URL, e.g. EUW1_7665669531.replay.
+var replayMatchID = regexp.MustCompile(`/([A-Z0-9]+_\d+)\.replay`)
+
+// LoadFixtures registers every .json file in dir by its shape: match-v5 match details and
+// timelines, and replay responses ({"matchFileURLs": [...]}) whose URLs name their match.
+func (s *Server) LoadFixtures(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := s.loadFixture(raw); err != nil {
+			return fmt.Errorf("load fixture %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) loadFixture(raw []byte) error {
+	var shape struct {
+		Info *struct {
+			Frames       json.RawMessage `json:"frames"`
+			Participants json.RawMessage `json:"participants"`
+		} `json:"info"`
+		MatchFileURLs []string `json:"matchFileURLs"`
+	}
+	if err := json.Unmarshal(raw, &shape); err != nil {
+		return err
+	}
+
+	switch {
+	case shape.Info != nil && shape.Info.Frames != nil:
+		return s.AddTimeline(raw)
+	case shape.Info != nil && shape.Info.Participants != nil:
+		return s.AddMatch(raw)
+	case shape.MatchFileURLs != nil:
+		for _, url := range shape.MatchFileURLs {
+			if m := replayMatchID.FindStringSubmatch(url); m != nil {
+				s.AddReplay(m[1], url)
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Package riottest provides a fake Riot API for tests and local development. It serves
+// account-v1, match-v5, summoner-v4 and league-v4 from fixtures and can inject faults.
+package riottest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// appRateLimit is reported on every response so clients do not throttle themselves
+// with the development key limits.
+const appRateLimit = "10000:1"
+
+// Server is a fake Riot API. NewHandler returns one that is not listening yet, to be
+// served by the caller; NewServer starts it on a local port.
+type Server struct {
+	// URL is the base URL of a Server started by NewServer, without a trailing slash.
+	URL string
+
+	ts  *httptest.Server
+	mux *http.ServeMux
+
+	mu        sync.Mutex
+	accounts  map[string]models.Account  // by PUUID
+	matches   map[string]storedMatch     // by full match ID, e.g. EUW1_123
+	timelines map[string]json.RawMessage // by full match ID
+	replays   map[string][]string        // replay URLs by full match ID
+	summoners map[string]string          // summoner ID by PUUID
+	entries   map[string][]api.RankEntry // by summoner ID
+	faults    []*fault
+	hits      map[string]int // requests by path
+}
+
+type storedMatch struct {
+	raw       json.RawMessage
+	startedAt int64 // unix ms
+	queueID   int
+	puuids    []string
+}
+
+// NewHandler returns a fake Riot API without fixtures.
+func NewHandler() *Server {
+	s := &Server{
+		mux:       http.NewServeMux(),
+		accounts:  make(map[string]models.Account),
+		matches:   make(map[string]storedMatch),
+		timelines: make(map[string]json.RawMessage),
+		replays:   make(map[string][]string),
+		summoners: make(map[string]string),
+		entries:   make(map[string][]api.RankEntry),
+		hits:      make(map[string]int),
+	}
+	s.mux.HandleFunc("GET /riot/account/v1/accounts/by-riot-id/{gameName}/{tagLine}", s.accountByRiotID)
+	s.mux.HandleFunc("GET /riot/account/v1/accounts/by-puuid/{puuid}", s.accountByPUUID)
+	s.mux.HandleFunc("GET /lol/match/v5/matches/by-puuid/{puuid}/ids", s.matchIDs)
+	s.mux.HandleFunc("GET /lol/match/v5/matches/by-puuid/{puuid}/replays", s.matchReplays)
+	s.mux.HandleFunc("GET /lol/match/v5/matches/{matchID}", s.match)
+	s.mux.HandleFunc("GET /lol/match/v5/matches/{matchID}/timeline", s.timeline)
+	s.mux.HandleFunc("GET /lol/summoner/v4/summoners/by-puuid/{puuid}", s.summonerByPUUID)
+	s.mux.HandleFunc("GET /lol/league/v4/entries/by-summoner/{summonerID}", s.entriesBySummoner)
+	s.mux.HandleFunc("GET /lol/league/v4/entries/by-puuid/{puuid}", s.entriesByPUUID)
+	return s
+}
+
+// NewServer starts a fake Riot API on a local port. The caller must Close it.
+func NewServer() *Server {
+	s := NewHandler()
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+	return s
+}
+
+// Close shuts down a server started by NewServer.
+func (s *Server) Close() {
+	if s.ts != nil {
+		s.ts.Close()
+	}
+}
+
+// Client returns a Riot API client that talks to s.
+func (s *Server) Client(opts ...api.ClientOption) *api.Client {
+	return api.NewClient(append([]api.ClientOption{api.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// Hits returns how many requests were made to paths starting with prefix.
+func (s *Server) Hits(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for path, count := range s.hits {
+		if strings.HasPrefix(path, prefix) {
+			n += count
+		}
+	}
+	return n
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+	f := s.takeFault(r.URL.Path)
+	s.mu.Unlock()
+
+	w.Header().Set("X-App-Rate-Limit", appRateLimit)
+	w.Header().Set("X-App-Rate-Limit-Count", "1:1")
+	if f != nil && !f.apply(w, r) {
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// AddAccount registers an account for account-v1 and gives it a summoner.
+func (s *Server) AddAccount(account models.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addAccount(account)
+}
+
+func (s *Server) addAccount(account models.Account) {
+	s.accounts[account.PUUID] = account
+	if _, ok := s.summoners[account.PUUID]; !ok {
+		s.summoners[account.PUUID] = "summoner-" + account.PUUID
+	}
+}
+
+// AddMatch registers a match-v5 match detail response. Its participants become known
+// accounts, and the match is listed in their match IDs.
+func (s *Server) AddMatch(raw []byte) error {
+	var detail struct {
+		Metadata struct {
+			MatchID string `json:"matchId"`
+		} `json:"metadata"`
+		Info struct {
+			GameStartTimestamp int64 `json:"gameStartTimestamp"`
+			QueueID            int   `json:"queueId"`
+			Participants       []struct {
+				PUUID          string `json:"puuid"`
+				RiotIDGameName string `json:"riotIdGameName"`
+				RiotIDTagline  string `json:"riotIdTagline"`
+			} `json:"participants"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(raw, &detail); err != nil {
+		return fmt.Errorf("decode match: %w", err)
+	}
+	if detail.Metadata.MatchID == "" {
+		return fmt.Errorf("match has no metadata.matchId")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	match := storedMatch{raw: raw, startedAt: detail.Info.GameStartTimestamp, queueID: detail.Info.QueueID}
+	for _, p := range detail.Info.Participants {
+		match.puuids = append(match.puuids, p.PUUID)
+		if _, ok := s.accounts[p.PUUID]; !ok {
+			s.addAccount(models.Account{PUUID: p.PUUID, GameName: p.RiotIDGameName, TagLine: p.RiotIDTagline})
+		}
+	}
+	s.matches[detail.Metadata.MatchID] = match
+	return nil
+}
+
+// AddTimeline registers a match-v5 timeline response.
+func (s *Server) AddTimeline(raw []byte) error {
+	var timeline struct {
+		Metadata struct {
+			MatchID string `json:"matchId"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(raw, &timeline); err != nil {
+		return fmt.Errorf("decode timeline: %w", err)
+	}
+	if timeline.Metadata.MatchID == "" {
+		return fmt.Errorf("timeline has no metadata.matchId")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timelines[timeline.Metadata.MatchID] = raw
+	return nil
+}
+
+// AddReplay registers the replay download URL of a match. It is listed in the replays
+// of every participant of the match.
+func (s *Server) AddReplay(fullMatchID, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replays[fullMatchID] = append(s.replays[fullMatchID], url)
+}
+
+// SetRankEntries sets the league-v4 entries of an account.
+func (s *Server) SetRankEntries(puuid string, entries []api.RankEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summonerID, ok := s.summoners[puuid]
+	if !ok {
+		summonerID = "summoner-" + puuid
+		s.summoners[puuid] = summonerID
+	}
+	s.entries[summonerID] = entries
+}
+
+func (s *Server) accountByRiotID(w http.ResponseWriter, r *http.Request) {
+	gameName, tagLine := r.PathValue("gameName"), r.PathValue("tagLine")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, account := range s.accounts {
+		if strings.EqualFold(account.GameName, gameName) && strings.EqualFold(account.TagLine, tagLine) {
+			writeJSON(w, accountResponse(account))
+			return
+		}
+	}
+	writeStatus(w, http.StatusNotFound, "No results found for player with riot id "+gameName+"#"+tagLine)
+}
+
+func (s *Server) accountByPUUID(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[r.PathValue("puuid")]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Data not found - No results found for player with puuid")
+		return
+	}
+	writeJSON(w, accountResponse(account))
+}
+
+func accountResponse(account models.Account) map[string]string {
+	return map[string]string{"puuid": account.PUUID, "gameName": account.GameName, "tagLine": account.TagLine}
+}
+
+// matchIDs lists the matches of a player newest first, honouring the startTime, endTime
+// (unix seconds), queue, start and count parameters.
+func (s *Server) matchIDs(w http.ResponseWriter, r *http.Request) {
+	puuid := r.PathValue("puuid")
+	query := r.URL.Query()
+	startTime, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+	endTime, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+	queue, _ := strconv.Atoi(query.Get("queue"))
+	start, _ := strconv.Atoi(query.Get("start"))
+	count := 20
+	if raw := query.Get("count"); raw != "" {
+		count, _ = strconv.Atoi(raw)
+	}
+	if count < 0 || count > 100 || start < 0 {
+		writeStatus(w, http.StatusBadRequest, "Bad request - invalid start or count")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type listed struct {
+		id        string
+		startedAt int64
+	}
+	var found []listed
+	for id, match := range s.matches {
+		if !slices.Contains(match.puuids, puuid) {
+			continue
+		}
+		if startTime > 0 && match.startedAt/1000 < startTime {
+			continue
+		}
+		if endTime > 0 && match.startedAt/1000 > endTime {
+			continue
+		}
+		if queue > 0 && match.queueID != queue {
+			continue
+		}
+		found = append(found, listed{id: id, startedAt: match.startedAt})
+	}
+	slices.SortFunc(found, func(a, b listed) int {
+		return cmp.Or(cmp.Compare(b.startedAt, a.startedAt), strings.Compare(b.id, a.id))
+	})
+
+	ids := []string{}
+	for i := start; i < len(found) && len(ids) < count; i++ {
+		ids = append(ids, found[i].id)
+	}
+	writeJSON(w, ids)
+}
+
+func (s *Server) matchReplays(w http.ResponseWriter, r *http.Request) {
+	puuid := r.PathValue("puuid")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls := []string{}
+	for id, match := range s.matches {
+		if slices.Contains(match.puuids, puuid) {
+			urls = append(urls, s.replays[id]...)
+		}
+	}
+	slices.Sort(urls)
+	writeJSON(w, map[string]any{"total": len(urls), "matchFileURLs": urls})
+}
+
+func (s *Server) match(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	match, ok := s.matches[r.PathValue("matchID")]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Data not found - match file not found")
+		return
+	}
+	writeRaw(w, match.raw)
+}
+
+func (s *Server) timeline(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.timelines[r.PathValue("matchID")]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Data not found - match file not found")
+		return
+	}
+	writeRaw(w, raw)
+}
+
+func (s *Server) summonerByPUUID(w http.ResponseWriter, r *http.Request) {
+	puuid := r.PathValue("puuid")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summonerID, ok := s.summoners[puuid]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Data not found - summoner not found")
+		return
+	}
+	writeJSON(w, map[string]any{"id": summonerID, "puuid": puuid, "profileIconId": 1, "summonerLevel": 30})
+}
+
+func (s *Server) entriesBySummoner(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, s.rankEntries(r.PathValue("summonerID")))
+}
+
+func (s *Server) entriesByPUUID(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, s.rankEntries(s.summoners[r.PathValue("puuid")]))
+}
+
+func (s *Server) rankEntries(summonerID string) []api.RankEntry {
+	if entries := s.entries[summonerID]; entries != nil {
+		return entries
+	}
+	return []api.RankEntry{}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeRaw(w, raw)
+}
+
+func writeRaw(w http.ResponseWriter, raw []byte) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	_, _ = w.Write(raw)
+}
+
+// writeStatus writes an error in the shape the Riot API uses.
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	raw, _ := json.Marshal(map[string]any{"status": map[string]any{"status_code": code, "message": message}})
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(raw)
+}
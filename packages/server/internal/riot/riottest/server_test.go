@@ -0,0 +1,120 @@
+package riottest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureDir   = "../../../tests/data/riot/raw"
+	fixturePUUID = "pACB3ilSQ1H4ztRE9PxDphyKcN24opipyIUUlmJj5JBCA36CyxN0ghw84EMMMxfuVlk0euBqRK6Bkw"
+)
+
+func newFixtureServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	require.NoError(t, s.LoadFixtures(fixtureDir))
+	return s
+}
+
+func TestServesFixtures(t *testing.T) {
+	s := newFixtureServer(t)
+	client := s.Client()
+	ctx := context.Background()
+
+	account, err := client.FetchAccountByPUUID(ctx, "EUW1", fixturePUUID)
+	require.NoError(t, err)
+	byRiotID, err := client.FetchAccount(ctx, "EUW1", account.GameName, account.TagLine)
+	require.NoError(t, err)
+	assert.Equal(t, fixturePUUID, byRiotID.PUUID)
+
+	startTime := int64(0)
+	ids, err := client.FetchMatchIDPage(ctx, fixturePUUID, "EUW1", &startTime, 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUW1_7665669531"}, ids)
+
+	// The match started before this startTime.
+	startTime = 1767204661 + 1
+	ids, err = client.FetchMatchIDPage(ctx, fixturePUUID, "EUW1", &startTime, 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	details, err := client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+	require.NoError(t, err)
+	assert.Len(t, details.Info.Participants, 10)
+
+	timeline, err := client.FetchMatchTimeline(ctx, 7665669531, "EUW1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, timeline.Info.Frames)
+
+	urls, err := client.FetchReplayURLs(ctx, fixturePUUID, "EUW1")
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Contains(t, urls[0], "EUW1_7665669531.replay")
+
+	entries := []api.RankEntry{{QueueType: "RANKED_SOLO_5x5", Tier: "GOLD", Rank: "II", LeaguePoints: 42}}
+	s.SetRankEntries(fixturePUUID, entries)
+	summonerID, err := client.FetchSummonerID(ctx, fixturePUUID, "EUW1")
+	require.NoError(t, err)
+	got, err := client.FetchRankEntries(ctx, summonerID, "EUW1")
+	require.NoError(t, err)
+	assert.Equal(t, entries, got)
+}
+
+func TestUnknownResourcesAreNotFound(t *testing.T) {
+	s := newFixtureServer(t)
+	client := s.Client()
+	ctx := context.Background()
+
+	_, err := client.FetchMatchDetails(ctx, 1, "EUW1")
+	assert.Error(t, err)
+	_, err = client.FetchAccount(ctx, "EUW1", "nobody", "0000")
+	assert.EqualError(t, err, "account not found on Riot servers")
+}
+
+func TestFaults(t *testing.T) {
+	s := newFixtureServer(t)
+	client := s.Client(api.WithTimeout(200 * time.Millisecond))
+	ctx := context.Background()
+	const matchPath = "/lol/match/v5/matches/EUW1_7665669531"
+
+	t.Run("not found", func(t *testing.T) {
+		s.Inject(matchPath, NotFound(), 1)
+		_, err := client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		assert.ErrorContains(t, err, "status 404")
+		_, err = client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		s.Inject(matchPath, RateLimited(0), 2)
+		before := s.Hits(matchPath)
+		_, err := client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, s.Hits(matchPath)-before)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		s.Inject(matchPath, ServerError(http.StatusServiceUnavailable), 0)
+		defer s.Reset()
+		_, err := client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		assert.ErrorContains(t, err, "status 503")
+		_, err = client.FetchMatchTimeline(ctx, 7665669531, "EUW1")
+		assert.ErrorContains(t, err, "status 503")
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		s.Inject(matchPath, Slow(time.Second), 1)
+		_, err := client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		assert.ErrorContains(t, err, "Client.Timeout")
+		_, err = client.FetchMatchDetails(ctx, 7665669531, "EUW1")
+		assert.NoError(t, err)
+	})
+}
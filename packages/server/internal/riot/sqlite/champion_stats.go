@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/galchammat/kadeem/internal/riot/postgres"
+)
+
+// newChampionStatMatches selects the participant rows of the account that are not counted in
+// champion_stats yet. $1 is the PUUID and $2 the minimum match duration.
+const newChampionStatMatches = `
+	FROM participants p
+	INNER JOIN lol_matches m ON m.id = p.match_id
+	WHERE p.puuid = $1 AND m.started_at IS NOT NULL AND m.duration >= $2
+		AND NOT EXISTS (SELECT 1 FROM champion_stats_matches c WHERE c.puuid = p.puuid AND c.match_id = p.match_id)`
+
+// RefreshChampionStats adds the account's matches of at least minDuration seconds that are
+// not counted yet to its champion stats, and returns how many were added. Matches counted
+// before their patch was known are moved to it first.
+func (s *DB) RefreshChampionStats(ctx context.Context, puuid string, minDuration int) (int64, error) {
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := postgres.RepatchChampionStats(ctx, tx, puuid); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		fmt.Sprintf(postgres.ChampionStatsUpsert, "(SELECT p.match_id"+newChampionStatMatches+")"), puuid, minDuration)
+	if err != nil {
+		return 0, fmt.Errorf("add champion stats of %s: %w", puuid, err)
+	}
+
+	// Transactions take the write lock up front (_txlock=immediate), so no other refresh can
+	// mark these matches between the two statements.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO champion_stats_matches (puuid, match_id, patch)
+		SELECT p.puuid, p.match_id, COALESCE(m.patch, '')
+		`+newChampionStatMatches, puuid, minDuration)
+	if err != nil {
+		return 0, fmt.Errorf("mark champion stats matches of %s: %w", puuid, err)
+	}
+	added, _ := res.RowsAffected()
+	return added, tx.Commit()
+}
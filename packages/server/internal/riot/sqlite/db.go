@@ -0,0 +1,20 @@
+// Package sqlite implements the riot store on SQLite for local development and tests.
+// Queries are shared with the postgres package, whose placeholders the SQLite connection
+// rewrites. Only the methods whose SQL differs are implemented here: batch upserts run row by
+// row in one transaction instead of through unnest, and timestamps are bound from Go instead
+// of NOW().
+package sqlite
+
+import (
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/riot/postgres"
+)
+
+type DB struct {
+	*postgres.DB
+	db *platformdb.DB
+}
+
+func New(db *platformdb.DB) *DB {
+	return &DB{DB: postgres.New(db), db: db}
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/postgres"
+)
+
+func (s *DB) SaveMatchEventBatch(ctx context.Context, events []models.MatchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO match_events (
+			match_id,
+			event_index,
+			participant_id,
+			time,
+			event_type,
+			victim_id,
+			assist_ids,
+			team_id,
+			item_id,
+			level,
+			subtype,
+			position_x,
+			position_y
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (match_id, event_index) DO UPDATE SET
+			participant_id = EXCLUDED.participant_id,
+			time = EXCLUDED.time,
+			event_type = EXCLUDED.event_type,
+			victim_id = EXCLUDED.victim_id,
+			assist_ids = EXCLUDED.assist_ids,
+			team_id = EXCLUDED.team_id,
+			item_id = EXCLUDED.item_id,
+			level = EXCLUDED.level,
+			subtype = EXCLUDED.subtype,
+			position_x = EXCLUDED.position_x,
+			position_y = EXCLUDED.position_y
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		if _, err := stmt.ExecContext(ctx,
+			event.MatchID,
+			event.EventIndex,
+			event.ParticipantID,
+			event.Time,
+			string(event.EventType),
+			event.VictimID,
+			postgres.IntArrayLiteral(event.AssistIDs),
+			event.TeamID,
+			event.ItemID,
+			event.Level,
+			event.Subtype,
+			event.PositionX,
+			event.PositionY,
+		); err != nil {
+			return fmt.Errorf("save match event batch: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/models"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// now is the timestamp written to and compared with DATETIME columns. Timestamps are
+// always bound from Go so every stored value has the same text format.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// EnqueueMatches inserts discovered matches as pending work. Matches that are already
+// known keep their current status. It returns the number of newly queued matches.
+func (s *DB) EnqueueMatches(ctx context.Context, matches []riot.MatchSummary) (int64, error) {
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO lol_matches (id, region, status, updated_at)
+		VALUES (?, ?, 'pending', ?)
+		ON CONFLICT DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var queued int64
+	for _, match := range matches {
+		res, err := stmt.ExecContext(ctx, match.ID, match.Region, now())
+		if err != nil {
+			return 0, fmt.Errorf("enqueue matches: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		queued += n
+	}
+	return queued, tx.Commit()
+}
+
+// ClaimPendingMatch leases one pending match, or one retry match whose backoff has elapsed,
+// to workerID for the given duration. It returns nil when there is nothing to claim.
+// SQLite has a single writer, so the UPDATE itself is the lock.
+func (s *DB) ClaimPendingMatch(ctx context.Context, workerID string, lease time.Duration) (*riot.MatchSummary, error) {
+	var match riot.MatchSummary
+	var region sql.NullString
+
+	at := now()
+	err := s.db.SQL.QueryRowContext(ctx, `
+		UPDATE lol_matches
+		SET status = 'processing',
+			leased_by = ?1,
+			lease_expires_at = ?2,
+			updated_at = ?3
+		WHERE id = (
+			SELECT id
+			FROM lol_matches
+			WHERE status = 'pending'
+			   OR (status = 'retry' AND (next_attempt_at IS NULL OR next_attempt_at <= ?3))
+			ORDER BY next_attempt_at NULLS FIRST
+			LIMIT 1
+		)
+		RETURNING id, region, attempts
+	`, workerID, at.Add(lease), at).Scan(&match.ID, &region, &match.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim pending match: %w", err)
+	}
+
+	match.Region = region.String
+	match.Status = models.StatusProcessing
+	return &match, nil
+}
+
+// AckMatch marks a match claimed by workerID as done and releases its lease. It returns
+// riot.ErrLeaseLost when the worker no longer holds the lease.
+func (s *DB) AckMatch(ctx context.Context, matchId int64, region, workerID string) error {
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET status = 'done',
+			attempts = 0,
+			last_error = NULL,
+			next_attempt_at = NULL,
+			leased_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = ?4
+		WHERE id = ?1
+		  AND region = ?2
+		  AND leased_by = ?3
+		  AND status = 'processing'
+	`, matchId, region, workerID, now())
+	if err != nil {
+		return fmt.Errorf("ack match: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return riot.ErrLeaseLost
+	}
+	return nil
+}
+
+// NackMatch releases the lease workerID holds on a match whose detail fetch failed and bumps
+// its attempt counter. The next attempt is scheduled with exponential backoff
+// (baseDelay * 2^(attempts-1), capped at maxDelay), and the match moves to the DLQ once it has
+// failed maxAttempts times. It returns riot.ErrLeaseLost when the worker no longer holds the lease.
+func (s *DB) NackMatch(ctx context.Context, matchId int64, region, workerID, lastError string, maxAttempts int, baseDelay, maxDelay time.Duration) (models.Status, error) {
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var attempts int
+	err = tx.QueryRowContext(ctx, `
+		SELECT attempts FROM lol_matches
+		WHERE id = ?1 AND region = ?2 AND leased_by = ?3 AND status = 'processing'
+	`, matchId, region, workerID).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", riot.ErrLeaseLost
+	}
+	if err != nil {
+		return "", fmt.Errorf("nack match: %w", err)
+	}
+
+	delay := baseDelay
+	for i := 0; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	status := models.StatusRetry
+	if attempts+1 >= maxAttempts {
+		status = models.StatusDLQ
+	}
+
+	at := now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET attempts = attempts + 1,
+			last_error = ?3,
+			status = ?4,
+			next_attempt_at = ?5,
+			leased_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = ?6
+		WHERE id = ?1
+		  AND region = ?2
+	`, matchId, region, lastError, string(status), at.Add(delay), at)
+	if err != nil {
+		return "", fmt.Errorf("nack match: %w", err)
+	}
+	return status, tx.Commit()
+}
+
+// ReclaimExpiredLeases puts processing matches whose lease ran out back into the queue,
+// e.g. after the worker that claimed them crashed. A reclaim counts as a failed attempt, so a
+// match that keeps killing its worker moves to the DLQ after maxAttempts. It returns the
+// number of reclaimed matches.
+func (s *DB) ReclaimExpiredLeases(ctx context.Context, maxAttempts int) (int64, error) {
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET attempts = attempts + 1,
+			last_error = 'lease expired',
+			status = CASE WHEN attempts + 1 >= ?2 THEN 'dlq' ELSE 'retry' END,
+			next_attempt_at = ?1,
+			leased_by = NULL,
+			lease_expires_at = NULL,
+			updated_at = ?1
+		WHERE status = 'processing'
+		  AND (lease_expires_at IS NULL OR lease_expires_at <= ?1)
+	`, now(), maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("reclaim expired leases: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RequeueMatch moves a DLQ match back to retry with a fresh attempt counter.
+// A nil matchID requeues every DLQ match. It returns the number of requeued matches.
+func (s *DB) RequeueMatch(ctx context.Context, matchID *int64) (int64, error) {
+	res, err := s.db.SQL.ExecContext(ctx, `
+		UPDATE lol_matches
+		SET status = 'retry',
+			attempts = 0,
+			next_attempt_at = ?2,
+			updated_at = ?2
+		WHERE status = 'dlq'
+		  AND (?1 IS NULL OR id = ?1)
+	`, matchID, now())
+	if err != nil {
+		return 0, fmt.Errorf("requeue match: %w", err)
+	}
+	return res.RowsAffected()
+}
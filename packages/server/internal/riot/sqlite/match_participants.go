@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+)
+
+func (s *DB) SaveMatchParticipantBatch(ctx context.Context, participants []models.MatchParticipantSummary) error {
+	if len(participants) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO participants (
+			match_id,
+			champion_id,
+			champ_level,
+			kills,
+			deaths,
+			assists,
+			total_minions_killed,
+			double_kills,
+			triple_kills,
+			quadra_kills,
+			penta_kills,
+			item0,
+			item1,
+			item2,
+			item3,
+			item4,
+			item5,
+			item6,
+			summoner1_id,
+			summoner2_id,
+			lane,
+			participant_id,
+			puuid,
+			riot_id_game_name,
+			riot_id_tagline,
+			total_damage_dealt_to_champions,
+			total_damage_taken,
+			win,
+			team_id,
+			team_position
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (match_id, participant_id) DO UPDATE SET
+			champion_id = EXCLUDED.champion_id,
+			champ_level = EXCLUDED.champ_level,
+			kills = EXCLUDED.kills,
+			deaths = EXCLUDED.deaths,
+			assists = EXCLUDED.assists,
+			total_minions_killed = EXCLUDED.total_minions_killed,
+			double_kills = EXCLUDED.double_kills,
+			triple_kills = EXCLUDED.triple_kills,
+			quadra_kills = EXCLUDED.quadra_kills,
+			penta_kills = EXCLUDED.penta_kills,
+			item0 = EXCLUDED.item0,
+			item1 = EXCLUDED.item1,
+			item2 = EXCLUDED.item2,
+			item3 = EXCLUDED.item3,
+			item4 = EXCLUDED.item4,
+			item5 = EXCLUDED.item5,
+			item6 = EXCLUDED.item6,
+			summoner1_id = EXCLUDED.summoner1_id,
+			summoner2_id = EXCLUDED.summoner2_id,
+			lane = EXCLUDED.lane,
+			puuid = EXCLUDED.puuid,
+			riot_id_game_name = EXCLUDED.riot_id_game_name,
+			riot_id_tagline = EXCLUDED.riot_id_tagline,
+			total_damage_dealt_to_champions = EXCLUDED.total_damage_dealt_to_champions,
+			total_damage_taken = EXCLUDED.total_damage_taken,
+			win = EXCLUDED.win,
+			team_id = EXCLUDED.team_id,
+			team_position = EXCLUDED.team_position
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range participants {
+		if _, err := stmt.ExecContext(ctx,
+			p.GameID,
+			p.ChampionID,
+			p.ChampLevel,
+			p.Kills,
+			p.Deaths,
+			p.Assists,
+			p.TotalMinionsKilled,
+			p.DoubleKills,
+			p.TripleKills,
+			p.QuadraKills,
+			p.PentaKills,
+			p.Item0,
+			p.Item1,
+			p.Item2,
+			p.Item3,
+			p.Item4,
+			p.Item5,
+			p.Item6,
+			p.Summoner1ID,
+			p.Summoner2ID,
+			p.Lane,
+			p.ParticipantID,
+			p.PUUID,
+			p.RiotIDGameName,
+			p.RiotIDTagline,
+			p.TotalDamageDealtToChampions,
+			p.TotalDamageTaken,
+			p.Win,
+			p.TeamID,
+			p.TeamPosition,
+		); err != nil {
+			return fmt.Errorf("save match participant batch: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	coremodels "github.com/galchammat/kadeem/internal/models"
+	"github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// SaveMatchSummaryBatch upserts match details. New rows take the summary status (done by
+// default); the status of queued rows is left to AckMatch/NackMatch.
+func (s *DB) SaveMatchSummaryBatch(ctx context.Context, matchSummaries []models.MatchSummary) error {
+	if len(matchSummaries) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO lol_matches (id, region, started_at, duration, queue_id, patch, status, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, NULLIF(?6, ''), ?7, ?8)
+		ON CONFLICT (id, region) DO UPDATE SET
+			started_at = EXCLUDED.started_at,
+			duration = EXCLUDED.duration,
+			queue_id = EXCLUDED.queue_id,
+			patch = COALESCE(EXCLUDED.patch, lol_matches.patch),
+			updated_at = EXCLUDED.updated_at
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, summary := range matchSummaries {
+		status := summary.Status
+		if status == "" {
+			status = coremodels.StatusDone
+		}
+		if _, err := stmt.ExecContext(ctx,
+			summary.ID, summary.Region, summary.StartedAt, summary.Duration, summary.QueueID, summary.Patch, string(status), now(),
+		); err != nil {
+			return fmt.Errorf("save match summary batch: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
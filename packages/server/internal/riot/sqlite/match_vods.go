@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"fmt"
+
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// ReplaceStreamerMatchVODs swaps the stored VOD alignments of a streamer for vods.
+func (s *DB) ReplaceStreamerMatchVODs(streamerID int64, vods []riot.MatchVOD) error {
+	tx, err := s.db.SQL.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`DELETE FROM match_vods WHERE streamer_id = ?1`, streamerID); err != nil {
+		return fmt.Errorf("delete match vods of streamer %d: %w", streamerID, err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO match_vods (match_id, broadcast_id, streamer_id, puuid, vod_offset, match_offset, overlap, url)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (match_id, broadcast_id) DO UPDATE SET
+			streamer_id = EXCLUDED.streamer_id,
+			puuid = EXCLUDED.puuid,
+			vod_offset = EXCLUDED.vod_offset,
+			match_offset = EXCLUDED.match_offset,
+			overlap = EXCLUDED.overlap,
+			url = EXCLUDED.url
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, vod := range vods {
+		if _, err := stmt.Exec(vod.MatchID, vod.BroadcastID, streamerID, vod.PUUID, vod.VODOffset, vod.MatchOffset, vod.Overlap, vod.URL); err != nil {
+			return fmt.Errorf("insert match vods of streamer %d: %w", streamerID, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/logging"
+)
+
+// allowedMatchColumns is the set of columns that can be updated via UpdateLolMatch.
+var allowedMatchColumns = map[string]bool{
+	"status":            true,
+	"duration":          true,
+	"replay_uri":        true,
+	"replay_status":     true,
+	"replay_updated_at": true,
+}
+
+func (s *DB) UpdateLolMatch(matchID int64, updates map[string]any) (bool, error) {
+	var setClauses []string
+	var args []any
+	argN := 1
+
+	for column, value := range updates {
+		if !allowedMatchColumns[column] {
+			return false, fmt.Errorf("disallowed column: %s", column)
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = ?%d", column, argN))
+		args = append(args, value)
+		argN++
+	}
+	if len(setClauses) == 0 {
+		return false, nil
+	}
+	args = append(args, now(), matchID)
+
+	query := `UPDATE lol_matches SET ` + strings.Join(setClauses, ", ") + fmt.Sprintf(`, updated_at = ?%d WHERE id = ?%d`, argN, argN+1)
+
+	res, err := s.db.SQL.Exec(query, args...)
+	if err != nil {
+		logging.Error("Failed to update match in database", "matchID", matchID, "error", err)
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return (n != 0), nil
+}
@@ -0,0 +1,492 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/models"
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := platformdb.OpenSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.SQL.Close() })
+
+	m, err := platformdb.NewMigrate(db, "../../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	_, err = db.SQL.Exec(`INSERT INTO streamers (id, name) VALUES (1, 'streamer')`)
+	require.NoError(t, err)
+	return New(db)
+}
+
+func TestAccounts(t *testing.T) {
+	s := newTestDB(t)
+
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "p1", StreamerID: 1, GameName: "Faker", TagLine: "KR1", Region: "kr"}))
+	require.NoError(t, s.TrackAccount("user", "p1"))
+	require.NoError(t, s.TrackAccount("user", "p1"))
+
+	account, err := s.FindRiotAccount("Faker", "KR1", "kr")
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	assert.Equal(t, "p1", account.PUUID)
+
+	tracked, err := s.ListTrackedAccounts("user", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, tracked, 1)
+
+	ok, err := s.IsTrackingAccount("user", "p1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	updated, err := s.UpdateRiotAccount("p1", map[string]any{"synced_at": int64(42)})
+	require.NoError(t, err)
+	assert.True(t, updated)
+	account, err = s.GetRiotAccount("p1")
+	require.NoError(t, err)
+	require.NotNil(t, account.SyncedAt)
+	assert.Equal(t, int64(42), *account.SyncedAt)
+}
+
+func TestMatchQueue(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	n, err := s.EnqueueMatches(ctx, []riot.MatchSummary{{ID: 1, Region: "na1"}, {ID: 2, Region: "na1"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = s.EnqueueMatches(ctx, []riot.MatchSummary{{ID: 1, Region: "na1"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	first, err := s.ClaimPendingMatch(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	second, err := s.ClaimPendingMatch(ctx, "w2", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.NotEqual(t, first.ID, second.ID)
+	none, err := s.ClaimPendingMatch(ctx, "w3", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	// Only the worker holding the lease may ack or nack a match.
+	assert.ErrorIs(t, s.AckMatch(ctx, first.ID, "na1", "w2"), riot.ErrLeaseLost)
+	_, err = s.NackMatch(ctx, second.ID, "na1", "w1", "boom", 2, time.Hour, 2*time.Hour)
+	assert.ErrorIs(t, err, riot.ErrLeaseLost)
+	require.NoError(t, s.AckMatch(ctx, first.ID, "na1", "w1"))
+	assert.ErrorIs(t, s.AckMatch(ctx, first.ID, "na1", "w1"), riot.ErrLeaseLost)
+
+	status, err := s.NackMatch(ctx, second.ID, "na1", "w2", "boom", 2, time.Hour, 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusRetry, status)
+	// The backoff has not elapsed yet.
+	none, err = s.ClaimPendingMatch(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = s.db.SQL.Exec(`UPDATE lol_matches SET next_attempt_at = ? WHERE id = ?`, now().Add(-time.Second), second.ID)
+	require.NoError(t, err)
+	retry, err := s.ClaimPendingMatch(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, retry)
+	assert.Equal(t, 1, retry.Attempts)
+
+	status, err = s.NackMatch(ctx, second.ID, "na1", "w1", "boom again", 2, time.Hour, 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDLQ, status)
+
+	requeued, err := s.RequeueMatch(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+
+	// A lease that already ran out is reclaimed as a failed attempt, and the worker that lost it
+	// can no longer ack the match.
+	claimed, err := s.ClaimPendingMatch(ctx, "w1", -time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	reclaimed, err := s.ReclaimExpiredLeases(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reclaimed)
+	assert.ErrorIs(t, s.AckMatch(ctx, claimed.ID, "na1", "w1"), riot.ErrLeaseLost)
+
+	// A match whose lease keeps running out ends up in the DLQ.
+	claimed, err = s.ClaimPendingMatch(ctx, "w1", -time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 1, claimed.Attempts)
+	reclaimed, err = s.ReclaimExpiredLeases(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reclaimed)
+	var matchStatus models.Status
+	require.NoError(t, s.db.SQL.QueryRow(`SELECT status FROM lol_matches WHERE id = ?`, claimed.ID).Scan(&matchStatus))
+	assert.Equal(t, models.StatusDLQ, matchStatus)
+}
+
+func TestListLolMatches(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 1000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 2000, Duration: 1500, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true, PentaKills: 1},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 157, Lane: "MIDDLE"},
+	}))
+	require.NoError(t, s.SaveMatchEventBatch(ctx, []riot.MatchEvent{
+		{MatchID: 1, EventIndex: 0, EventType: riot.MatchEventChampionKill, AssistIDs: []int{2, 5}},
+	}))
+
+	matches, err := s.ListLolMatches(nil, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, int64(2), matches[0].Summary.ID)
+	assert.Len(t, matches[0].Participants, 1)
+
+	puuid, win := "p1", true
+	matches, err = s.ListLolMatches(&riot.MatchFilter{PUUID: &puuid, Win: &win}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, int64(1), matches[0].Summary.ID)
+
+	uri := "local://1.rofl"
+	updated, err := s.UpdateLolMatch(1, map[string]any{"replay_uri": uri, "replay_status": "stored"})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	replays, err := s.ListStoredReplays(ctx)
+	require.NoError(t, err)
+	require.Len(t, replays, 1)
+	assert.Equal(t, riot.StoredReplay{MatchID: 1, ReplayURI: uri, StartedAt: 1000, Pentakill: true}, replays[0])
+
+	expired, err := s.ExpireMatchReplays(ctx, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+	matches, err = s.ListLolMatches(&riot.MatchFilter{MatchID: &replays[0].MatchID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "expired", matches[0].Summary.ReplayStatus)
+	assert.Nil(t, matches[0].Summary.ReplayURI)
+
+	_, err = s.UpdateLolMatch(1, map[string]any{"replay_uri": uri, "replay_status": "stored"})
+	require.NoError(t, err)
+	reset, err := s.ResetMatchReplays(ctx, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reset)
+	matches, err = s.ListLolMatches(&riot.MatchFilter{MatchID: &replays[0].MatchID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "pending", matches[0].Summary.ReplayStatus)
+	assert.Nil(t, matches[0].Summary.ReplayURI)
+}
+
+func TestMatchVODs(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	_, err := s.db.SQL.Exec(`
+		INSERT INTO channels (id, streamer_id, platform, channel_name, avatar_url) VALUES ('c1', 1, 'twitch', 'streamer', '');
+		INSERT INTO broadcasts (id, channel_id, url, thumbnail_url, viewable, created_at, published_at, duration)
+		VALUES (7, 'c1', 'https://vod/7', '', 'public', 900, 900, 7200);`)
+	require.NoError(t, err)
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "p1", StreamerID: 1, GameName: "Faker", TagLine: "KR1", Region: "kr"}))
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{{ID: 1, Region: "kr", StartedAt: 1_000_000, Duration: 1800}}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{{GameID: 1, ParticipantID: 1, PUUID: "p1"}}))
+
+	pairs, err := s.ListMatchBroadcasts(1)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, int64(7), pairs[0].BroadcastID)
+
+	vod := riot.MatchVOD{MatchID: 1, BroadcastID: 7, StreamerID: 1, PUUID: "p1", VODOffset: 100, Overlap: 1800, URL: "https://vod/7?t=100s"}
+	require.NoError(t, s.ReplaceStreamerMatchVODs(1, []riot.MatchVOD{vod}))
+	require.NoError(t, s.ReplaceStreamerMatchVODs(1, []riot.MatchVOD{vod}))
+
+	matches, err := s.ListLolMatches(nil, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, []riot.MatchVOD{vod}, matches[0].VODs)
+}
+
+func TestChampionStats(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	const day = 86_400_000
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 10 * day, Duration: 1800, QueueID: 420, Patch: "15.9"},
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420, Patch: "15.9"},
+		{ID: 3, Region: "na1", StartedAt: 11 * day, Duration: 200, QueueID: 420, Patch: "15.10"},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true, Kills: 10, Deaths: 2, Assists: 5, TotalMinionsKilled: 240, ChampLevel: 17},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Kills: 2, Deaths: 6, Assists: 3, TotalMinionsKilled: 150, ChampLevel: 14},
+		{GameID: 2, ParticipantID: 2, PUUID: "p2", ChampionID: 157, Lane: "MIDDLE"},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE"},
+	}))
+
+	added, err := s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), added)
+	added, err = s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Zero(t, added)
+
+	totals, err := s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.ChampionStatTotals{{
+		ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 2, Wins: 1, Kills: 12, Deaths: 8, Assists: 8,
+		CS: 390, ChampLevels: 31, Duration: 3000,
+	}}, totals)
+
+	// Matches synced later are added to the totals.
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{{ID: 4, Region: "na1", StartedAt: 12 * day, Duration: 1500, QueueID: 420, Patch: "15.10"}}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true},
+	}))
+	added, err = s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), added)
+
+	fromDay := int64(11)
+	totals, err = s.ListChampionStats(ctx, "p1", &riot.ChampionStatsFilter{FromDay: &fromDay})
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, "15.10", totals[0].Patch)
+	assert.Equal(t, 1, totals[0].Games)
+
+	// The patch is kept when a summary without one is saved again.
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{{ID: 4, Region: "na1", StartedAt: 12 * day, Duration: 1500, QueueID: 420}}))
+	matchID := int64(4)
+	matches, err := s.ListLolMatches(&riot.MatchFilter{MatchID: &matchID}, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "15.10", matches[0].Summary.Patch)
+}
+
+func TestChampionStatsLatePatch(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	const day = 86_400_000
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 10 * day, Duration: 1800, QueueID: 420, Patch: "15.9"},
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true, Kills: 10, Deaths: 2, ChampLevel: 17},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Kills: 2, Deaths: 6, ChampLevel: 14},
+	}))
+
+	// A match without a patch is counted under an empty one until it gets one.
+	added, err := s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), added)
+	totals, err := s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []riot.ChampionStatTotals{
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 1, Wins: 1, Kills: 10, Deaths: 2, ChampLevels: 17, Duration: 1800},
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "", Games: 1, Kills: 2, Deaths: 6, ChampLevels: 14, Duration: 1200},
+	}, totals)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420, Patch: "15.9"},
+	}))
+	added, err = s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Zero(t, added)
+	totals, err = s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.ChampionStatTotals{{
+		ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 2, Wins: 1, Kills: 12, Deaths: 8,
+		ChampLevels: 31, Duration: 3000,
+	}}, totals)
+}
+
+func TestLaneMatchups(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 2_000_000, Duration: 1500, QueueID: 420},
+		{ID: 3, Region: "na1", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "na1", StartedAt: 4_000_000, Duration: 1600, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE", Win: true, Kills: 8, Deaths: 1, TotalMinionsKilled: 220, TotalDamageDealtToChampions: 25_000},
+		// A teammate roaming mid is not an opponent.
+		{GameID: 1, ParticipantID: 2, PUUID: "p2", ChampionID: 238, Lane: "MIDDLE", TeamID: 100, TeamPosition: "JUNGLE", Win: true},
+		{GameID: 1, ParticipantID: 6, PUUID: "e1", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Kills: 2, Deaths: 6, TotalMinionsKilled: 200, TotalDamageDealtToChampions: 15_000},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE", Kills: 1, Deaths: 5, TotalMinionsKilled: 150, TotalDamageDealtToChampions: 10_000},
+		{GameID: 2, ParticipantID: 6, PUUID: "e2", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Win: true, Kills: 5, Deaths: 1, TotalMinionsKilled: 170, TotalDamageDealtToChampions: 20_000},
+		// Remakes are left out.
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE"},
+		{GameID: 3, ParticipantID: 6, PUUID: "e3", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Win: true},
+		// Bot lane has two players per team, paired by position.
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 1, Lane: "BOTTOM", TeamID: 100, TeamPosition: "BOTTOM", Win: true, Kills: 4},
+		{GameID: 4, ParticipantID: 2, PUUID: "p3", ChampionID: 40, Lane: "BOTTOM", TeamID: 100, TeamPosition: "UTILITY", Win: true},
+		{GameID: 4, ParticipantID: 6, PUUID: "e4", ChampionID: 22, Lane: "BOTTOM", TeamID: 200, TeamPosition: "BOTTOM", Kills: 1},
+		{GameID: 4, ParticipantID: 7, PUUID: "e5", ChampionID: 412, Lane: "BOTTOM", TeamID: 200, TeamPosition: "UTILITY"},
+	}))
+
+	matchups, err := s.ListLaneMatchups(ctx, "p1", 300, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.LaneMatchupTotals{
+		{EnemyChampionID: 157, Lane: "MIDDLE", Games: 2, Wins: 1, KillDiff: 2, DeathDiff: -1, DamageDiff: 0, CSDiff: 0},
+		{EnemyChampionID: 22, Lane: "BOTTOM", Games: 1, Wins: 1, KillDiff: 3},
+	}, matchups)
+
+	supportLane := "UTILITY"
+	matchups, err = s.ListLaneMatchups(ctx, "p3", 300, &riot.MatchupFilter{Lane: &supportLane})
+	require.NoError(t, err)
+	assert.Equal(t, []riot.LaneMatchupTotals{{EnemyChampionID: 412, Lane: "UTILITY", Games: 1, Wins: 1}}, matchups)
+
+	minGames := &riot.MatchupFilter{MinGames: 3}
+	matchups, err = s.ListLaneMatchups(ctx, "p1", 300, minGames)
+	require.NoError(t, err)
+	assert.Empty(t, matchups)
+
+	championID := 238
+	matchups, err = s.ListLaneMatchups(ctx, "p1", 300, &riot.MatchupFilter{ChampionID: &championID})
+	require.NoError(t, err)
+	assert.Empty(t, matchups)
+}
+
+func TestRankedGames(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	for _, rank := range []riot.PlayerRank{
+		{PUUID: "p1", Timestamp: 100, Tier: "GOLD", Rank: "II", QueueID: 420},
+		{PUUID: "p1", Timestamp: 200, Tier: "GOLD", Rank: "II", QueueID: 420},
+		{PUUID: "p1", Timestamp: 300, Tier: "GOLD", Rank: "I", QueueID: 420},
+		{PUUID: "p1", Timestamp: 400, Tier: "GOLD", Rank: "I", QueueID: 420},
+		{PUUID: "p1", Timestamp: 250, Tier: "SILVER", Rank: "I", QueueID: 440},
+	} {
+		require.NoError(t, s.InsertPlayerRank(&rank))
+	}
+
+	// The snapshots just outside the window are kept to bracket its games.
+	ranks, err := s.ListPlayerRanks(ctx, "p1", 420, 250, 260)
+	require.NoError(t, err)
+	require.Len(t, ranks, 2)
+	assert.Equal(t, int64(200), ranks[0].Timestamp)
+	assert.Equal(t, int64(300), ranks[1].Timestamp)
+
+	ranks, err = s.ListPlayerRanks(ctx, "p1", 420, 0, 1000)
+	require.NoError(t, err)
+	assert.Len(t, ranks, 4)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 150_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 160_000, Duration: 200, QueueID: 420},
+		{ID: 3, Region: "na1", StartedAt: 170_000, Duration: 1800, QueueID: 440},
+		{ID: 4, Region: "na1", StartedAt: 500_000, Duration: 1800, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Win: true},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+	}))
+
+	games, err := s.ListRankedGames(ctx, "p1", 420, 300, 0, 400)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.RankedGame{
+		{MatchID: 1, QueueID: 420, StartedAt: 150_000, Duration: 1800, ChampionID: 103, Win: true},
+	}, games)
+}
+
+func TestListPlayedGames(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 2_000_000, Duration: 1500, QueueID: 450},
+		{ID: 3, Region: "na1", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "na1", StartedAt: 9_000_000, Duration: 1500, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Win: true, Kills: 5, Deaths: 1, Assists: 7},
+		{GameID: 1, ParticipantID: 6, PUUID: "e1", ChampionID: 157},
+		{GameID: 2, ParticipantID: 1, PUUID: "smurf", ChampionID: 238},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+	}))
+
+	games, err := s.ListPlayedGames(ctx, []string{"p1", "smurf"}, 300, 0, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.PlayedGame{
+		{MatchID: 1, PUUID: "p1", QueueID: 420, StartedAt: 1_000_000, Duration: 1800, ChampionID: 103, Win: true, Kills: 5, Deaths: 1, Assists: 7},
+		{MatchID: 2, PUUID: "smurf", QueueID: 450, StartedAt: 2_000_000, Duration: 1500, ChampionID: 238},
+	}, games)
+
+	games, err = s.ListPlayedGames(ctx, nil, 300, 0, 5000)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestTeammates(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDB(t)
+
+	_, err := s.db.SQL.Exec(`INSERT INTO streamers (id, name) VALUES (2, 'rival')`)
+	require.NoError(t, err)
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "p1", StreamerID: 1, GameName: "Faker", TagLine: "KR1", Region: "kr"}))
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "r1", StreamerID: 2, GameName: "Rival", TagLine: "KR1", Region: "kr"}))
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "kr", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "kr", StartedAt: 2_000_000, Duration: 1800, QueueID: 420},
+		{ID: 3, Region: "kr", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "kr", StartedAt: 500_000, Duration: 1200, QueueID: 1700},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", TeamID: 100, Win: true},
+		{GameID: 1, ParticipantID: 2, PUUID: "duo", TeamID: 100, RiotIDGameName: "OldName", RiotIDTagline: "KR1", Win: true},
+		{GameID: 1, ParticipantID: 6, PUUID: "r1", TeamID: 200},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", TeamID: 100},
+		{GameID: 2, ParticipantID: 2, PUUID: "duo", TeamID: 100, RiotIDGameName: "Duo", RiotIDTagline: "KR1"},
+		{GameID: 2, ParticipantID: 3, PUUID: "r1", TeamID: 100},
+		{GameID: 2, ParticipantID: 6, PUUID: "enemy", TeamID: 200, Win: true},
+		// Remakes are left out.
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", TeamID: 100},
+		{GameID: 3, ParticipantID: 2, PUUID: "r1", TeamID: 100},
+		// Arena has more than two teams, so losing together does not make teammates.
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", TeamID: 1},
+		{GameID: 4, ParticipantID: 3, PUUID: "duo", TeamID: 2},
+	}))
+
+	teammates, err := s.ListTeammates(ctx, "p1", 300, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, teammates, 2)
+	assert.Equal(t, riot.TeammateTotals{
+		PUUID: "duo", GameName: "Duo", TagLine: "KR1", Region: "kr", Games: 2, Wins: 1, LastSeen: 2_000_000,
+	}, teammates[0])
+	assert.Equal(t, "r1", teammates[1].PUUID)
+	require.NotNil(t, teammates[1].StreamerID)
+	assert.Equal(t, 2, *teammates[1].StreamerID)
+
+	teammates, err = s.ListTeammates(ctx, "p1", 300, 2, 10)
+	require.NoError(t, err)
+	assert.Len(t, teammates, 1)
+
+	meetings, err := s.ListStreamerMeetings(ctx, 1, 300, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.StreamerMeeting{
+		{MatchID: 2, StartedAt: 2_000_000, QueueID: 420, PUUID: "p1", OtherStreamerID: 2, OtherStreamerName: "rival", OtherPUUID: "r1", SameTeam: true},
+		{MatchID: 1, StartedAt: 1_000_000, QueueID: 420, PUUID: "p1", OtherStreamerID: 2, OtherStreamerName: "rival", OtherPUUID: "r1", SameTeam: false},
+	}, meetings)
+}
@@ -0,0 +1,105 @@
+// Package store defines the storage interface of the riot domain and opens the backend
+// that matches the database driver.
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/models"
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/postgres"
+	"github.com/galchammat/kadeem/internal/riot/sqlite"
+)
+
+// AccountStore keeps Riot accounts and who tracks them.
+type AccountStore interface {
+	SaveRiotAccount(account *riot.Account) error
+	GetRiotAccount(puuid string) (*riot.Account, error)
+	FindRiotAccount(gameName, tagLine, region string) (*riot.Account, error)
+	FindOrCreateRiotAccount(gameName, tagLine, region string, streamerID int) (*riot.Account, error)
+	ListTrackedAccounts(userID string, limit, offset int) ([]riot.Account, error)
+	TrackAccount(userID string, accountPUUID string) error
+	UntrackAccount(userID string, accountPUUID string) error
+	IsTrackingAccount(userID string, accountPUUID string) (bool, error)
+	GetTrackedAccountsForSync() ([]riot.Account, error)
+	ListRiotAccounts(filter *riot.Account, limit, offset int) ([]riot.Account, error)
+	DeleteRiotAccount(puuid string) error
+	UpdateRiotAccount(PUUID string, updates map[string]any) (bool, error)
+}
+
+// MatchStore keeps matches with their participants and timeline events.
+type MatchStore interface {
+	ListLolMatches(filter *riot.MatchFilter, limit, offset int) ([]riot.Match, error)
+	UpdateLolMatch(matchID int64, updates map[string]any) (bool, error)
+	SaveMatchSummaryBatch(ctx context.Context, matchSummaries []riot.MatchSummary) error
+	SaveMatchParticipantBatch(ctx context.Context, participants []riot.MatchParticipantSummary) error
+	SaveMatchEventBatch(ctx context.Context, events []riot.MatchEvent) error
+}
+
+// MatchQueue is the work queue of match detail fetches kept in lol_matches.
+type MatchQueue interface {
+	EnqueueMatches(ctx context.Context, matches []riot.MatchSummary) (int64, error)
+	ClaimPendingMatch(ctx context.Context, workerID string, lease time.Duration) (*riot.MatchSummary, error)
+	AckMatch(ctx context.Context, matchId int64, region, workerID string) error
+	NackMatch(ctx context.Context, matchId int64, region, workerID, lastError string, maxAttempts int, baseDelay, maxDelay time.Duration) (models.Status, error)
+	ReclaimExpiredLeases(ctx context.Context, maxAttempts int) (int64, error)
+	RequeueMatch(ctx context.Context, matchID *int64) (int64, error)
+}
+
+// RankStore keeps rank snapshots and finds the ranked games between them.
+type RankStore interface {
+	InsertPlayerRank(rank *riot.PlayerRank) error
+	GetRankAtTime(puuid string, queueID int, timestamp int64) (*riot.PlayerRank, error)
+	ListPlayerRanks(ctx context.Context, puuid string, queueID int, from, to int64) ([]riot.PlayerRank, error)
+	ListRankedGames(ctx context.Context, puuid string, queueID, minDuration int, from, to int64) ([]riot.RankedGame, error)
+}
+
+// VODStore keeps the alignment of matches with broadcasts.
+type VODStore interface {
+	ListMatchBroadcasts(streamerID int64) ([]riot.MatchBroadcast, error)
+	ReplaceStreamerMatchVODs(streamerID int64, vods []riot.MatchVOD) error
+	ListAlignedKills() ([]riot.AlignedKill, error)
+}
+
+// ReplayIndex tracks which matches have a stored replay.
+type ReplayIndex interface {
+	ListStoredReplays(ctx context.Context) ([]riot.StoredReplay, error)
+	ExpireMatchReplays(ctx context.Context, matchIDs []int64) (int64, error)
+	ResetMatchReplays(ctx context.Context, matchIDs []int64) (int64, error)
+}
+
+// StatsStore keeps incrementally maintained aggregates of match history.
+type StatsStore interface {
+	RefreshChampionStats(ctx context.Context, puuid string, minDuration int) (int64, error)
+	ListChampionStats(ctx context.Context, puuid string, filter *riot.ChampionStatsFilter) ([]riot.ChampionStatTotals, error)
+	ListLaneMatchups(ctx context.Context, puuid string, minDuration int, filter *riot.MatchupFilter) ([]riot.LaneMatchupTotals, error)
+	ListPlayedGames(ctx context.Context, puuids []string, minDuration int, from, to int64) ([]riot.PlayedGame, error)
+	ListTeammates(ctx context.Context, puuid string, minDuration, minGames, limit int) ([]riot.TeammateTotals, error)
+	ListStreamerMeetings(ctx context.Context, streamerID, minDuration, limit, offset int) ([]riot.StreamerMeeting, error)
+}
+
+// Store is everything the riot domain persists.
+type Store interface {
+	AccountStore
+	MatchStore
+	MatchQueue
+	RankStore
+	VODStore
+	ReplayIndex
+	StatsStore
+}
+
+var (
+	_ Store = (*postgres.DB)(nil)
+	_ Store = (*sqlite.DB)(nil)
+)
+
+// New returns the Store backend for the driver of db.
+func New(db *platformdb.DB) Store {
+	if db.Driver == platformdb.DriverSQLite {
+		return sqlite.New(db)
+	}
+	return postgres.New(db)
+}
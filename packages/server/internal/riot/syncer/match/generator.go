@@ -13,6 +13,7 @@ const matchIDPageSize = 49
 const defaultLookbackDays = 1
 const defaultLookback = 60 * 60 * 24 * defaultLookbackDays
 
+// Sync discovers new match ids for every riot account and queues them for the workers.
 func (s *MatchSyncer) Sync(ctx context.Context) error {
 	for offset := 0; ; offset += matchIDPageSize {
 		accounts, err := s.store.ListRiotAccounts(nil, matchIDPageSize, offset)
@@ -42,7 +43,7 @@ func (s *MatchSyncer) syncAccount(ctx context.Context, account models.Account) e
 			return err
 		}
 
-		matchIDs, err := s.client.FetchMatchIDPage(account.PUUID, account.Region, startTime, start, matchIDPageSize)
+		matchIDs, err := s.client.FetchMatchIDPage(ctx, account.PUUID, account.Region, startTime, start, matchIDPageSize)
 		if err != nil {
 			return fmt.Errorf("fetch match id page for puuid %q start %d: %w", account.PUUID, start, err)
 		}
@@ -51,11 +52,20 @@ func (s *MatchSyncer) syncAccount(ctx context.Context, account models.Account) e
 			return nil
 		}
 
-		// details and timelines
-		if err := s.processMatches(ctx, matchIDs); err != nil {
+		// details and timelines are fetched by the queue workers
+		matches := make([]models.MatchSummary, 0, len(matchIDs))
+		for _, fullMatchID := range matchIDs {
+			region, matchID, err := parseFullMatchID(fullMatchID)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, models.MatchSummary{ID: matchID, Region: region})
+		}
+		queued, err := s.store.EnqueueMatches(ctx, matches)
+		if err != nil {
 			return err
 		}
 
-		logging.Info("synced riot match id page", "puuid", account.PUUID, "start", start, "count", len(matchIDs))
+		logging.Info("synced riot match id page", "puuid", account.PUUID, "start", start, "count", len(matchIDs), "queued", queued)
 	}
 }
@@ -11,6 +11,11 @@ func mapMatchDetails(matchDetails riotmodels.MatchDetails) (riotmodels.MatchSumm
 		StartedAt: matchDetails.Info.StartedAt,
 		Duration:  matchDetails.Info.Duration,
 		QueueID:   matchDetails.Info.QueueID,
+		Patch:     riotmodels.Patch(matchDetails.Info.GameVersion),
 	}
-	return summary, matchDetails.Info.Participants
+	participants := matchDetails.Info.Participants
+	for i := range participants {
+		participants[i].GameID = summary.ID
+	}
+	return summary, participants
 }
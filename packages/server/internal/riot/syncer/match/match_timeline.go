@@ -1 +1,93 @@
 package matchsync
+
+import (
+	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// mapMatchTimeline flattens the timeline frames into normalized match events.
+// Event types that are not useful for highlights (skill level ups, pauses, ...) are dropped.
+func mapMatchTimeline(matchID int64, timeline riotmodels.MatchTimeline) []riotmodels.MatchEvent {
+	var events []riotmodels.MatchEvent
+	index := 0
+	for _, frame := range timeline.Info.Frames {
+		for _, raw := range frame.Events {
+			event, ok := mapTimelineEvent(raw)
+			if !ok {
+				continue
+			}
+			event.MatchID = matchID
+			event.EventIndex = index
+			index++
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func mapTimelineEvent(raw riotmodels.MatchTimelineEvent) (riotmodels.MatchEvent, bool) {
+	event := riotmodels.MatchEvent{Time: raw.Timestamp}
+
+	switch raw.Type {
+	case "CHAMPION_KILL":
+		event.EventType = riotmodels.MatchEventChampionKill
+		event.ParticipantID = raw.KillerID
+		event.VictimID = raw.VictimID
+		event.AssistIDs = raw.AssistingParticipantIDs
+	case "ELITE_MONSTER_KILL":
+		event.EventType = riotmodels.MatchEventEliteMonster
+		event.ParticipantID = raw.KillerID
+		event.AssistIDs = raw.AssistingParticipantIDs
+		event.TeamID = raw.KillerTeamID
+		event.Subtype = joinSubtype(raw.MonsterType, raw.MonsterSubType)
+	case "BUILDING_KILL":
+		event.EventType = riotmodels.MatchEventBuildingKill
+		event.ParticipantID = raw.KillerID
+		event.AssistIDs = raw.AssistingParticipantIDs
+		event.TeamID = raw.TeamID
+		event.Subtype = joinSubtype(raw.BuildingType, raw.TowerType, raw.LaneType)
+	case "TURRET_PLATE_DESTROYED":
+		event.EventType = riotmodels.MatchEventTurretPlate
+		event.ParticipantID = raw.KillerID
+		event.TeamID = raw.TeamID
+		event.Subtype = raw.LaneType
+	case "ITEM_PURCHASED":
+		event.EventType = riotmodels.MatchEventItemPurchased
+		event.ParticipantID = raw.ParticipantID
+		event.ItemID = raw.ItemID
+	case "LEVEL_UP":
+		event.EventType = riotmodels.MatchEventLevelUp
+		event.ParticipantID = raw.ParticipantID
+		event.Level = raw.Level
+	case "WARD_PLACED":
+		event.EventType = riotmodels.MatchEventWardPlaced
+		event.ParticipantID = raw.CreatorID
+		event.Subtype = raw.WardType
+	case "WARD_KILL":
+		event.EventType = riotmodels.MatchEventWardKill
+		event.ParticipantID = raw.KillerID
+		event.Subtype = raw.WardType
+	default:
+		return riotmodels.MatchEvent{}, false
+	}
+
+	if raw.Position != nil {
+		x, y := raw.Position.X, raw.Position.Y
+		event.PositionX = &x
+		event.PositionY = &y
+	}
+	return event, true
+}
+
+func joinSubtype(parts ...string) string {
+	subtype := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if subtype != "" {
+			subtype += ":"
+		}
+		subtype += part
+	}
+	return subtype
+}
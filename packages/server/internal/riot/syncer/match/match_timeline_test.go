@@ -0,0 +1,51 @@
+package matchsync
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapMatchTimeline(t *testing.T) {
+	raw, err := os.ReadFile("../../../../tests/data/riot/raw/match_timeline.json")
+	require.NoError(t, err)
+
+	var timeline riotmodels.MatchTimeline
+	require.NoError(t, json.Unmarshal(raw, &timeline))
+
+	events := mapMatchTimeline(timeline.Info.ID, timeline)
+
+	var types []riotmodels.MatchEventType
+	for i, event := range events {
+		assert.Equal(t, int64(7665669531), event.MatchID)
+		assert.Equal(t, i, event.EventIndex)
+		types = append(types, event.EventType)
+	}
+	assert.Equal(t, []riotmodels.MatchEventType{
+		riotmodels.MatchEventItemPurchased,
+		riotmodels.MatchEventWardPlaced,
+		riotmodels.MatchEventLevelUp,
+		riotmodels.MatchEventChampionKill,
+		riotmodels.MatchEventWardKill,
+		riotmodels.MatchEventEliteMonster,
+		riotmodels.MatchEventTurretPlate,
+		riotmodels.MatchEventBuildingKill,
+	}, types)
+
+	kill := events[3]
+	assert.Equal(t, 1, kill.ParticipantID)
+	assert.Equal(t, 6, kill.VictimID)
+	assert.Equal(t, []int{2, 5}, kill.AssistIDs)
+	assert.Equal(t, int64(95114), kill.Time)
+	require.NotNil(t, kill.PositionX)
+	assert.Equal(t, 2470, *kill.PositionX)
+
+	assert.Equal(t, 2, events[1].ParticipantID)
+	assert.Equal(t, "DRAGON:FIRE_DRAGON", events[5].Subtype)
+	assert.Equal(t, 200, events[5].TeamID)
+	assert.Equal(t, "TOWER_BUILDING:OUTER_TURRET:TOP_LANE", events[7].Subtype)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/galchammat/kadeem/internal/models"
 	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
@@ -30,108 +29,50 @@ type Result struct {
 
 	MatchSummary riotmodels.MatchSummary
 	Participants []riotmodels.MatchParticipantSummary
-	Events       []any
+	Events       []riotmodels.MatchEvent
+
+	// Err is set when the details or timeline fetch failed and the match has to be retried.
+	Err error
 }
 
-func (s *MatchSyncer) processMatches(
-	ctx context.Context,
-	fullMatchIDs []string,
-) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	jobs := make(chan Job, len(fullMatchIDs)*2)
-	results := make(chan Result, len(fullMatchIDs)*2)
-	fatalErr := make(chan error, 1)
-
-	const workerCount = 10
-	var wg sync.WaitGroup
-
-	for range workerCount {
-		wg.Add(1)
-
-		go func() {
-			defer wg.Done()
-
-			for job := range jobs {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-
-				result, err := s.processJob(ctx, job)
-				if err != nil {
-					select {
-					case fatalErr <- err:
-					default:
-					}
-					cancel()
-					return
-				}
-
-				select {
-				case results <- result:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}()
-	}
+// processMatch fetches the details and timeline of a claimed match and saves them.
+// A returned error means the match should be nacked.
+func (s *MatchSyncer) processMatch(ctx context.Context, matchID int64, region string) error {
+	fullMatchID := fmt.Sprintf("%s_%d", region, matchID)
 
-	for _, fullMatchID := range fullMatchIDs {
-		jobs <- Job{FullMatchID: fullMatchID, Op: Details}
-		// jobs <- Job{FullMatchID: fullMatchID, Op: Timeline}
+	details, err := s.processJob(ctx, Job{FullMatchID: fullMatchID, Region: region, Op: Details})
+	if err != nil {
+		return err
 	}
-	close(jobs)
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	count := len(fullMatchIDs)
-	summaries := make([]riotmodels.MatchSummary, 0, count)
-	participants := make([]riotmodels.MatchParticipantSummary, 0, count)
-	events := make([]any, 0, count)
-
-	for result := range results {
-		fmt.Println(result)
-		summaries = append(summaries, result.MatchSummary)
-		participants = append(participants, result.Participants...)
-		events = append(events, result.Events...)
+	if details.Err != nil {
+		return details.Err
 	}
 
-	select {
-	case err := <-fatalErr:
+	timeline, err := s.processJob(ctx, Job{FullMatchID: fullMatchID, Region: region, Op: Timeline})
+	if err != nil {
 		return err
-	default:
+	}
+	if timeline.Err != nil {
+		return fmt.Errorf("fetch match timeline: %w", timeline.Err)
 	}
 
-	if err := ctx.Err(); err != nil {
+	if err := s.store.SaveMatchSummaryBatch(ctx, []riotmodels.MatchSummary{details.MatchSummary}); err != nil {
 		return err
 	}
-
-	if err := s.store.SaveMatchSummaryBatch(ctx, summaries); err != nil {
+	if err := s.store.SaveMatchParticipantBatch(ctx, details.Participants); err != nil {
 		return err
 	}
-	if err := s.store.SaveMatchParticipantBatch(ctx, participants); err != nil {
+	if err := s.store.SaveMatchEventBatch(ctx, timeline.Events); err != nil {
 		return err
 	}
-	// s.store.SaveMatchEventBatch(ctx, events)
 
 	return nil
 }
 
 func (s *MatchSyncer) processJob(ctx context.Context, job Job) (Result, error) {
-	parts := strings.Split(job.FullMatchID, "_")
-	if len(parts) != 2 {
-		return Result{}, fmt.Errorf("invalid full match id %q", job.FullMatchID)
-	}
-	region, rawMatchID := parts[0], parts[1]
-	matchID, err := strconv.ParseInt(rawMatchID, 10, 64)
+	region, matchID, err := parseFullMatchID(job.FullMatchID)
 	if err != nil {
-		return Result{}, fmt.Errorf("failed to parse matchID %s. %w", rawMatchID, err)
+		return Result{}, err
 	}
 
 	result := Result{
@@ -141,21 +82,43 @@ func (s *MatchSyncer) processJob(ctx context.Context, job Job) (Result, error) {
 
 	switch job.Op {
 	case Details:
-		matchDetails, err := s.client.FetchMatchDetails(matchID, region)
+		matchDetails, err := s.client.FetchMatchDetails(ctx, matchID, region)
 		if err != nil {
 			result.MatchSummary.ID = matchID
 			result.MatchSummary.Region = region
 			result.MatchSummary.Status = models.StatusRetry
-			// ToDo - set to models.StatusDLQ if already Retry
+			result.Err = err
 		} else {
 			result.MatchSummary, result.Participants = mapMatchDetails(*matchDetails)
+			if result.MatchSummary.Region == "" {
+				result.MatchSummary.Region = region
+			}
 			result.MatchSummary.Status = models.StatusDone
 		}
 	case Timeline:
-		// Timeline processing not yet implemented
+		timeline, err := s.client.FetchMatchTimeline(ctx, matchID, region)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.Events = mapMatchTimeline(matchID, *timeline)
+		}
 	default:
 		return Result{}, fmt.Errorf("unknown op %q", job.Op)
 	}
 
 	return result, nil
 }
+
+// parseFullMatchID splits a match-v5 id such as "EUW1_7665669531" into its region and numeric id.
+func parseFullMatchID(fullMatchID string) (string, int64, error) {
+	parts := strings.Split(fullMatchID, "_")
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid full match id %q", fullMatchID)
+	}
+	region, rawMatchID := parts[0], parts[1]
+	matchID, err := strconv.ParseInt(rawMatchID, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse matchID %s. %w", rawMatchID, err)
+	}
+	return region, matchID, nil
+}
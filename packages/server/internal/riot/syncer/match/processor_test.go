@@ -2,35 +2,59 @@ package matchsync
 
 import (
 	"context"
-	"encoding/json"
-	"os"
+	"net/http"
 	"testing"
 
-	riotapi "github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/models"
+	"github.com/galchammat/kadeem/internal/riot/riottest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessDetailsJob(t *testing.T) {
-	if os.Getenv("RUN_INTEGRATION_TESTS") != "true" {
-		t.Skip("Skipping integration test; set RUN_INTEGRATION_TESTS=true to run it")
-	}
+	riot := riottest.NewServer()
+	defer riot.Close()
+	require.NoError(t, riot.LoadFixtures("../../../../tests/data/riot/raw"))
 
 	ctx := context.Background()
-	s := &MatchSyncer{client: riotapi.NewClient()}
+	s := &MatchSyncer{client: riot.Client()}
 
-	matchDetails, err := s.processJob(ctx, Job{
-		FullMatchID: "EUW1_7848931380",
+	result, err := s.processJob(ctx, Job{
+		FullMatchID: "EUW1_7665669531",
 		Region:      "EUW1",
 		Op:          Details,
 	})
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	assert.Equal(t, int64(7665669531), result.MatchSummary.ID)
+	assert.Equal(t, "EUW1", result.MatchSummary.Region)
+	assert.Equal(t, models.StatusDone, result.MatchSummary.Status)
+	assert.Len(t, result.Participants, 10)
+
+	result, err = s.processJob(ctx, Job{FullMatchID: "EUW1_7665669531", Region: "EUW1", Op: Timeline})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Events)
+}
+
+func TestProcessDetailsJobMarksMissingMatchForRetry(t *testing.T) {
+	riot := riottest.NewServer()
+	defer riot.Close()
 
-	if err != nil {
-		t.Errorf("failed to process details job: %v", err)
-	}
+	s := &MatchSyncer{client: riot.Client()}
+	result, err := s.processJob(context.Background(), Job{FullMatchID: "EUW1_1", Region: "EUW1", Op: Details})
+	require.NoError(t, err)
+	assert.Error(t, result.Err)
+	assert.Equal(t, models.StatusRetry, result.MatchSummary.Status)
+}
 
-	b, err := json.MarshalIndent(matchDetails, "", "  ")
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Log(string(b))
+func TestProcessMatchFailsOnTimelineError(t *testing.T) {
+	riot := riottest.NewServer()
+	defer riot.Close()
+	require.NoError(t, riot.LoadFixtures("../../../../tests/data/riot/raw"))
+	riot.Inject("/lol/match/v5/matches/EUW1_7665669531/timeline", riottest.ServerError(http.StatusServiceUnavailable), 0)
 
+	// The match is nacked before anything is saved, so no store is needed.
+	s := &MatchSyncer{client: riot.Client()}
+	err := s.processMatch(context.Background(), 7665669531, "EUW1")
+	assert.ErrorContains(t, err, "fetch match timeline")
 }
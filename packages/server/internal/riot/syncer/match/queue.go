@@ -0,0 +1,191 @@
+package matchsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/models"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+)
+
+// QueueConfig controls the workers that fetch details for queued matches.
+type QueueConfig struct {
+	Workers int
+	// Lease is how long a claimed match stays reserved for its worker. Matches still
+	// processing after their lease ran out are put back into the queue.
+	Lease time.Duration
+	// PollInterval is how long an idle worker waits before claiming again.
+	PollInterval time.Duration
+}
+
+var DefaultQueueConfig = QueueConfig{
+	Workers:      4,
+	Lease:        10 * time.Minute,
+	PollInterval: 15 * time.Second,
+}
+
+// RunQueue processes queued matches until ctx is cancelled. Several instances can run
+// against the same database; claims are exclusive and expired leases are reclaimed.
+func (s *MatchSyncer) RunQueue(ctx context.Context, cfg QueueConfig) {
+	cfg = cfg.withDefaults()
+	prefix := workerPrefix()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.reclaimLoop(ctx, cfg.Lease/2)
+	}()
+
+	for i := range cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.work(ctx, fmt.Sprintf("%s-%d", prefix, i), cfg, false)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Drain processes queued matches until none are left to claim.
+func (s *MatchSyncer) Drain(ctx context.Context, cfg QueueConfig) error {
+	cfg = cfg.withDefaults()
+	prefix := workerPrefix()
+
+	if _, err := s.store.ReclaimExpiredLeases(ctx, s.retry.MaxAttempts); err != nil {
+		return err
+	}
+
+	errs := make(chan error, cfg.Workers)
+	var wg sync.WaitGroup
+	for i := range cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.work(ctx, fmt.Sprintf("%s-%d", prefix, i), cfg, true); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// work claims and processes matches one at a time. When drain is set it returns as soon as
+// the queue is empty or a claim fails; otherwise it waits and keeps polling.
+func (s *MatchSyncer) work(ctx context.Context, workerID string, cfg QueueConfig, drain bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		match, err := s.store.ClaimPendingMatch(ctx, workerID, cfg.Lease)
+		if err != nil {
+			if drain {
+				return err
+			}
+			logging.Error("Failed to claim riot match", "worker", workerID, "error", err)
+		}
+		if match == nil {
+			if drain && err == nil {
+				return nil
+			}
+			if !sleep(ctx, cfg.PollInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		if err := s.processMatch(ctx, match.ID, match.Region); err != nil {
+			if ctx.Err() != nil {
+				// Shutting down; the lease expires and the reclaim puts the match back into the
+				// queue as a failed attempt.
+				return ctx.Err()
+			}
+			if err := s.nack(ctx, workerID, match.ID, match.Region, err); errors.Is(err, riot.ErrLeaseLost) {
+				logging.Warn("riot match lease lost before nack", "worker", workerID, "matchID", match.ID, "region", match.Region)
+			} else if err != nil {
+				logging.Error("Failed to nack riot match", "matchID", match.ID, "region", match.Region, "error", err)
+			}
+			continue
+		}
+		if err := s.store.AckMatch(ctx, match.ID, match.Region, workerID); errors.Is(err, riot.ErrLeaseLost) {
+			logging.Warn("riot match lease lost before ack", "worker", workerID, "matchID", match.ID, "region", match.Region)
+			continue
+		} else if err != nil {
+			logging.Error("Failed to ack riot match", "matchID", match.ID, "region", match.Region, "error", err)
+			continue
+		}
+		logging.Debug("processed riot match", "worker", workerID, "matchID", match.ID, "region", match.Region)
+	}
+}
+
+func (s *MatchSyncer) nack(ctx context.Context, workerID string, matchID int64, region string, cause error) error {
+	status, err := s.store.NackMatch(ctx, matchID, region, workerID, cause.Error(),
+		s.retry.MaxAttempts, s.retry.BaseDelay, s.retry.MaxDelay)
+	if err != nil {
+		return err
+	}
+	if status == models.StatusDLQ {
+		logging.Warn("moved riot match to dlq", "matchID", matchID, "region", region, "error", cause)
+	} else {
+		logging.Info("scheduled riot match retry", "matchID", matchID, "region", region, "error", cause)
+	}
+	return nil
+}
+
+func (s *MatchSyncer) reclaimLoop(ctx context.Context, interval time.Duration) {
+	for {
+		reclaimed, err := s.store.ReclaimExpiredLeases(ctx, s.retry.MaxAttempts)
+		if err != nil && ctx.Err() == nil {
+			logging.Error("Failed to reclaim expired riot match leases", "error", err)
+		} else if reclaimed > 0 {
+			logging.Warn("reclaimed riot matches with expired leases", "count", reclaimed)
+		}
+		if !sleep(ctx, interval) {
+			return
+		}
+	}
+}
+
+func (cfg QueueConfig) withDefaults() QueueConfig {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultQueueConfig.Workers
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultQueueConfig.Lease
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultQueueConfig.PollInterval
+	}
+	return cfg
+}
+
+// workerPrefix identifies this process in leased_by so stuck leases can be traced to an instance.
+func workerPrefix() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// sleep waits for d and reports false if ctx was cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
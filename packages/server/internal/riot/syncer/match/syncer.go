@@ -3,7 +3,9 @@ package matchsync
 import (
 	"context"
 	"fmt"
+	"time"
 
+	coremodels "github.com/galchammat/kadeem/internal/models"
 	"github.com/galchammat/kadeem/internal/riot/api"
 	"github.com/galchammat/kadeem/internal/riot/models"
 )
@@ -15,12 +17,33 @@ type MatchStore interface {
 	// match details
 	SaveMatchSummaryBatch(context.Context, []models.MatchSummary) error
 	SaveMatchParticipantBatch(context.Context, []models.MatchParticipantSummary) error
-	// SaveMatchEventBatch(context.Context, []models.MatchEvent)
+	// match timelines
+	SaveMatchEventBatch(context.Context, []models.MatchEvent) error
+	// work queue
+	EnqueueMatches(context.Context, []models.MatchSummary) (int64, error)
+	ClaimPendingMatch(ctx context.Context, workerID string, lease time.Duration) (*models.MatchSummary, error)
+	AckMatch(ctx context.Context, matchID int64, region, workerID string) error
+	NackMatch(ctx context.Context, matchID int64, region, workerID, lastError string, maxAttempts int, baseDelay, maxDelay time.Duration) (coremodels.Status, error)
+	ReclaimExpiredLeases(ctx context.Context, maxAttempts int) (int64, error)
+}
+
+// RetryPolicy controls how failed detail fetches are retried before landing in the DLQ.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   time.Minute,
+	MaxDelay:    6 * time.Hour,
 }
 
 type MatchSyncer struct {
 	client *api.Client
 	store  MatchStore
+	retry  RetryPolicy
 }
 
 func NewMatchSyncer(client *api.Client, store MatchStore) (*MatchSyncer, error) {
@@ -31,5 +54,5 @@ func NewMatchSyncer(client *api.Client, store MatchStore) (*MatchSyncer, error)
 		return nil, fmt.Errorf("match id store is nil")
 	}
 
-	return &MatchSyncer{client: client, store: store}, nil
+	return &MatchSyncer{client: client, store: store, retry: DefaultRetryPolicy}, nil
 }
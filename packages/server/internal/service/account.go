@@ -1,27 +1,28 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
 	"github.com/galchammat/kadeem/internal/logging"
 	riot "github.com/galchammat/kadeem/internal/riot/api"
 	"github.com/galchammat/kadeem/internal/riot/models"
-	riotstore "github.com/galchammat/kadeem/internal/riot/postgres"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 )
 
 type AccountService struct {
-	db   *riotstore.DB
+	db   riotstore.Store
 	riot *riot.Client
 }
 
-func NewAccountService(db *riotstore.DB, riot *riot.Client) *AccountService {
+func NewAccountService(db riotstore.Store, riot *riot.Client) *AccountService {
 	return &AccountService{db: db, riot: riot}
 }
 
 // AddAccount fetches account from Riot API and saves it.
 func (s *AccountService) AddAccount(region, gameName, tagLine string, streamerID int) error {
-	account, err := s.riot.FetchAccount(region, gameName, tagLine)
+	account, err := s.riot.FetchAccount(context.Background(), region, gameName, tagLine)
 	if err != nil {
 		return err
 	}
@@ -31,7 +32,7 @@ func (s *AccountService) AddAccount(region, gameName, tagLine string, streamerID
 
 // ReconcileAccount checks if account data has changed on Riot servers and updates DB.
 func (s *AccountService) ReconcileAccount(account *models.Account) error {
-	fetched, err := s.riot.FetchAccountByPUUID(account.Region, account.PUUID)
+	fetched, err := s.riot.FetchAccountByPUUID(context.Background(), account.Region, account.PUUID)
 	if err != nil {
 		return err
 	}
@@ -68,7 +69,7 @@ func (s *AccountService) UpdateAccount(region, gameName, tagLine, puuid string)
 		return fmt.Errorf("gameName, tagLine, region, and puuid cannot be empty")
 	}
 
-	validated, err := s.riot.FetchAccount(region, gameName, tagLine)
+	validated, err := s.riot.FetchAccount(context.Background(), region, gameName, tagLine)
 	if err != nil {
 		return err
 	}
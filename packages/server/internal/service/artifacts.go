@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/twitch/artifact"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+)
+
+const (
+	artifactBatchSize      = 20
+	artifactEventBatchSize = 100
+)
+
+// ArtifactConfig sets how artifacts are cut and downloaded.
+type ArtifactConfig struct {
+	MaxBytes    int64 // size cap of one artifact, 0 means no cap
+	MaxAttempts int   // downloads of an artifact before it is failed for good
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Timeout     time.Duration // of one download
+
+	// A highlight is a streak of at least MinKills kills with at most KillWindow between two
+	// kills. Its segment starts Lead before the first kill and ends Tail after the last.
+	Lead       time.Duration
+	Tail       time.Duration
+	KillWindow time.Duration
+	MinKills   int
+}
+
+// DefaultArtifactConfig caps artifacts at 2 GiB and retries a download five times, waiting
+// 5m after the first failure and doubling up to 6h.
+var DefaultArtifactConfig = ArtifactConfig{
+	MaxBytes:    2 << 30,
+	MaxAttempts: 5,
+	BaseDelay:   5 * time.Minute,
+	MaxDelay:    6 * time.Hour,
+	Timeout:     15 * time.Minute,
+	Lead:        20 * time.Second,
+	Tail:        10 * time.Second,
+	KillWindow:  10 * time.Second,
+	MinKills:    2,
+}
+
+// ArtifactConfigFromEnv reads ARTIFACT_MAX_BYTES, ARTIFACT_MAX_ATTEMPTS and
+// ARTIFACT_DOWNLOAD_TIMEOUT (a Go duration) on top of the defaults.
+func ArtifactConfigFromEnv() ArtifactConfig {
+	config := DefaultArtifactConfig
+	if raw := os.Getenv("ARTIFACT_MAX_BYTES"); raw != "" {
+		if maxBytes, err := strconv.ParseInt(raw, 10, 64); err == nil && maxBytes >= 0 {
+			config.MaxBytes = maxBytes
+		} else {
+			logging.Warn("Ignoring invalid ARTIFACT_MAX_BYTES", "value", raw)
+		}
+	}
+	if raw := os.Getenv("ARTIFACT_MAX_ATTEMPTS"); raw != "" {
+		if attempts, err := strconv.Atoi(raw); err == nil && attempts > 0 {
+			config.MaxAttempts = attempts
+		} else {
+			logging.Warn("Ignoring invalid ARTIFACT_MAX_ATTEMPTS", "value", raw)
+		}
+	}
+	if raw := os.Getenv("ARTIFACT_DOWNLOAD_TIMEOUT"); raw != "" {
+		if timeout, err := time.ParseDuration(raw); err == nil && timeout > 0 {
+			config.Timeout = timeout
+		} else {
+			logging.Warn("Ignoring invalid ARTIFACT_DOWNLOAD_TIMEOUT", "value", raw)
+		}
+	}
+	return config
+}
+
+// ArtifactDownloadResult counts what one DownloadPending did.
+type ArtifactDownloadResult struct {
+	Downloaded int
+	Retrying   int
+	Failed     int
+}
+
+// ArtifactService finds clips and match highlights worth keeping and downloads them into the
+// file store, next to the replays.
+type ArtifactService struct {
+	twitchDB twitchstore.Store
+	riotDB   riotstore.Store
+	files    replay.ReplayStore
+	source   artifact.Source
+	config   ArtifactConfig
+	now      func() time.Time
+}
+
+// NewArtifactService creates a new ArtifactService.
+func NewArtifactService(twitchDB twitchstore.Store, riotDB riotstore.Store, files replay.ReplayStore, source artifact.Source, config ArtifactConfig) *ArtifactService {
+	return &ArtifactService{twitchDB: twitchDB, riotDB: riotDB, files: files, source: source, config: config, now: time.Now}
+}
+
+// Discover queues the clips of Twitch channels and the VOD segments around the multikills of
+// aligned matches, and returns how many artifacts were new.
+func (s *ArtifactService) Discover() (int64, error) {
+	clips, err := s.discoverClips()
+	if err != nil {
+		return 0, err
+	}
+	highlights, err := s.discoverHighlights()
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now().Unix()
+	added, err := s.twitchDB.EnqueueArtifacts(clips, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := s.twitchDB.EnqueueArtifacts(highlights, now)
+	if err != nil {
+		return added, err
+	}
+	return added + n, nil
+}
+
+func (s *ArtifactService) discoverClips() ([]models.Artifact, error) {
+	platform := models.PlatformTwitch
+	channels, err := s.twitchDB.ListChannels(&models.ChannelFilter{Platform: &platform}, 1000, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list twitch channels: %w", err)
+	}
+
+	var artifacts []models.Artifact
+	eventType := models.StreamEventClip
+	for _, channel := range channels {
+		filter := &models.StreamEventFilter{ChannelID: &channel.ID, EventType: &eventType}
+		for offset := 0; ; offset += artifactEventBatchSize {
+			events, err := s.twitchDB.ListStreamEvents(filter, artifactEventBatchSize, offset)
+			if err != nil {
+				return nil, fmt.Errorf("list clips of channel %s: %w", channel.ID, err)
+			}
+			for _, event := range events {
+				if event.ExternalID == nil {
+					continue
+				}
+				artifacts = append(artifacts, models.Artifact{
+					Kind:      models.ArtifactClip,
+					ChannelID: event.ChannelID,
+					SourceID:  *event.ExternalID,
+					Title:     event.Title,
+				})
+			}
+			if len(events) < artifactEventBatchSize {
+				break
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+func (s *ArtifactService) discoverHighlights() ([]models.Artifact, error) {
+	kills, err := s.riotDB.ListAlignedKills()
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []models.Artifact
+	for start := 0; start < len(kills); {
+		end := start + 1
+		for end < len(kills) && kills[end].MatchID == kills[start].MatchID && kills[end].BroadcastID == kills[start].BroadcastID {
+			end++
+		}
+		artifacts = append(artifacts, s.highlights(kills[start:end])...)
+		start = end
+	}
+	return artifacts, nil
+}
+
+var multikillTitles = []string{2: "Double kill", 3: "Triple kill", 4: "Quadra kill", 5: "Penta kill"}
+
+// highlights cuts the segments around the multikills among the kills of one match on one VOD.
+// Segments are clamped to the part of the match the VOD covers, and overlapping segments are
+// merged and titled after their biggest streak.
+func (s *ArtifactService) highlights(kills []riotmodels.AlignedKill) []models.Artifact {
+	first := kills[0]
+	videoID, ok := artifact.VideoID(first.BroadcastURL)
+	if !ok {
+		return nil
+	}
+
+	type window struct {
+		start, end int64 // seconds into the match
+		kills      int
+	}
+	lead := int64(s.config.Lead / time.Second)
+	tail := int64(s.config.Tail / time.Second)
+	gap := s.config.KillWindow.Milliseconds()
+
+	var windows []window
+	for i := 0; i < len(kills); {
+		j := i + 1
+		for j < len(kills) && kills[j].Time-kills[j-1].Time <= gap {
+			j++
+		}
+		if j-i >= s.config.MinKills {
+			w := window{
+				start: max(kills[i].Time/1000-lead, first.MatchOffset),
+				end:   min(kills[j-1].Time/1000+tail, first.MatchOffset+first.Overlap),
+				kills: j - i,
+			}
+			if w.end > w.start {
+				if n := len(windows); n > 0 && windows[n-1].end >= w.start {
+					windows[n-1].end = max(windows[n-1].end, w.end)
+					windows[n-1].kills = max(windows[n-1].kills, w.kills)
+				} else {
+					windows = append(windows, w)
+				}
+			}
+		}
+		i = j
+	}
+
+	artifacts := make([]models.Artifact, 0, len(windows))
+	for _, w := range windows {
+		matchID := first.MatchID
+		artifacts = append(artifacts, models.Artifact{
+			Kind:        models.ArtifactVODSegment,
+			ChannelID:   first.ChannelID,
+			SourceID:    videoID,
+			MatchID:     &matchID,
+			StartOffset: first.VODOffset + w.start - first.MatchOffset,
+			Duration:    w.end - w.start,
+			Title:       multikillTitles[min(w.kills, len(multikillTitles)-1)],
+		})
+	}
+	return artifacts
+}
+
+// DownloadPending downloads the artifacts that are due, until none are left or ctx is done.
+// A failed download is retried with exponential backoff; after MaxAttempts, or if it is over
+// the size cap or unavailable, the artifact is failed for good.
+func (s *ArtifactService) DownloadPending(ctx context.Context) (ArtifactDownloadResult, error) {
+	var result ArtifactDownloadResult
+	for {
+		artifacts, err := s.twitchDB.ListDueArtifacts(s.now().Unix(), artifactBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(artifacts) == 0 {
+			return result, nil
+		}
+
+		for _, a := range artifacts {
+			key, size, err := s.download(ctx, a)
+			if ctx.Err() != nil {
+				// Interrupted downloads do not count as attempts.
+				return result, ctx.Err()
+			}
+			now := s.now()
+			if err == nil {
+				if err := s.twitchDB.CompleteArtifact(a.ID, key, size, now.Unix()); err != nil {
+					return result, err
+				}
+				logging.Info("Downloaded artifact", "id", a.ID, "kind", a.Kind, "source", a.SourceID, "size", size)
+				result.Downloaded++
+				continue
+			}
+
+			var next *int64
+			if a.Attempts+1 < s.config.MaxAttempts && !errors.Is(err, artifact.ErrTooLarge) && !errors.Is(err, artifact.ErrUnavailable) {
+				at := now.Add(s.retryDelay(a.Attempts)).Unix()
+				next = &at
+			}
+			if err := s.twitchDB.FailArtifact(a.ID, err.Error(), next, now.Unix()); err != nil {
+				return result, err
+			}
+			if next != nil {
+				logging.Warn("Artifact download failed, will retry", "id", a.ID, "kind", a.Kind, "source", a.SourceID, "attempt", a.Attempts+1, "error", err)
+				result.Retrying++
+			} else {
+				logging.Error("Artifact download failed", "id", a.ID, "kind", a.Kind, "source", a.SourceID, "attempt", a.Attempts+1, "error", err)
+				result.Failed++
+			}
+		}
+	}
+}
+
+// retryDelay is the wait after the given number of earlier attempts failed.
+func (s *ArtifactService) retryDelay(attempts int) time.Duration {
+	delay := s.config.BaseDelay
+	for i := 0; i < attempts && delay < s.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.config.MaxDelay)
+}
+
+// download stages the artifact on disk under the size cap, then stores it. It returns the key
+// and size of the stored file.
+func (s *ArtifactService) download(ctx context.Context, a models.Artifact) (string, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	partial := filepath.Join(artifact.StagingDir(), fmt.Sprintf("%d.part", a.ID))
+	if err := os.MkdirAll(filepath.Dir(partial), 0o755); err != nil {
+		return "", 0, err
+	}
+	f, err := os.Create(partial)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(partial) //nolint:errcheck
+	defer f.Close()
+
+	w := &artifact.LimitWriter{W: f, Max: s.config.MaxBytes}
+	switch a.Kind {
+	case models.ArtifactClip:
+		err = s.source.Clip(ctx, a.SourceID, w)
+	case models.ArtifactVODSegment:
+		err = s.source.VODSegment(ctx, a.SourceID, a.StartOffset, a.Duration, w)
+	default:
+		err = fmt.Errorf("unknown artifact kind %q", a.Kind)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return "", 0, err
+	}
+	key := artifact.Key(a)
+	if err := s.files.Put(ctx, key, f, w.Written); err != nil {
+		return "", 0, fmt.Errorf("store artifact: %w", err)
+	}
+	return key, w.Written, nil
+}
+
+// ListArtifacts returns artifacts matching the filter, newest first.
+func (s *ArtifactService) ListArtifacts(filter *models.ArtifactFilter, limit, offset int) ([]models.Artifact, error) {
+	return s.twitchDB.ListArtifacts(filter, limit, offset)
+}
+
+// CountArtifacts counts the artifacts matching the filter by status.
+func (s *ArtifactService) CountArtifacts(filter *models.ArtifactFilter) (map[models.ArtifactStatus]int, error) {
+	return s.twitchDB.CountArtifacts(filter)
+}
+
+// RetryArtifact queues a failed artifact again. It reports false if the artifact does not
+// exist or has not failed.
+func (s *ArtifactService) RetryArtifact(id int64) (bool, error) {
+	return s.twitchDB.RetryArtifact(id, s.now().Unix())
+}
+
+// OpenArtifact opens a downloaded artifact for reading. It returns replay.ErrNotFound when
+// the artifact is unknown, not downloaded yet, or the file is gone.
+func (s *ArtifactService) OpenArtifact(ctx context.Context, id int64) (*replay.Object, models.Artifact, error) {
+	artifacts, err := s.twitchDB.ListArtifacts(&models.ArtifactFilter{ID: &id}, 1, 0)
+	if err != nil {
+		return nil, models.Artifact{}, err
+	}
+	if len(artifacts) == 0 || artifacts[0].Status != models.ArtifactDownloaded {
+		return nil, models.Artifact{}, replay.ErrNotFound
+	}
+	obj, err := replay.Open(ctx, s.files, artifacts[0].StorageKey)
+	return obj, artifacts[0], err
+}
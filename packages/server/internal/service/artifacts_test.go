@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	riotmodels "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/galchammat/kadeem/internal/twitch/artifact"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource serves clips by slug, refuses RefusedClip and fails for the rest.
+type fakeSource struct {
+	clips map[string]string
+	calls int
+}
+
+func (f *fakeSource) Clip(ctx context.Context, slug string, w io.Writer) error {
+	f.calls++
+	if slug == "RefusedClip" {
+		return fmt.Errorf("%w: clip %s not found", artifact.ErrUnavailable, slug)
+	}
+	clip, ok := f.clips[slug]
+	if !ok {
+		return errors.New("clip unavailable")
+	}
+	_, err := io.Copy(w, strings.NewReader(clip))
+	return err
+}
+
+func (f *fakeSource) VODSegment(ctx context.Context, videoID string, start, duration int64, w io.Writer) error {
+	f.calls++
+	return errors.New("video unavailable")
+}
+
+func newArtifactTest(t *testing.T, source artifact.Source) (*ArtifactService, twitchstore.Store) {
+	t.Helper()
+	t.Setenv("BIN_DIR", t.TempDir())
+	db, err := platformdb.OpenSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.SQL.Close() })
+	m, err := platformdb.NewMigrate(db, "../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	store := twitchstore.New(db)
+	streamer, err := store.FindOrCreateStreamer("kadeemtest")
+	require.NoError(t, err)
+	_, err = store.SaveChannel(models.Channel{ID: "100000001", StreamerID: streamer.ID, Platform: models.PlatformTwitch, ChannelName: "KadeemTest"})
+	require.NoError(t, err)
+
+	files := replay.NewLocalStore(replay.DefaultDir())
+	s := NewArtifactService(store, riotstore.New(db), files, source, DefaultArtifactConfig)
+	return s, store
+}
+
+func TestDownloadArtifacts(t *testing.T) {
+	source := &fakeSource{clips: map[string]string{"FunnyClip": "clip bytes", "HugeClip": strings.Repeat("x", 64)}}
+	s, store := newArtifactTest(t, source)
+	s.config.MaxBytes = 32
+	s.config.MaxAttempts = 2
+	now := time.Unix(1_700_000_000, 0)
+	s.now = func() time.Time { return now }
+
+	var events []models.StreamEvent
+	for _, slug := range []string{"FunnyClip", "HugeClip", "GoneClip", "RefusedClip"} {
+		id := slug
+		events = append(events, models.StreamEvent{ChannelID: "100000001", EventType: models.StreamEventClip, Title: slug, Timestamp: 100, ExternalID: &id})
+	}
+	require.NoError(t, store.UpsertStreamEvents(events))
+
+	added, err := s.Discover()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), added)
+	added, err = s.Discover()
+	require.NoError(t, err)
+	assert.Zero(t, added)
+
+	result, err := s.DownloadPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ArtifactDownloadResult{Downloaded: 1, Retrying: 1, Failed: 2}, result)
+
+	// The retry is not due yet.
+	result, err = s.DownloadPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ArtifactDownloadResult{}, result)
+
+	now = now.Add(s.config.BaseDelay)
+	result, err = s.DownloadPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ArtifactDownloadResult{Failed: 1}, result)
+	assert.Equal(t, 5, source.calls)
+
+	counts, err := s.CountArtifacts(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ArtifactStatus]int{models.ArtifactDownloaded: 1, models.ArtifactFailed: 3}, counts)
+
+	failed := models.ArtifactFailed
+	artifacts, err := s.ListArtifacts(&models.ArtifactFilter{Status: &failed}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 3)
+	for _, a := range artifacts {
+		switch a.SourceID {
+		case "HugeClip":
+			assert.Equal(t, 1, a.Attempts)
+			assert.Contains(t, a.LastError, "size cap")
+		case "RefusedClip":
+			assert.Equal(t, 1, a.Attempts)
+			assert.Contains(t, a.LastError, "unavailable")
+		case "GoneClip":
+			assert.Equal(t, 2, a.Attempts)
+			assert.Equal(t, "clip unavailable", a.LastError)
+		}
+	}
+
+	downloaded := models.ArtifactDownloaded
+	artifacts, err = s.ListArtifacts(&models.ArtifactFilter{Status: &downloaded}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	obj, a, err := s.OpenArtifact(context.Background(), artifacts[0].ID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	obj.Close()
+	assert.Equal(t, "clip bytes", string(content))
+	assert.Equal(t, int64(10), a.Size)
+	assert.Equal(t, "artifacts/clips/FunnyClip.mp4", a.StorageKey)
+
+	// A failed artifact can be queued again, a downloaded one cannot.
+	source.clips["GoneClip"] = "found it"
+	for _, a := range append(artifacts, models.Artifact{ID: 999}) {
+		ok, err := s.RetryArtifact(a.ID)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+	for _, a := range mustList(t, s, &models.ArtifactFilter{Status: &failed}) {
+		if a.SourceID == "GoneClip" {
+			ok, err := s.RetryArtifact(a.ID)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+	}
+	result, err = s.DownloadPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ArtifactDownloadResult{Downloaded: 1}, result)
+}
+
+func mustList(t *testing.T, s *ArtifactService, filter *models.ArtifactFilter) []models.Artifact {
+	t.Helper()
+	artifacts, err := s.ListArtifacts(filter, 100, 0)
+	require.NoError(t, err)
+	return artifacts
+}
+
+func TestHighlights(t *testing.T) {
+	s := &ArtifactService{config: DefaultArtifactConfig}
+	kill := func(seconds int64) riotmodels.AlignedKill {
+		return riotmodels.AlignedKill{
+			MatchID:      1,
+			ChannelID:    "100000001",
+			BroadcastURL: "https://www.twitch.tv/videos/123",
+			VODOffset:    3600,
+			MatchOffset:  0,
+			Overlap:      1800,
+			Time:         seconds * 1000,
+		}
+	}
+
+	// A double kill clamped to the start of the match, a single kill, a quadra kill that
+	// overlaps the double kill after it, and a quadra kill clamped to the end of the VOD.
+	artifacts := s.highlights([]riotmodels.AlignedKill{
+		kill(5), kill(12),
+		kill(300),
+		kill(600), kill(608), kill(615), kill(622),
+		kill(645), kill(650),
+		kill(1785), kill(1790), kill(1795), kill(1799),
+	})
+	require.Len(t, artifacts, 3)
+
+	assert.Equal(t, "Double kill", artifacts[0].Title)
+	assert.Equal(t, "123", artifacts[0].SourceID)
+	assert.Equal(t, int64(3600), artifacts[0].StartOffset)
+	assert.Equal(t, int64(22), artifacts[0].Duration)
+	assert.Equal(t, int64(1), *artifacts[0].MatchID)
+
+	assert.Equal(t, "Quadra kill", artifacts[1].Title)
+	assert.Equal(t, int64(3600+580), artifacts[1].StartOffset)
+	assert.Equal(t, int64(660-580), artifacts[1].Duration)
+
+	assert.Equal(t, "Quadra kill", artifacts[2].Title)
+	assert.Equal(t, int64(3600+1765), artifacts[2].StartOffset)
+	assert.Equal(t, int64(1800-1765), artifacts[2].Duration)
+
+	// Matches on a YouTube VOD have no Twitch video to cut.
+	youtube := kill(5)
+	youtube.BroadcastURL = "https://www.youtube.com/watch?v=abc"
+	assert.Empty(t, s.highlights([]riotmodels.AlignedKill{youtube, youtube}))
+}
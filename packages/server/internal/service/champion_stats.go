@@ -0,0 +1,113 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// remakeDuration is the match length in seconds under which a match counts as a remake.
+// Remakes are left out of champion stats.
+const remakeDuration = 300
+
+// ChampionStatsService computes how accounts perform on their champions.
+type ChampionStatsService struct {
+	db riotstore.Store
+}
+
+// NewChampionStatsService creates a new ChampionStatsService.
+func NewChampionStatsService(db riotstore.Store) *ChampionStatsService {
+	return &ChampionStatsService{db: db}
+}
+
+// Refresh adds the account's matches that were synced since the last refresh to its
+// champion stats and returns how many were added.
+func (s *ChampionStatsService) Refresh(ctx context.Context, puuid string) (int64, error) {
+	return s.db.RefreshChampionStats(ctx, puuid, remakeDuration)
+}
+
+// ChampionStats returns the account's stats per champion, lane and queue, most played first.
+// When the refresh fails, the stats counted so far are returned.
+func (s *ChampionStatsService) ChampionStats(ctx context.Context, puuid string, filter *models.ChampionStatsFilter) ([]models.ChampionStats, error) {
+	if _, err := s.Refresh(ctx, puuid); err != nil {
+		logging.Warn("Serving champion stats without refreshing", "puuid", puuid, "error", err)
+	}
+	totals, err := s.db.ListChampionStats(ctx, puuid, filter)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeChampionStats(totals, filter), nil
+}
+
+// summarizeChampionStats sums the totals of the patches inside the filter's patch window and
+// turns them into averages and rates.
+func summarizeChampionStats(totals []models.ChampionStatTotals, filter *models.ChampionStatsFilter) []models.ChampionStats {
+	type key struct {
+		championID int
+		lane       string
+		queueID    int
+	}
+	merged := make(map[key]*models.ChampionStatTotals)
+	for _, t := range totals {
+		if filter != nil && filter.PatchMin != nil && (t.Patch == "" || models.ComparePatches(t.Patch, *filter.PatchMin) < 0) {
+			continue
+		}
+		if filter != nil && filter.PatchMax != nil && (t.Patch == "" || models.ComparePatches(t.Patch, *filter.PatchMax) > 0) {
+			continue
+		}
+		k := key{t.ChampionID, t.Lane, t.QueueID}
+		m, ok := merged[k]
+		if !ok {
+			m = &models.ChampionStatTotals{ChampionID: t.ChampionID, Lane: t.Lane, QueueID: t.QueueID}
+			merged[k] = m
+		}
+		m.Games += t.Games
+		m.Wins += t.Wins
+		m.Kills += t.Kills
+		m.Deaths += t.Deaths
+		m.Assists += t.Assists
+		m.CS += t.CS
+		m.Damage += t.Damage
+		m.ChampLevels += t.ChampLevels
+		m.Duration += t.Duration
+	}
+
+	stats := make([]models.ChampionStats, 0, len(merged))
+	for _, t := range merged {
+		games := float64(t.Games)
+		minutes := float64(t.Duration) / 60
+		stats = append(stats, models.ChampionStats{
+			ChampionID:    t.ChampionID,
+			Lane:          t.Lane,
+			QueueID:       t.QueueID,
+			Games:         t.Games,
+			Wins:          t.Wins,
+			WinRate:       round2(float64(t.Wins) / games),
+			Kills:         round2(float64(t.Kills) / games),
+			Deaths:        round2(float64(t.Deaths) / games),
+			Assists:       round2(float64(t.Assists) / games),
+			KDA:           round2(float64(t.Kills+t.Assists) / float64(max(t.Deaths, 1))),
+			CSPerMin:      round2(float64(t.CS) / minutes),
+			DamagePerMin:  round2(float64(t.Damage) / minutes),
+			AvgChampLevel: round2(float64(t.ChampLevels) / games),
+		})
+	}
+	slices.SortFunc(stats, func(a, b models.ChampionStats) int {
+		return cmp.Or(
+			cmp.Compare(b.Games, a.Games),
+			cmp.Compare(a.ChampionID, b.ChampionID),
+			cmp.Compare(a.QueueID, b.QueueID),
+			cmp.Compare(a.Lane, b.Lane),
+		)
+	})
+	return stats
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
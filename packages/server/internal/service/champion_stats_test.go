@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeChampionStats(t *testing.T) {
+	totals := []models.ChampionStatTotals{
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 2, Wins: 1, Kills: 12, Deaths: 8, Assists: 8,
+			CS: 390, Damage: 60_000, ChampLevels: 31, Duration: 3000},
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.10", Games: 1, Wins: 1, Kills: 3, Deaths: 0, Assists: 4,
+			CS: 210, Damage: 30_000, ChampLevels: 16, Duration: 1500},
+		{ChampionID: 157, Lane: "MIDDLE", QueueID: 420, Patch: "15.8", Games: 1, Duration: 1800},
+		{ChampionID: 157, Lane: "MIDDLE", QueueID: 440, Patch: "", Games: 1, Duration: 1800},
+	}
+
+	stats := summarizeChampionStats(totals, nil)
+	assert.Len(t, stats, 3)
+	assert.Equal(t, models.ChampionStats{
+		ChampionID:    103,
+		Lane:          "MIDDLE",
+		QueueID:       420,
+		Games:         3,
+		Wins:          2,
+		WinRate:       0.67,
+		Kills:         5,
+		Deaths:        2.67,
+		Assists:       4,
+		KDA:           3.38,
+		CSPerMin:      8,
+		DamagePerMin:  1200,
+		AvgChampLevel: 15.67,
+	}, stats[0])
+	assert.Equal(t, 420, stats[1].QueueID)
+	assert.Equal(t, 440, stats[2].QueueID)
+
+	// Patches compare numerically, and matches without a patch are outside any patch window.
+	patchMin := "15.9"
+	stats = summarizeChampionStats(totals, &models.ChampionStatsFilter{PatchMin: &patchMin})
+	assert.Len(t, stats, 1)
+	assert.Equal(t, 3, stats[0].Games)
+
+	patchMax := "15.9"
+	stats = summarizeChampionStats(totals, &models.ChampionStatsFilter{PatchMin: &patchMin, PatchMax: &patchMax})
+	assert.Len(t, stats, 1)
+	assert.Equal(t, 2, stats[0].Games)
+	assert.Equal(t, 7.8, stats[0].CSPerMin)
+}
+
+func TestPatch(t *testing.T) {
+	assert.Equal(t, "15.24", models.Patch("15.24.734.7485"))
+	assert.Equal(t, "", models.Patch("unknown"))
+	assert.Equal(t, -1, models.ComparePatches("15.9", "15.10"))
+	assert.Equal(t, 1, models.ComparePatches("16.1", "15.24"))
+	assert.Equal(t, 0, models.ComparePatches("15.9", "15.9"))
+}
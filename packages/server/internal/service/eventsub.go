@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+)
+
+// EventSubConfig is where Twitch delivers EventSub notifications and the secret it signs
+// them with.
+type EventSubConfig struct {
+	CallbackURL string
+	Secret      string
+}
+
+// EventSubConfigFromEnv reads TWITCH_EVENTSUB_CALLBACK_URL and TWITCH_EVENTSUB_SECRET.
+func EventSubConfigFromEnv() EventSubConfig {
+	return EventSubConfig{
+		CallbackURL: os.Getenv("TWITCH_EVENTSUB_CALLBACK_URL"),
+		Secret:      os.Getenv("TWITCH_EVENTSUB_SECRET"),
+	}
+}
+
+// Enabled reports whether subscriptions can be created: Twitch needs both a callback and a
+// secret of 10 to 100 characters.
+func (c EventSubConfig) Enabled() bool {
+	return c.CallbackURL != "" && len(c.Secret) >= 10 && len(c.Secret) <= 100
+}
+
+// eventSubTypes are the subscriptions kept for every tracked Twitch channel.
+var eventSubTypes = []struct{ Type, Version string }{
+	{models.EventSubStreamOnline, "1"},
+	{models.EventSubStreamOffline, "1"},
+	{models.EventSubChannelUpdate, "2"},
+}
+
+// EventSubSyncResult counts what SyncSubscriptions changed.
+type EventSubSyncResult struct {
+	Created int `json:"created"`
+	Deleted int `json:"deleted"`
+	Kept    int `json:"kept"`
+}
+
+// EventSubService keeps the EventSub subscriptions of tracked channels and records the
+// notifications Twitch delivers for them.
+type EventSubService struct {
+	db     twitchstore.Store
+	twitch *twitchapi.TwitchClient
+	config EventSubConfig
+}
+
+// NewEventSubService creates a new EventSubService.
+func NewEventSubService(db twitchstore.Store, twitchClient *twitchapi.TwitchClient, config EventSubConfig) *EventSubService {
+	return &EventSubService{db: db, twitch: twitchClient, config: config}
+}
+
+// HandleNotification persists an EventSub notification as a live session change and a
+// stream event. Start and end events are keyed by stream like the poller's, others by
+// message ID, so a redelivered message is stored once. Notifications for channels that are
+// no longer tracked are dropped.
+func (s *EventSubService) HandleNotification(messageID string, sent time.Time, msg models.EventSubMessage) error {
+	channelID := msg.Subscription.Condition.BroadcasterUserID
+	channels, err := s.db.ListChannels(&models.ChannelFilter{ID: &channelID}, 1, 0)
+	if err != nil {
+		return fmt.Errorf("look up channel %s: %w", channelID, err)
+	}
+	if len(channels) == 0 {
+		logging.Info("Dropping EventSub notification for unknown channel", "channel_id", channelID, "type", msg.Subscription.Type)
+		return nil
+	}
+
+	event := models.StreamEvent{
+		ChannelID:  channelID,
+		Timestamp:  sent.Unix(),
+		ExternalID: &messageID,
+	}
+
+	switch msg.Subscription.Type {
+	case models.EventSubStreamOnline:
+		var online models.StreamOnlineEvent
+		if err := json.Unmarshal(msg.Event, &online); err != nil {
+			return fmt.Errorf("decode stream.online event: %w", err)
+		}
+		if startedAt, err := time.Parse(time.RFC3339, online.StartedAt); err == nil {
+			event.Timestamp = startedAt.Unix()
+		}
+		if _, err := s.db.StartLiveSession(models.LiveSession{
+			ChannelID: channelID,
+			StreamID:  online.ID,
+			StartedAt: event.Timestamp,
+		}); err != nil {
+			return fmt.Errorf("start live session for channel %s: %w", channelID, err)
+		}
+		event.EventType = models.StreamEventOnline
+		event.Title = "Went live"
+		event.Value = &online.ID
+		event.ExternalID = liveEventID(models.StreamEventOnline, online.ID)
+
+	case models.EventSubStreamOffline:
+		live := true
+		sessions, err := s.db.ListLiveSessions(&models.LiveSessionFilter{ChannelID: &channelID, Live: &live}, 1, 0)
+		if err != nil {
+			return fmt.Errorf("find live session for channel %s: %w", channelID, err)
+		}
+		if _, err := s.db.EndLiveSession(channelID, event.Timestamp); err != nil {
+			return fmt.Errorf("end live session for channel %s: %w", channelID, err)
+		}
+		event.EventType = models.StreamEventOffline
+		event.Title = "Went offline"
+		if len(sessions) > 0 {
+			event.Value = &sessions[0].StreamID
+			event.ExternalID = liveEventID(models.StreamEventOffline, sessions[0].StreamID)
+		}
+
+	case models.EventSubChannelUpdate:
+		var update models.ChannelUpdateEvent
+		if err := json.Unmarshal(msg.Event, &update); err != nil {
+			return fmt.Errorf("decode channel.update event: %w", err)
+		}
+		if _, err := s.db.UpdateLiveSession(channelID, update.Title, update.CategoryName); err != nil {
+			return fmt.Errorf("update live session for channel %s: %w", channelID, err)
+		}
+		event.EventType = models.StreamEventUpdate
+		event.Title = update.Title
+		event.Description = update.CategoryName
+		event.Value = &update.CategoryName
+
+	default:
+		logging.Warn("Ignoring unsupported EventSub notification", "type", msg.Subscription.Type, "channel_id", channelID)
+		return nil
+	}
+
+	if err := s.db.UpsertStreamEvents([]models.StreamEvent{event}); err != nil {
+		return fmt.Errorf("store %s event for channel %s: %w", msg.Subscription.Type, channelID, err)
+	}
+	return nil
+}
+
+// ListSubscriptions returns the app's EventSub subscriptions.
+func (s *EventSubService) ListSubscriptions() ([]models.EventSubSubscription, error) {
+	return s.twitch.ListEventSubSubscriptions()
+}
+
+// DeleteSubscription removes an EventSub subscription.
+func (s *EventSubService) DeleteSubscription(id string) error {
+	return s.twitch.DeleteEventSubSubscription(id)
+}
+
+// SyncSubscriptions makes the subscriptions to our callback match the tracked Twitch
+// channels: missing ones are created, and those of untracked channels or that Twitch
+// disabled (revoked, failed verification, ...) are deleted so they can be recreated.
+func (s *EventSubService) SyncSubscriptions() (EventSubSyncResult, error) {
+	var result EventSubSyncResult
+	if !s.config.Enabled() {
+		return result, fmt.Errorf("eventsub is not configured: set TWITCH_EVENTSUB_CALLBACK_URL and a 10-100 character TWITCH_EVENTSUB_SECRET")
+	}
+
+	platform := models.PlatformTwitch
+	channels, err := s.db.ListChannels(&models.ChannelFilter{Platform: &platform}, 1000, 0)
+	if err != nil {
+		return result, fmt.Errorf("list twitch channels: %w", err)
+	}
+	tracked := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		tracked[ch.ID] = true
+	}
+
+	subscriptions, err := s.twitch.ListEventSubSubscriptions()
+	if err != nil {
+		return result, err
+	}
+
+	type key struct{ subType, channelID string }
+	have := make(map[key]bool)
+	for _, sub := range subscriptions {
+		if sub.Transport.Method != "webhook" || sub.Transport.Callback != s.config.CallbackURL {
+			continue
+		}
+		k := key{sub.Type, sub.Condition.BroadcasterUserID}
+		active := sub.Status == "enabled" || sub.Status == "webhook_callback_verification_pending"
+		if active && tracked[k.channelID] && !have[k] {
+			have[k] = true
+			result.Kept++
+			continue
+		}
+		if err := s.twitch.DeleteEventSubSubscription(sub.ID); err != nil {
+			return result, err
+		}
+		logging.Info("Deleted EventSub subscription", "id", sub.ID, "type", sub.Type, "channel_id", k.channelID, "status", sub.Status)
+		result.Deleted++
+	}
+
+	for _, ch := range channels {
+		for _, t := range eventSubTypes {
+			if have[key{t.Type, ch.ID}] {
+				continue
+			}
+			sub, err := s.twitch.CreateEventSubSubscription(t.Type, t.Version, ch.ID, s.config.CallbackURL, s.config.Secret)
+			if err != nil {
+				return result, fmt.Errorf("subscribe channel %s: %w", ch.ID, err)
+			}
+			logging.Info("Created EventSub subscription", "id", sub.ID, "type", sub.Type, "channel_id", ch.ID)
+			result.Created++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/streaming"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+)
+
+const defaultLivePollInterval = 5 * time.Minute
+
+// LivePollInterval reads LIVE_POLL_INTERVAL (a Go duration), the time between two polls
+// and so between two viewer samples. It defaults to 5m.
+func LivePollInterval() time.Duration {
+	if raw := os.Getenv("LIVE_POLL_INTERVAL"); raw != "" {
+		if interval, err := time.ParseDuration(raw); err == nil && interval > 0 {
+			return interval
+		}
+		logging.Warn("Ignoring invalid LIVE_POLL_INTERVAL", "value", raw)
+	}
+	return defaultLivePollInterval
+}
+
+// liveEventID keys the start and end events of a stream by its stream ID, so the EventSub
+// receiver and the poller record each of them once.
+func liveEventID(eventType models.StreamEventType, streamID string) *string {
+	id := string(eventType) + ":" + streamID
+	return &id
+}
+
+// LivePollResult counts what one Poll saw.
+type LivePollResult struct {
+	Live    int
+	Started int
+	Ended   int
+}
+
+// LiveSessionService records the live sessions of tracked channels and their viewer counts.
+type LiveSessionService struct {
+	db        twitchstore.Store
+	platforms *streaming.Registry
+	now       func() time.Time
+}
+
+// NewLiveSessionService creates a new LiveSessionService.
+func NewLiveSessionService(db twitchstore.Store, platforms *streaming.Registry) *LiveSessionService {
+	return &LiveSessionService{db: db, platforms: platforms, now: time.Now}
+}
+
+// Poll asks every platform which of its tracked channels are live. Live channels get their
+// session started or updated and a viewer sample; title and category changes become stream
+// events. Open sessions of channels that are no longer live are ended. A platform that fails
+// does not keep the others from being polled.
+func (s *LiveSessionService) Poll() (LivePollResult, error) {
+	var result LivePollResult
+	var errs []error
+	for _, platform := range s.platforms.All() {
+		r, err := s.pollPlatform(platform)
+		result.Live += r.Live
+		result.Started += r.Started
+		result.Ended += r.Ended
+		if err != nil {
+			errs = append(errs, fmt.Errorf("poll %s: %w", platform.Name(), err))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func (s *LiveSessionService) pollPlatform(platform streaming.StreamingPlatform) (LivePollResult, error) {
+	var result LivePollResult
+	name := platform.Name()
+	channels, err := s.db.ListChannels(&models.ChannelFilter{Platform: &name}, 1000, 0)
+	if err != nil {
+		return result, fmt.Errorf("list %s channels: %w", name, err)
+	}
+	if len(channels) == 0 {
+		return result, nil
+	}
+	channelIDs := make([]string, 0, len(channels))
+	tracked := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		channelIDs = append(channelIDs, ch.ID)
+		tracked[ch.ID] = true
+	}
+
+	streams, err := platform.FetchLiveStreams(channelIDs)
+	if err != nil {
+		return result, err
+	}
+
+	live := true
+	openSessions, err := s.db.ListLiveSessions(&models.LiveSessionFilter{Live: &live}, 1000, 0)
+	if err != nil {
+		return result, fmt.Errorf("list open live sessions: %w", err)
+	}
+	open := make(map[string]models.LiveSession, len(openSessions))
+	for _, ls := range openSessions {
+		if tracked[ls.ChannelID] {
+			open[ls.ChannelID] = ls
+		}
+	}
+
+	now := s.now().Unix()
+	var events []models.StreamEvent
+	for _, stream := range streams {
+		result.Live++
+		startedAt := now
+		if t, err := time.Parse(time.RFC3339, stream.StartedAt); err == nil {
+			startedAt = t.Unix()
+		}
+
+		prev, wasOpen := open[stream.UserID]
+		delete(open, stream.UserID)
+		if wasOpen && prev.StreamID != stream.ID {
+			// StartLiveSession closes the previous stream; record that it ended.
+			events = append(events, offlineEvent(prev, startedAt))
+			result.Ended++
+		}
+
+		sessionID, err := s.db.StartLiveSession(models.LiveSession{
+			ChannelID: stream.UserID,
+			StreamID:  stream.ID,
+			Title:     stream.Title,
+			Category:  stream.GameName,
+			StartedAt: startedAt,
+		})
+		if err != nil {
+			return result, fmt.Errorf("start live session for channel %s: %w", stream.UserID, err)
+		}
+
+		switch {
+		case !wasOpen || prev.StreamID != stream.ID:
+			result.Started++
+			events = append(events, models.StreamEvent{
+				ChannelID:   stream.UserID,
+				EventType:   models.StreamEventOnline,
+				Title:       "Went live",
+				Description: stream.GameName,
+				Timestamp:   startedAt,
+				Value:       &stream.ID,
+				ExternalID:  liveEventID(models.StreamEventOnline, stream.ID),
+			})
+		case prev.Title == "" && prev.Category == "":
+			// The session was started by EventSub's stream.online, which carries no title or
+			// category; StartLiveSession just filled them in, so nothing changed.
+		case prev.Title != stream.Title || prev.Category != stream.GameName:
+			externalID := fmt.Sprintf("%s:%s:%d", models.StreamEventUpdate, stream.ID, now)
+			events = append(events, models.StreamEvent{
+				ChannelID:   stream.UserID,
+				EventType:   models.StreamEventUpdate,
+				Title:       stream.Title,
+				Description: stream.GameName,
+				Timestamp:   now,
+				Value:       &stream.GameName,
+				ExternalID:  &externalID,
+			})
+		}
+
+		if err := s.db.AddViewerSample(models.ViewerSample{SessionID: sessionID, Timestamp: now, Viewers: stream.ViewerCount}); err != nil {
+			return result, err
+		}
+	}
+
+	for channelID, ls := range open {
+		if _, err := s.db.EndLiveSession(channelID, now); err != nil {
+			return result, fmt.Errorf("end live session for channel %s: %w", channelID, err)
+		}
+		events = append(events, offlineEvent(ls, now))
+		result.Ended++
+	}
+
+	if err := s.db.UpsertStreamEvents(events); err != nil {
+		return result, fmt.Errorf("store live events: %w", err)
+	}
+	return result, nil
+}
+
+func offlineEvent(ls models.LiveSession, endedAt int64) models.StreamEvent {
+	return models.StreamEvent{
+		ChannelID:  ls.ChannelID,
+		EventType:  models.StreamEventOffline,
+		Title:      "Went offline",
+		Timestamp:  endedAt,
+		Value:      &ls.StreamID,
+		ExternalID: liveEventID(models.StreamEventOffline, ls.StreamID),
+	}
+}
+
+// ListLiveSessions returns the channel's live sessions, newest first.
+func (s *LiveSessionService) ListLiveSessions(channelID string, limit, offset int) ([]models.LiveSession, error) {
+	return s.db.ListLiveSessions(&models.LiveSessionFilter{ChannelID: &channelID}, limit, offset)
+}
+
+// ViewerSeries returns a session of the channel with its viewer samples, oldest first. The
+// session is nil if the channel has no session with that ID.
+func (s *LiveSessionService) ViewerSeries(channelID string, sessionID int64) (*models.LiveSession, []models.ViewerSample, error) {
+	sessions, err := s.db.ListLiveSessions(&models.LiveSessionFilter{ID: &sessionID, ChannelID: &channelID}, 1, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil, nil
+	}
+	samples, err := s.db.ListViewerSamples(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &sessions[0], samples, nil
+}
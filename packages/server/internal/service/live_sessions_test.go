@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/streaming"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
+	"github.com/galchammat/kadeem/internal/twitch/twitchtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLiveSessionTest(t *testing.T) (*LiveSessionService, twitchstore.Store, *twitchtest.Server) {
+	t.Helper()
+	db, err := platformdb.OpenSQLite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.SQL.Close() })
+	m, err := platformdb.NewMigrate(db, "../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	store := twitchstore.New(db)
+	streamer, err := store.FindOrCreateStreamer("kadeemtest")
+	require.NoError(t, err)
+	_, err = store.SaveChannel(models.Channel{ID: "100000001", StreamerID: streamer.ID, Platform: "twitch", ChannelName: "KadeemTest"})
+	require.NoError(t, err)
+
+	fake := twitchtest.NewServer()
+	t.Cleanup(fake.Close)
+	require.NoError(t, fake.LoadFixtures("../../tests/data/twitch"))
+	return NewLiveSessionService(store, streaming.NewRegistry(fake.Client(context.Background()))), store, fake
+}
+
+func TestPollLiveSessions(t *testing.T) {
+	s, store, fake := newLiveSessionTest(t)
+	now := time.Date(2026, 1, 7, 19, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	result, err := s.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, LivePollResult{Live: 1, Started: 1}, result)
+
+	// Same stream, new title and more viewers.
+	now = now.Add(5 * time.Minute)
+	fake.Clear(twitchtest.Streams)
+	require.NoError(t, fake.Add(twitchtest.Streams, json.RawMessage(`{"id":"40000000007","user_id":"100000001","user_login":"kadeemtest",
+		"game_name":"League of Legends","type":"live","title":"pentakill incoming","viewer_count":5000,"started_at":"2026-01-07T18:00:03Z"}`)))
+	result, err = s.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, LivePollResult{Live: 1}, result)
+
+	now = now.Add(5 * time.Minute)
+	fake.Clear(twitchtest.Streams)
+	result, err = s.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, LivePollResult{Ended: 1}, result)
+
+	sessions, err := s.ListLiveSessions("100000001", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	session := sessions[0]
+	assert.Equal(t, "40000000007", session.StreamID)
+	assert.Equal(t, "pentakill incoming", session.Title)
+	assert.Equal(t, "League of Legends", session.Category)
+	assert.Equal(t, time.Date(2026, 1, 7, 18, 0, 3, 0, time.UTC).Unix(), session.StartedAt)
+	require.NotNil(t, session.EndedAt)
+	assert.Equal(t, now.Unix(), *session.EndedAt)
+
+	got, samples, err := s.ViewerSeries("100000001", session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, []models.ViewerSample{
+		{SessionID: session.ID, Timestamp: now.Add(-10 * time.Minute).Unix(), Viewers: 4321},
+		{SessionID: session.ID, Timestamp: now.Add(-5 * time.Minute).Unix(), Viewers: 5000},
+	}, samples)
+
+	got, _, err = s.ViewerSeries("100000002", session.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	channelID := "100000001"
+	events, err := store.ListStreamEvents(&models.StreamEventFilter{ChannelID: &channelID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, models.StreamEventOffline, events[0].EventType)
+	assert.Equal(t, models.StreamEventUpdate, events[1].EventType)
+	assert.Equal(t, "pentakill incoming", events[1].Title)
+	assert.Equal(t, models.StreamEventOnline, events[2].EventType)
+}
+
+func TestPollLiveSessionsAfterMissedOffline(t *testing.T) {
+	s, store, fake := newLiveSessionTest(t)
+
+	_, err := s.Poll()
+	require.NoError(t, err)
+
+	// The channel restarted its stream between two polls.
+	fake.Clear(twitchtest.Streams)
+	require.NoError(t, fake.Add(twitchtest.Streams, json.RawMessage(`{"id":"40000000008","user_id":"100000001","user_login":"kadeemtest",
+		"game_name":"League of Legends","type":"live","title":"back again","viewer_count":100,"started_at":"2026-01-07T23:00:00Z"}`)))
+	result, err := s.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, LivePollResult{Live: 1, Started: 1, Ended: 1}, result)
+
+	live := true
+	sessions, err := store.ListLiveSessions(&models.LiveSessionFilter{Live: &live}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "40000000008", sessions[0].StreamID)
+}
+
+func TestPollLiveSessionsFillsEventSubSession(t *testing.T) {
+	s, store, _ := newLiveSessionTest(t)
+
+	// stream.online starts the session without a title or category.
+	_, err := store.StartLiveSession(models.LiveSession{ChannelID: "100000001", StreamID: "40000000007", StartedAt: 1767808803})
+	require.NoError(t, err)
+
+	result, err := s.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, LivePollResult{Live: 1}, result)
+
+	channelID := "100000001"
+	sessions, err := s.ListLiveSessions(channelID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "League of Legends", sessions[0].Category)
+	assert.Equal(t, "ranked grind day 7", sessions[0].Title)
+
+	events, err := store.ListStreamEvents(&models.StreamEventFilter{ChannelID: &channelID}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// rankedTiers are the ranked tiers from lowest to highest. Master and above have no divisions
+// and share one LP pool.
+var rankedTiers = []string{
+	"IRON", "BRONZE", "SILVER", "GOLD", "PLATINUM", "EMERALD", "DIAMOND", "MASTER", "GRANDMASTER", "CHALLENGER",
+}
+
+// rankedDivisions are the divisions of a tier from lowest to highest.
+var rankedDivisions = []string{"IV", "III", "II", "I"}
+
+// apexTier is the index of the lowest tier without divisions.
+const apexTier = 7
+
+// LPService links the LP changes between rank snapshots to the ranked games that caused them.
+type LPService struct {
+	db riotstore.Store
+}
+
+// NewLPService creates a new LPService.
+func NewLPService(db riotstore.Store) *LPService {
+	return &LPService{db: db}
+}
+
+// MatchLPChanges returns the LP change of each of the account's games in the ranked queue that
+// started between from and to (unix seconds), oldest first. Games that are not bracketed by
+// two rank snapshots are left out.
+func (s *LPService) MatchLPChanges(ctx context.Context, puuid string, queueID int, from, to int64) ([]models.MatchLPChange, error) {
+	ranks, err := s.db.ListPlayerRanks(ctx, puuid, queueID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	games, err := s.db.ListRankedGames(ctx, puuid, queueID, remakeDuration, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return attributeLP(ranks, games), nil
+}
+
+// LPSeries returns the account's rank in the ranked queue between from and to (unix seconds)
+// as points for an LP-over-time chart, oldest first.
+func (s *LPService) LPSeries(ctx context.Context, puuid string, queueID int, from, to int64) ([]models.LPPoint, error) {
+	ranks, err := s.db.ListPlayerRanks(ctx, puuid, queueID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return lpSeries(ranks), nil
+}
+
+// attributeLP splits the LP change between every two consecutive snapshots over the games
+// that ended between them. Both ranks and games are sorted oldest first. Rank timestamps are
+// unix seconds and game times unix milliseconds.
+func attributeLP(ranks []models.PlayerRank, games []models.RankedGame) []models.MatchLPChange {
+	var changes []models.MatchLPChange
+	g := 0
+	for i := 1; i < len(ranks); i++ {
+		before, after := ranks[i-1], ranks[i]
+		for g < len(games) && games[g].EndedAt() <= before.Timestamp*1000 {
+			g++
+		}
+		start := g
+		for g < len(games) && games[g].EndedAt() <= after.Timestamp*1000 {
+			g++
+		}
+		changes = append(changes, splitLPChange(before, after, games[start:g])...)
+	}
+	return changes
+}
+
+// splitLPChange attributes the LP change from before to after to games. A single game gets the
+// whole change. Several games share it as lpPerGame splits it, and are marked approximate, as
+// is a game whose snapshots count a different number of games played. A promotion goes to the
+// last win and a demotion to the last loss.
+func splitLPChange(before, after models.PlayerRank, games []models.RankedGame) []models.MatchLPChange {
+	if len(games) == 0 {
+		return nil
+	}
+	pointsBefore, ok := ladderPoints(before)
+	if !ok {
+		return nil
+	}
+	pointsAfter, ok := ladderPoints(after)
+	if !ok {
+		return nil
+	}
+	delta := pointsAfter - pointsBefore
+	played := after.Wins + after.Losses - before.Wins - before.Losses
+	approximate := len(games) > 1 || played != 1
+
+	wins := 0
+	for _, game := range games {
+		if game.Win {
+			wins++
+		}
+	}
+	losses := len(games) - wins
+	perWin, perLoss := lpPerGame(delta, wins, losses)
+
+	promoted, demoted := false, false
+	if c := compareRanks(after, before); c > 0 {
+		promoted = true
+	} else if c < 0 {
+		demoted = true
+	}
+	last := func(win bool) int {
+		for i := len(games) - 1; i >= 0; i-- {
+			if games[i].Win == win {
+				return i
+			}
+		}
+		return len(games) - 1
+	}
+	promotedIn, demotedIn := last(true), last(false)
+
+	changes := make([]models.MatchLPChange, 0, len(games))
+	for i, game := range games {
+		lp := perLoss
+		if game.Win {
+			lp = perWin
+		}
+		changes = append(changes, models.MatchLPChange{
+			MatchID:     game.MatchID,
+			QueueID:     game.QueueID,
+			StartedAt:   game.StartedAt,
+			ChampionID:  game.ChampionID,
+			Win:         game.Win,
+			LPDelta:     int(math.Round(lp)),
+			Promotion:   promoted && i == promotedIn,
+			Demotion:    demoted && i == demotedIn,
+			Approximate: approximate,
+			Before:      rankPosition(before),
+			After:       rankPosition(after),
+		})
+	}
+	return changes
+}
+
+// lpPerGame splits delta into the LP of each win and each loss, assuming every win gains and
+// every loss costs the same LP. Wins are kept at zero or above and losses at zero or below;
+// what that model cannot explain, such as a net loss over more wins than losses, is spread
+// evenly over the games on the side of its sign, or over all games when there are none.
+func lpPerGame(delta, wins, losses int) (float64, float64) {
+	perWin, perLoss := 0.0, 0.0
+	if wins != losses {
+		perWin = float64(delta) / float64(wins-losses)
+		perLoss = -perWin
+	}
+	perWin, perLoss = max(perWin, 0), min(perLoss, 0)
+
+	rest := float64(delta) - float64(wins)*perWin - float64(losses)*perLoss
+	switch {
+	case rest > 0 && wins > 0:
+		perWin += rest / float64(wins)
+	case rest < 0 && losses > 0:
+		perLoss += rest / float64(losses)
+	case rest != 0:
+		perWin += rest / float64(wins+losses)
+		perLoss += rest / float64(wins+losses)
+	}
+	return perWin, perLoss
+}
+
+// lpSeries turns rank snapshots into chart points. Snapshots that repeat the one before them
+// are dropped, except for the last one so that the chart reaches the latest sync.
+func lpSeries(ranks []models.PlayerRank) []models.LPPoint {
+	points := make([]models.LPPoint, 0, len(ranks))
+	for i, r := range ranks {
+		ladder, ok := ladderPoints(r)
+		if !ok {
+			continue
+		}
+		if i > 0 && i < len(ranks)-1 && sameStanding(ranks[i-1], r) {
+			continue
+		}
+		points = append(points, models.LPPoint{
+			Timestamp:    r.Timestamp,
+			RankPosition: rankPosition(r),
+			Wins:         r.Wins,
+			Losses:       r.Losses,
+			LadderPoints: ladder,
+		})
+	}
+	return points
+}
+
+// ladderPosition returns the tier and division index of a rank, higher being better, and
+// false for a rank it does not know.
+func ladderPosition(tier, rank string) (int, int, bool) {
+	t := slices.Index(rankedTiers, tier)
+	if t < 0 {
+		return 0, 0, false
+	}
+	if t >= apexTier {
+		return t, 0, true
+	}
+	d := slices.Index(rankedDivisions, rank)
+	if d < 0 {
+		return 0, 0, false
+	}
+	return t, d, true
+}
+
+// ladderPoints counts the LP of a rank from the bottom of Iron IV with 100 LP per division.
+// Master and above continue from the top of Diamond I.
+func ladderPoints(r models.PlayerRank) (int, bool) {
+	t, d, ok := ladderPosition(r.Tier, r.Rank)
+	if !ok {
+		return 0, false
+	}
+	if t >= apexTier {
+		return apexTier*400 + r.LeaguePoints, true
+	}
+	return t*400 + d*100 + r.LeaguePoints, true
+}
+
+// compareRanks compares the tier and division of two ranks, ignoring their LP.
+func compareRanks(a, b models.PlayerRank) int {
+	ta, da, _ := ladderPosition(a.Tier, a.Rank)
+	tb, db, _ := ladderPosition(b.Tier, b.Rank)
+	return cmp.Or(cmp.Compare(ta, tb), cmp.Compare(da, db))
+}
+
+func sameStanding(a, b models.PlayerRank) bool {
+	return a.Tier == b.Tier && a.Rank == b.Rank && a.LeaguePoints == b.LeaguePoints &&
+		a.Wins == b.Wins && a.Losses == b.Losses
+}
+
+func rankPosition(r models.PlayerRank) models.RankPosition {
+	return models.RankPosition{Tier: r.Tier, Rank: r.Rank, LeaguePoints: r.LeaguePoints}
+}
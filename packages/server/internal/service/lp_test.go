@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeLP(t *testing.T) {
+	ranks := []models.PlayerRank{
+		{Timestamp: 1000, Tier: "GOLD", Rank: "II", LeaguePoints: 80, Wins: 10, Losses: 10},
+		{Timestamp: 5000, Tier: "GOLD", Rank: "I", LeaguePoints: 2, Wins: 11, Losses: 10},
+		{Timestamp: 9000, Tier: "GOLD", Rank: "I", LeaguePoints: 22, Wins: 13, Losses: 11},
+		{Timestamp: 12000, Tier: "GOLD", Rank: "II", LeaguePoints: 85, Wins: 13, Losses: 12},
+	}
+	games := []models.RankedGame{
+		// Ended before the first snapshot.
+		{MatchID: 1, StartedAt: 0, Duration: 900, Win: true},
+		{MatchID: 2, StartedAt: 2_000_000, Duration: 1500, Win: true},
+		{MatchID: 3, StartedAt: 5_500_000, Duration: 1500, Win: true},
+		{MatchID: 4, StartedAt: 6_500_000, Duration: 1500},
+		{MatchID: 5, StartedAt: 7_500_000, Duration: 1200, Win: true},
+		{MatchID: 6, StartedAt: 10_000_000, Duration: 1500},
+	}
+
+	changes := attributeLP(ranks, games)
+	require.Len(t, changes, 5)
+
+	// A single game between two snapshots gets the whole change, including the promotion.
+	assert.Equal(t, int64(2), changes[0].MatchID)
+	assert.Equal(t, 22, changes[0].LPDelta)
+	assert.True(t, changes[0].Promotion)
+	assert.False(t, changes[0].Approximate)
+	assert.Equal(t, models.RankPosition{Tier: "GOLD", Rank: "II", LeaguePoints: 80}, changes[0].Before)
+
+	// Two wins and a loss share 20 LP as +20, -20, +20.
+	for _, c := range changes[1:4] {
+		assert.True(t, c.Approximate)
+		assert.False(t, c.Promotion)
+	}
+	assert.Equal(t, 20, changes[1].LPDelta)
+	assert.Equal(t, -20, changes[2].LPDelta)
+	assert.Equal(t, 20, changes[3].LPDelta)
+
+	assert.Equal(t, int64(6), changes[4].MatchID)
+	assert.Equal(t, -37, changes[4].LPDelta)
+	assert.True(t, changes[4].Demotion)
+	assert.False(t, changes[4].Approximate)
+}
+
+func TestSplitLPChangeSigns(t *testing.T) {
+	before := models.PlayerRank{Timestamp: 1000, Tier: "GOLD", Rank: "II", LeaguePoints: 50, Wins: 10, Losses: 10}
+	lpDeltas := func(after models.PlayerRank, wins ...bool) []int {
+		games := make([]models.RankedGame, len(wins))
+		for i, win := range wins {
+			games[i] = models.RankedGame{MatchID: int64(i + 1), Win: win}
+		}
+		var deltas []int
+		for _, c := range splitLPChange(before, after, games) {
+			deltas = append(deltas, c.LPDelta)
+		}
+		return deltas
+	}
+
+	// Two wins and a loss that lost 10 LP: the wins gain nothing and the loss takes it all.
+	after := models.PlayerRank{Tier: "GOLD", Rank: "II", LeaguePoints: 40, Wins: 12, Losses: 11}
+	assert.Equal(t, []int{0, 0, -10}, lpDeltas(after, true, true, false))
+
+	// A win and two losses that gained 30 LP: the win takes it all.
+	after = models.PlayerRank{Tier: "GOLD", Rank: "II", LeaguePoints: 80, Wins: 11, Losses: 12}
+	assert.Equal(t, []int{30, 0, 0}, lpDeltas(after, true, false, false))
+
+	// As many wins as losses: a net gain goes to the wins and a net loss to the losses.
+	after = models.PlayerRank{Tier: "GOLD", Rank: "II", LeaguePoints: 70, Wins: 12, Losses: 12}
+	assert.Equal(t, []int{10, 0, 10, 0}, lpDeltas(after, true, false, true, false))
+	after = models.PlayerRank{Tier: "GOLD", Rank: "II", LeaguePoints: 30, Wins: 12, Losses: 12}
+	assert.Equal(t, []int{0, -10, 0, -10}, lpDeltas(after, true, false, true, false))
+}
+
+func TestLPSeries(t *testing.T) {
+	ranks := []models.PlayerRank{
+		{Timestamp: 1000, Tier: "DIAMOND", Rank: "I", LeaguePoints: 90, Wins: 5, Losses: 5},
+		{Timestamp: 2000, Tier: "DIAMOND", Rank: "I", LeaguePoints: 90, Wins: 5, Losses: 5},
+		{Timestamp: 3000, Tier: "MASTER", Rank: "I", LeaguePoints: 10, Wins: 6, Losses: 5},
+		{Timestamp: 4000, Tier: "", Rank: "", LeaguePoints: 0},
+		{Timestamp: 5000, Tier: "MASTER", Rank: "I", LeaguePoints: 10, Wins: 6, Losses: 5},
+	}
+
+	points := lpSeries(ranks)
+	require.Len(t, points, 3)
+	assert.Equal(t, 2790, points[0].LadderPoints)
+	assert.Equal(t, int64(3000), points[1].Timestamp)
+	assert.Equal(t, 2810, points[1].LadderPoints)
+	assert.Equal(t, int64(5000), points[2].Timestamp)
+}
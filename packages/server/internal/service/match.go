@@ -1,43 +1,44 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"time"
 
 	"github.com/galchammat/kadeem/internal/constants"
 	"github.com/galchammat/kadeem/internal/logging"
+	coremodels "github.com/galchammat/kadeem/internal/models"
 	riot "github.com/galchammat/kadeem/internal/riot/api"
 	"github.com/galchammat/kadeem/internal/riot/models"
-	riotstore "github.com/galchammat/kadeem/internal/riot/postgres"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 )
 
 type MatchService struct {
-	db   *riotstore.DB
-	riot *riot.Client
+	db      riotstore.Store
+	riot    *riot.Client
+	replays replay.ReplayStore
 }
 
-func NewMatchService(db *riotstore.DB, riot *riot.Client) *MatchService {
-	return &MatchService{db: db, riot: riot}
+func NewMatchService(db riotstore.Store, riot *riot.Client, replays replay.ReplayStore) *MatchService {
+	return &MatchService{db: db, riot: riot, replays: replays}
 }
 
-// SyncMatches syncs replays and match summaries for an account.
+// SyncMatches downloads the replays Riot offers for an account. Matches that are not known
+// yet are enqueued so the match queue fetches their details; details are never fetched here.
 func (s *MatchService) SyncMatches(account models.Account) error {
 	logging.Debug("Syncing matches for account", "ID", account.PUUID)
 
-	replayURLs, err := s.riot.FetchReplayURLs(account.PUUID, account.Region)
+	replayURLs, err := s.riot.FetchReplayURLs(context.Background(), account.PUUID, account.Region)
 	if err != nil {
 		logging.Error("Failed to fetch replay URLs for account", "puuid", account.PUUID, "region", account.Region, "error", err)
 		return err
 	}
 
 	for _, url := range replayURLs {
-		matchID, fullMatchID, err := extractMatchID(url)
+		matchID, _, err := extractMatchID(url)
 		if err != nil {
 			return fmt.Errorf("failed to parse matchID from replay URL: %s", url)
 		}
@@ -51,16 +52,18 @@ func (s *MatchService) SyncMatches(account models.Account) error {
 			existingMatch = &existingMatches[0]
 		}
 
-		// Fetch the match summary if record does not exist or has no start timestamp
-		if existingMatch == nil || existingMatch.Summary.StartedAt == nil {
-			logging.Debug("Fetching match summary", "MatchID", matchID, "FullMatchID", fullMatchID)
-			if err := s.SyncMatchSummary(matchID, fullMatchID, account.Region); err != nil {
-				logging.Warn("Skipping match summary sync due to error", "MatchID", matchID)
+		// Queue unknown matches; the queue workers fetch their details and timeline.
+		if existingMatch == nil {
+			logging.Debug("Enqueueing match", "MatchID", matchID)
+			if _, err := s.db.EnqueueMatches(context.Background(), []models.MatchSummary{{ID: matchID, Region: account.Region}}); err != nil {
+				logging.Warn("Skipping match due to enqueue error", "MatchID", matchID, "error", err)
+				continue
 			}
 		}
 
-		// Download the replay if record does not exist or has no replay
-		if existingMatch == nil || existingMatch.Summary.ReplayURI == nil {
+		// Download the replay if record does not exist or has no replay. Replays removed by
+		// retention stay expired.
+		if existingMatch == nil || (existingMatch.Summary.ReplayURI == nil && existingMatch.Summary.ReplayStatus != "expired") {
 			logging.Debug("Downloading replay", "MatchID", matchID, "URL", url)
 			if err := s.SyncMatchReplay(matchID, url); err != nil {
 				logging.Warn("Skipping replay download due to error", "MatchID", matchID)
@@ -74,25 +77,36 @@ func (s *MatchService) SyncMatches(account models.Account) error {
 }
 
 // SyncMatchSummary fetches match detail from Riot API and stores it.
-func (s *MatchService) SyncMatchSummary(matchID int64, fullMatchID, region string) error {
+func (s *MatchService) SyncMatchSummary(ctx context.Context, matchID int64, fullMatchID, region string) error {
 	if matchID == 0 {
 		return fmt.Errorf("matchID cannot be zero")
 	}
 
-	response, err := s.riot.FetchMatchDetail(fullMatchID, region)
+	response, err := s.riot.FetchMatchDetails(ctx, matchID, region)
 	if err != nil {
 		return err
 	}
 
 	summary := models.MatchSummary{
 		ID:        response.Info.ID,
-		StartedAt: &response.Info.StartedAt,
-		Duration:  &response.Info.Duration,
+		Region:    region,
+		StartedAt: response.Info.StartedAt,
+		Duration:  response.Info.Duration,
+		QueueID:   response.Info.QueueID,
+		Patch:     models.Patch(response.Info.GameVersion),
+		Status:    coremodels.StatusDone,
+	}
+	for i := range response.Info.Participants {
+		response.Info.Participants[i].GameID = summary.ID
 	}
 
-	if err := s.db.InsertLolMatchWithParticipants(&summary, response.Info.Participants); err != nil {
+	if err := s.db.SaveMatchSummaryBatch(ctx, []models.MatchSummary{summary}); err != nil {
+		logging.Error("Failed to save match summary", "matchID", matchID, "fullMatchID", fullMatchID, "error", err)
+		return err
+	}
+	if err := s.db.SaveMatchParticipantBatch(ctx, response.Info.Participants); err != nil {
 		logging.Error(
-			"Failed to insert match with participants (transaction rolled back)",
+			"Failed to save match participants",
 			"matchID", matchID,
 			"fullMatchID", fullMatchID,
 			"participantCount", len(response.Info.Participants),
@@ -111,14 +125,31 @@ func (s *MatchService) SyncMatchSummary(matchID int64, fullMatchID, region strin
 
 // SyncMatchReplay downloads a replay file and marks it synced in DB.
 func (s *MatchService) SyncMatchReplay(matchID int64, replayURL string) error {
-	replayPath, err := downloadReplay(matchID, replayURL)
+	replayURI, err := s.downloadReplay(context.Background(), matchID, replayURL)
 	if err != nil {
 		return fmt.Errorf("error downloading replay: %v", err)
 	}
-	_, err = s.db.UpdateLolMatch(matchID, map[string]any{"replay_uri": replayPath, "replay_status": "synced"})
+	_, err = s.db.UpdateLolMatch(matchID, map[string]any{"replay_uri": replayURI, "replay_status": "synced"})
 	return err
 }
 
+// OpenReplay opens the stored replay of a match for reading. It returns replay.ErrNotFound
+// when the match has no replay or the file is gone.
+func (s *MatchService) OpenReplay(ctx context.Context, matchID int64) (*replay.Object, error) {
+	matches, err := s.db.ListLolMatches(&models.MatchFilter{MatchID: &matchID}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 || matches[0].Summary.ReplayURI == nil {
+		return nil, replay.ErrNotFound
+	}
+	key, ok := s.replays.Key(*matches[0].Summary.ReplayURI)
+	if !ok {
+		return nil, fmt.Errorf("replay %q is not in the configured replay store", *matches[0].Summary.ReplayURI)
+	}
+	return replay.Open(ctx, s.replays, key)
+}
+
 // ListMatches lists matches with auto-sync if stale.
 func (s *MatchService) ListMatches(filter *models.MatchFilter, account *models.Account, limit, offset int) ([]models.Match, error) {
 	if account != nil &&
@@ -130,14 +161,30 @@ func (s *MatchService) ListMatches(filter *models.MatchFilter, account *models.A
 	return s.db.ListLolMatches(filter, limit, offset)
 }
 
-// FetchMatchIDs fetches match IDs from the Riot API.
-func (s *MatchService) FetchMatchIDs(puuid, region string, startTime *int64) ([]string, error) {
-	return s.riot.FetchMatchIDs(puuid, region, startTime)
+// FetchMatchIDs fetches the most recent page of match IDs from the Riot API.
+func (s *MatchService) FetchMatchIDs(ctx context.Context, puuid, region string, startTime *int64) ([]string, error) {
+	return s.riot.FetchMatchIDPage(ctx, puuid, region, startTime, 0, 100)
+}
+
+// ListDLQMatches lists matches whose detail fetch failed on every retry.
+func (s *MatchService) ListDLQMatches(limit, offset int) ([]models.Match, error) {
+	status := coremodels.StatusDLQ
+	return s.db.ListLolMatches(&models.MatchFilter{Status: &status}, limit, offset)
+}
+
+// RequeueMatch moves a DLQ match back to retry. A nil matchID requeues the whole DLQ.
+func (s *MatchService) RequeueMatch(ctx context.Context, matchID *int64) (int64, error) {
+	n, err := s.db.RequeueMatch(ctx, matchID)
+	if err != nil {
+		return 0, err
+	}
+	logging.Info("Requeued DLQ matches", "matchID", matchID, "count", n)
+	return n, nil
 }
 
 // FetchReplayURLs fetches replay URLs from the Riot API.
-func (s *MatchService) FetchReplayURLs(puuid, region string) ([]string, error) {
-	return s.riot.FetchReplayURLs(puuid, region)
+func (s *MatchService) FetchReplayURLs(ctx context.Context, puuid, region string) ([]string, error) {
+	return s.riot.FetchReplayURLs(ctx, puuid, region)
 }
 
 // --- helpers ---
@@ -163,59 +210,3 @@ func extractMatchID(url string) (int64, string, error) {
 
 	return id, fullMatchID, nil
 }
-
-func replayStorageDir() string {
-	if binDir := os.Getenv("BIN_DIR"); binDir != "" {
-		return filepath.Join(binDir, "replays")
-	}
-	return filepath.Join("bin", "replays")
-}
-
-func replayExists(filePath string) bool {
-	info, err := os.Stat(filePath)
-	return err == nil && info.Size() > 1024*1024 // > 1MB
-}
-
-func downloadReplay(matchID int64, replayURL string) (string, error) {
-	if replayURL == "" {
-		return "", fmt.Errorf("replay URL cannot be empty")
-	}
-
-	replaysDir := replayStorageDir()
-	if err := os.MkdirAll(replaysDir, 0o755); err != nil {
-		logging.Error("Failed to create replays directory", "path", replaysDir, "error", err)
-		return "", err
-	}
-
-	filePath := filepath.Join(replaysDir, fmt.Sprintf("%d.rofl", matchID))
-	if replayExists(filePath) {
-		return filePath, nil
-	}
-
-	resp, err := http.Get(replayURL)
-	if err != nil {
-		logging.Error("Failed to download replay from URL", "matchID", matchID, "url", replayURL, "error", err)
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("replay download failed with status code %d", resp.StatusCode)
-	}
-
-	outFile, err := os.Create(filePath)
-	if err != nil {
-		logging.Error("Failed to create replay file", "matchID", matchID, "path", filePath, "error", err)
-		return "", err
-	}
-	defer outFile.Close()
-
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		logging.Error("Failed to write replay data to file", "matchID", matchID, "path", filePath, "error", err)
-		_ = outFile.Close()
-		_ = os.Remove(filePath)
-		return "", err
-	}
-
-	return filePath, nil
-}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// MatchupService computes how accounts fare against the champions they face in lane.
+type MatchupService struct {
+	db riotstore.Store
+}
+
+// NewMatchupService creates a new MatchupService.
+func NewMatchupService(db riotstore.Store) *MatchupService {
+	return &MatchupService{db: db}
+}
+
+// LaneMatchups returns the account's results per enemy lane champion, most played first.
+// Remakes are left out.
+func (s *MatchupService) LaneMatchups(ctx context.Context, puuid string, filter *models.MatchupFilter) ([]models.LaneMatchup, error) {
+	totals, err := s.db.ListLaneMatchups(ctx, puuid, remakeDuration, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	matchups := make([]models.LaneMatchup, 0, len(totals))
+	for _, t := range totals {
+		games := float64(t.Games)
+		matchups = append(matchups, models.LaneMatchup{
+			EnemyChampionID: t.EnemyChampionID,
+			Lane:            t.Lane,
+			Games:           t.Games,
+			Wins:            t.Wins,
+			WinRate:         round2(float64(t.Wins) / games),
+			KillDiff:        round2(float64(t.KillDiff) / games),
+			DeathDiff:       round2(float64(t.DeathDiff) / games),
+			DamageDiff:      round2(float64(t.DamageDiff) / games),
+			CSDiff:          round2(float64(t.CSDiff) / games),
+		})
+	}
+	return matchups, nil
+}
@@ -1,32 +1,33 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/galchammat/kadeem/internal/logging"
 	riot "github.com/galchammat/kadeem/internal/riot/api"
 	"github.com/galchammat/kadeem/internal/riot/models"
-	riotstore "github.com/galchammat/kadeem/internal/riot/postgres"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 )
 
 type RankService struct {
-	db   *riotstore.DB
+	db   riotstore.Store
 	riot *riot.Client
 }
 
-func NewRankService(db *riotstore.DB, riot *riot.Client) *RankService {
+func NewRankService(db riotstore.Store, riot *riot.Client) *RankService {
 	return &RankService{db: db, riot: riot}
 }
 
 // SyncRank fetches current rank for an account and stores a snapshot.
 func (s *RankService) SyncRank(account *models.Account) error {
-	summonerID, err := s.riot.FetchSummonerID(account.PUUID, account.Region)
+	summonerID, err := s.riot.FetchSummonerID(context.Background(), account.PUUID, account.Region)
 	if err != nil {
 		return err
 	}
 
-	entries, err := s.riot.FetchRankEntries(summonerID, account.Region)
+	entries, err := s.riot.FetchRankEntries(context.Background(), summonerID, account.Region)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	riot "github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+)
+
+const replayBackfillBatchSize = 100
+
+// ChampionResolver maps replay champion names (e.g. "MonkeyKing") to champion IDs.
+type ChampionResolver interface {
+	ChampionID(name string) (int, bool)
+}
+
+// BackfillParticipantsFromReplays fills participants of matches that have a local replay but
+// no participant rows, e.g. because the Riot match API no longer serves them. Champion IDs are
+// left at zero when champions is nil. It returns the number of backfilled matches.
+func (s *MatchService) BackfillParticipantsFromReplays(ctx context.Context, champions ChampionResolver) (int, error) {
+	hasReplay := true
+	hasParticipants := false
+	filter := &models.MatchFilter{HasReplay: &hasReplay, HasParticipants: &hasParticipants}
+
+	backfilled := 0
+	skipped := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return backfilled, err
+		}
+
+		// Backfilled matches drop out of the filter, so only skipped ones move the offset.
+		matches, err := s.db.ListLolMatches(filter, replayBackfillBatchSize, skipped)
+		if err != nil {
+			return backfilled, fmt.Errorf("list matches without participants: %w", err)
+		}
+		if len(matches) == 0 {
+			return backfilled, nil
+		}
+
+		for _, match := range matches {
+			if err := s.backfillMatchFromReplay(ctx, match.Summary, champions); err != nil {
+				logging.Warn("Skipping replay backfill", "matchID", match.Summary.ID, "error", err)
+				skipped++
+				continue
+			}
+			backfilled++
+		}
+	}
+}
+
+func (s *MatchService) backfillMatchFromReplay(ctx context.Context, summary models.MatchSummary, champions ChampionResolver) error {
+	if summary.ReplayURI == nil {
+		return fmt.Errorf("match has no replay")
+	}
+	path, cleanup, err := s.localReplay(ctx, *summary.ReplayURI)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	metadata, err := riot.ReadROFLMetadata(path)
+	if err != nil {
+		return err
+	}
+	participants := replayParticipants(summary.ID, metadata, champions)
+	if len(participants) == 0 {
+		return fmt.Errorf("replay has no player stats")
+	}
+
+	if err := s.db.SaveMatchParticipantBatch(ctx, participants); err != nil {
+		return err
+	}
+	if summary.Duration == 0 && metadata.GameLength > 0 {
+		if _, err := s.db.UpdateLolMatch(summary.ID, map[string]any{"duration": metadata.GameLength / 1000}); err != nil {
+			return err
+		}
+	}
+
+	logging.Debug("Backfilled match participants from replay", "matchID", summary.ID, "participantCount", len(participants))
+	return nil
+}
+
+// localReplay returns a local file path for a replay URI. Replays that are not on the local
+// disk are copied to a temporary file, which cleanup removes.
+func (s *MatchService) localReplay(ctx context.Context, uri string) (string, func(), error) {
+	key, ok := s.replays.Key(uri)
+	if !ok {
+		return "", nil, fmt.Errorf("replay %q is not in the configured replay store", uri)
+	}
+	if local, ok := s.replays.(*replay.LocalStore); ok {
+		if _, err := local.Stat(ctx, key); err != nil {
+			return "", nil, err
+		}
+		return local.Path(key), func() {}, nil
+	}
+
+	rc, err := s.replays.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rc.Close()
+
+	tmp, err := os.CreateTemp("", "replay-*.rofl")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) } //nolint:errcheck
+	if _, err := io.Copy(tmp, rc); err != nil {
+		tmp.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+// replayParticipants maps the end-of-game stats of a replay onto participant rows.
+// The stats are in participant order, so participant IDs follow their position.
+func replayParticipants(matchID int64, metadata *riot.ROFLMetadata, champions ChampionResolver) []models.MatchParticipantSummary {
+	participants := make([]models.MatchParticipantSummary, 0, len(metadata.Players))
+	for i, player := range metadata.Players {
+		p := models.MatchParticipantSummary{
+			GameID:                      matchID,
+			ChampLevel:                  player.Level,
+			Kills:                       player.Kills,
+			Deaths:                      player.Deaths,
+			Assists:                     player.Assists,
+			TotalMinionsKilled:          player.MinionsKilled,
+			DoubleKills:                 player.DoubleKills,
+			TripleKills:                 player.TripleKills,
+			QuadraKills:                 player.QuadraKills,
+			PentaKills:                  player.PentaKills,
+			Item0:                       player.Items[0],
+			Item1:                       player.Items[1],
+			Item2:                       player.Items[2],
+			Item3:                       player.Items[3],
+			Item4:                       player.Items[4],
+			Item5:                       player.Items[5],
+			Item6:                       player.Items[6],
+			Lane:                        positionLane(player.Position),
+			ParticipantID:               i + 1,
+			PUUID:                       player.PUUID,
+			RiotIDGameName:              player.RiotIDGameName,
+			RiotIDTagline:               player.RiotIDTagline,
+			TotalDamageDealtToChampions: player.TotalDamageDealtToChampions,
+			TotalDamageTaken:            player.TotalDamageTaken,
+			Win:                         player.Win,
+			TeamID:                      player.Team,
+			TeamPosition:                player.Position,
+		}
+		if champions != nil {
+			p.ChampionID, _ = champions.ChampionID(player.Champion)
+		}
+		participants = append(participants, p)
+	}
+	return participants
+}
+
+// positionLane maps a replay position onto the lane values of the match API.
+func positionLane(position string) string {
+	switch position {
+	case "TOP", "JUNGLE", "MIDDLE", "BOTTOM":
+		return position
+	case "UTILITY":
+		return "BOTTOM"
+	default:
+		return "NONE"
+	}
+}
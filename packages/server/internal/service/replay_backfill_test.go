@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	riot "github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type championMap map[string]int
+
+func (m championMap) ChampionID(name string) (int, bool) {
+	id, ok := m[name]
+	return id, ok
+}
+
+func TestReplayParticipants(t *testing.T) {
+	metadata := &riot.ROFLMetadata{Players: []riot.ROFLPlayerStats{
+		{PUUID: "a", Champion: "Ahri", Position: "MIDDLE", Kills: 9, Win: true, Items: [7]int{3089}},
+		{PUUID: "b", Champion: "Unknown", Position: "UTILITY"},
+	}}
+
+	participants := replayParticipants(42, metadata, championMap{"Ahri": 103})
+	require.Len(t, participants, 2)
+
+	assert.Equal(t, int64(42), participants[0].GameID)
+	assert.Equal(t, 1, participants[0].ParticipantID)
+	assert.Equal(t, 103, participants[0].ChampionID)
+	assert.Equal(t, "MIDDLE", participants[0].Lane)
+	assert.Equal(t, 9, participants[0].Kills)
+	assert.Equal(t, 3089, participants[0].Item0)
+	assert.True(t, participants[0].Win)
+
+	assert.Equal(t, 2, participants[1].ParticipantID)
+	assert.Zero(t, participants[1].ChampionID)
+	assert.Equal(t, "BOTTOM", participants[1].Lane)
+}
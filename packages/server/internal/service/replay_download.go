@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	riot "github.com/galchammat/kadeem/internal/riot/api"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+)
+
+const (
+	defaultReplayDownloadConcurrency = 2
+	defaultReplayDownloadTimeout     = 10 * time.Minute
+)
+
+var (
+	replayDownloadsOnce sync.Once
+	replayDownloadSlots chan struct{}
+	replayDownloadLimit time.Duration
+	replayHTTPClient    = &http.Client{Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	}}
+
+	// replayKeyLocks serializes downloads of the same replay, which share one staging file.
+	replayKeyLocksMu sync.Mutex
+	replayKeyLocks   = make(map[string]*replayKeyLock)
+)
+
+type replayKeyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockReplayKey waits until no other download of key is running in the process and returns the
+// function that lets the next one in.
+func lockReplayKey(key string) func() {
+	replayKeyLocksMu.Lock()
+	l, ok := replayKeyLocks[key]
+	if !ok {
+		l = &replayKeyLock{}
+		replayKeyLocks[key] = l
+	}
+	l.refs++
+	replayKeyLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		replayKeyLocksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(replayKeyLocks, key)
+		}
+		replayKeyLocksMu.Unlock()
+	}
+}
+
+// initReplayDownloads reads REPLAY_DOWNLOAD_CONCURRENCY and REPLAY_DOWNLOAD_TIMEOUT (a Go duration).
+// The slots are shared by every MatchService in the process, so syncing several accounts at
+// once never runs more downloads than configured.
+func initReplayDownloads() {
+	replayDownloadsOnce.Do(func() {
+		concurrency := defaultReplayDownloadConcurrency
+		if v, err := strconv.Atoi(os.Getenv("REPLAY_DOWNLOAD_CONCURRENCY")); err == nil && v > 0 {
+			concurrency = v
+		}
+		replayDownloadLimit = defaultReplayDownloadTimeout
+		if v, err := time.ParseDuration(os.Getenv("REPLAY_DOWNLOAD_TIMEOUT")); err == nil && v > 0 {
+			replayDownloadLimit = v
+		}
+		replayDownloadSlots = make(chan struct{}, concurrency)
+	})
+}
+
+// acquireReplayDownload waits for a free download slot and returns the function that frees it.
+func acquireReplayDownload(ctx context.Context) (func(), error) {
+	initReplayDownloads()
+	select {
+	case replayDownloadSlots <- struct{}{}:
+		return func() { <-replayDownloadSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// downloadReplay stores the replay of a match unless a verified copy already exists,
+// and returns its replay URI.
+//
+// The file is downloaded into the staging directory first. An interrupted download is
+// resumed from there on the next attempt, and only a replay that passes riot.VerifyROFL
+// is handed to the replay store, whose Put is atomic. Downloads of the same match wait for
+// each other so that they never write to the staging file at once.
+func (s *MatchService) downloadReplay(ctx context.Context, matchID int64, replayURL string) (string, error) {
+	if replayURL == "" {
+		return "", fmt.Errorf("replay URL cannot be empty")
+	}
+
+	key := replay.MatchKey(matchID)
+	if s.hasVerifiedReplay(ctx, key) {
+		return s.replays.URI(key), nil
+	}
+
+	unlock := lockReplayKey(key)
+	defer unlock()
+	// A download that held the lock before us may have stored the replay already.
+	if s.hasVerifiedReplay(ctx, key) {
+		return s.replays.URI(key), nil
+	}
+
+	release, err := acquireReplayDownload(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, replayDownloadLimit)
+	defer cancel()
+
+	partial := filepath.Join(replay.StagingDir(), key+".part")
+	if err := replay.Download(ctx, replayHTTPClient, replayURL, partial); err != nil {
+		// The partial file is kept so the next attempt can resume it.
+		logging.Error("Failed to download replay from URL", "matchID", matchID, "url", replayURL, "error", err)
+		return "", err
+	}
+
+	if err := riot.VerifyROFL(partial); err != nil {
+		os.Remove(partial) //nolint:errcheck
+		return "", fmt.Errorf("verify downloaded replay: %w", err)
+	}
+
+	if err := s.storeReplay(ctx, key, partial); err != nil {
+		logging.Error("Failed to store replay", "matchID", matchID, "key", key, "error", err)
+		return "", err
+	}
+	os.Remove(partial) //nolint:errcheck
+
+	return s.replays.URI(key), nil
+}
+
+// hasVerifiedReplay reports whether the store already holds the replay. Only verified replays
+// are uploaded, but local files may predate verification and are checked again.
+func (s *MatchService) hasVerifiedReplay(ctx context.Context, key string) bool {
+	if _, err := s.replays.Stat(ctx, key); err != nil {
+		if !errors.Is(err, replay.ErrNotFound) {
+			logging.Warn("Failed to stat stored replay", "key", key, "error", err)
+		}
+		return false
+	}
+
+	local, ok := s.replays.(*replay.LocalStore)
+	if !ok {
+		return true
+	}
+	if err := riot.VerifyROFL(local.Path(key)); err != nil {
+		logging.Warn("Stored replay is incomplete, downloading it again", "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+func (s *MatchService) storeReplay(ctx context.Context, key, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return s.replays.Put(ctx, key, f, info.Size())
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testReplayBytes builds a minimal replay with one uncompressed chunk.
+func testReplayBytes(payload []byte) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, 0x10+0xD)
+	copy(header, "RIOT\x02\x00")
+	buf.Write(header)
+
+	chunkHeader := make([]byte, 17)
+	binary.LittleEndian.PutUint32(chunkHeader[0:4], 1)
+	binary.LittleEndian.PutUint32(chunkHeader[9:13], uint32(len(payload)))
+	buf.Write(chunkHeader)
+	buf.Write(payload)
+
+	buf.Write(make([]byte, 0x100)) // signature
+	meta := []byte(`{"gameLength":1000}`)
+	buf.Write(meta)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(meta)))
+	return buf.Bytes()
+}
+
+func newReplayTestService(t *testing.T) (*MatchService, *replay.LocalStore) {
+	t.Helper()
+	t.Setenv("BIN_DIR", t.TempDir())
+	store := replay.NewLocalStore(replay.DefaultDir())
+	return &MatchService{replays: store}, store
+}
+
+func TestDownloadReplayResumesPartialFile(t *testing.T) {
+	content := testReplayBytes(bytes.Repeat([]byte("p"), 4096))
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "replay", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	s, store := newReplayTestService(t)
+	partial := filepath.Join(replay.StagingDir(), replay.MatchKey(7)+".part")
+	require.NoError(t, os.MkdirAll(filepath.Dir(partial), 0o755))
+	require.NoError(t, os.WriteFile(partial, content[:1000], 0o644))
+
+	uri, err := s.downloadReplay(context.Background(), 7, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, store.URI(replay.MatchKey(7)), uri)
+	assert.Equal(t, []string{"bytes=1000-"}, ranges)
+
+	stored, err := os.ReadFile(store.Path(replay.MatchKey(7)))
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+	assert.NoFileExists(t, partial)
+
+	// A verified copy is not downloaded again.
+	_, err = s.downloadReplay(context.Background(), 7, server.URL)
+	require.NoError(t, err)
+	assert.Len(t, ranges, 1)
+}
+
+func TestDownloadReplayRejectsInvalidReplay(t *testing.T) {
+	content := testReplayBytes(bytes.Repeat([]byte("p"), 4096))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content[:len(content)-10])
+	}))
+	defer server.Close()
+
+	s, store := newReplayTestService(t)
+	_, err := s.downloadReplay(context.Background(), 8, server.URL)
+	require.Error(t, err)
+
+	assert.NoFileExists(t, store.Path(replay.MatchKey(8)))
+	assert.NoFileExists(t, filepath.Join(replay.StagingDir(), replay.MatchKey(8)+".part"))
+}
+
+func TestDownloadReplayReplacesTruncatedLocalCopy(t *testing.T) {
+	content := testReplayBytes(bytes.Repeat([]byte("p"), 4096))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	s, store := newReplayTestService(t)
+	key := replay.MatchKey(9)
+	require.NoError(t, store.Put(context.Background(), key, bytes.NewReader(content[:2000]), 2000))
+
+	_, err := s.downloadReplay(context.Background(), 9, server.URL)
+	require.NoError(t, err)
+
+	stored, err := os.ReadFile(store.Path(key))
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+}
+
+func TestDownloadReplayConcurrentDownloadsOfOneMatch(t *testing.T) {
+	content := testReplayBytes(bytes.Repeat([]byte("p"), 64*1024))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.ServeContent(w, r, "replay", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	s, store := newReplayTestService(t)
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.downloadReplay(context.Background(), 10, server.URL)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	// Downloads that waited for the first one find its verified copy.
+	assert.Equal(t, int32(1), requests.Load())
+	stored, err := os.ReadFile(store.Path(replay.MatchKey(10)))
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+}
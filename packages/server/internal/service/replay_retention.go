@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+)
+
+// ReplayRetention decides which stored replays are kept. Aligned and pentakill replays are
+// never deleted while their Keep flag is set, not even to get under MaxBytes.
+type ReplayRetention struct {
+	MaxAge         time.Duration // 0 keeps replays regardless of age
+	KeepAligned    bool
+	KeepPentakills bool
+	MaxBytes       int64 // 0 means no cap
+}
+
+// DefaultReplayRetention keeps 30 days of replays plus every aligned or pentakill game.
+var DefaultReplayRetention = ReplayRetention{
+	MaxAge:         30 * 24 * time.Hour,
+	KeepAligned:    true,
+	KeepPentakills: true,
+}
+
+// ReplayRetentionFromEnv reads REPLAY_RETENTION_DAYS, REPLAY_RETENTION_KEEP_ALIGNED,
+// REPLAY_RETENTION_KEEP_PENTAKILLS and REPLAY_RETENTION_MAX_BYTES on top of the defaults.
+func ReplayRetentionFromEnv() ReplayRetention {
+	policy := DefaultReplayRetention
+	if raw := os.Getenv("REPLAY_RETENTION_DAYS"); raw != "" {
+		if days, err := strconv.Atoi(raw); err == nil && days >= 0 {
+			policy.MaxAge = time.Duration(days) * 24 * time.Hour
+		} else {
+			logging.Warn("Ignoring invalid REPLAY_RETENTION_DAYS", "value", raw)
+		}
+	}
+	if raw := os.Getenv("REPLAY_RETENTION_KEEP_ALIGNED"); raw != "" {
+		if keep, err := strconv.ParseBool(raw); err == nil {
+			policy.KeepAligned = keep
+		} else {
+			logging.Warn("Ignoring invalid REPLAY_RETENTION_KEEP_ALIGNED", "value", raw)
+		}
+	}
+	if raw := os.Getenv("REPLAY_RETENTION_KEEP_PENTAKILLS"); raw != "" {
+		if keep, err := strconv.ParseBool(raw); err == nil {
+			policy.KeepPentakills = keep
+		} else {
+			logging.Warn("Ignoring invalid REPLAY_RETENTION_KEEP_PENTAKILLS", "value", raw)
+		}
+	}
+	if raw := os.Getenv("REPLAY_RETENTION_MAX_BYTES"); raw != "" {
+		if maxBytes, err := strconv.ParseInt(raw, 10, 64); err == nil && maxBytes >= 0 {
+			policy.MaxBytes = maxBytes
+		} else {
+			logging.Warn("Ignoring invalid REPLAY_RETENTION_MAX_BYTES", "value", raw)
+		}
+	}
+	return policy
+}
+
+// Reasons a replay is expired, or missing when its object is gone from the replay store.
+const (
+	ReplayExpiredAge     = "age"
+	ReplayExpiredSizeCap = "size_cap"
+	ReplayMissing        = "missing"
+)
+
+// ExpiredReplay is a replay the collector deletes (or would delete in a dry run), or one it
+// found missing.
+type ExpiredReplay struct {
+	MatchID   int64  `json:"matchId"`
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	StartedAt int64  `json:"startedAt"`
+	Reason    string `json:"reason"`
+}
+
+// ReplayGCReport describes one garbage collection pass. Missing lists the replays whose
+// objects are gone; their matches are reset so the replays are downloaded again. Failed lists
+// the replays that were due but could not be deleted; they stay stored and are tried again on
+// the next pass.
+type ReplayGCReport struct {
+	DryRun       bool            `json:"dryRun"`
+	Scanned      int             `json:"scanned"`
+	Kept         int             `json:"kept"`
+	KeptBytes    int64           `json:"keptBytes"`
+	Expired      []ExpiredReplay `json:"expired"`
+	ExpiredBytes int64           `json:"expiredBytes"`
+	Missing      []ExpiredReplay `json:"missing"`
+	Failed       []ExpiredReplay `json:"failed"`
+}
+
+// CollectReplays deletes the stored replays that fall outside policy and marks their matches
+// expired. Matches whose replay object is missing are reset instead. With dryRun set nothing
+// is changed and the report lists what would be deleted.
+func (s *MatchService) CollectReplays(ctx context.Context, policy ReplayRetention, dryRun bool) (*ReplayGCReport, error) {
+	stored, err := s.db.ListStoredReplays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.replays.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list replay store: %w", err)
+	}
+	sizes := make(map[string]int64, len(objects))
+	for _, obj := range objects {
+		sizes[obj.Key] = obj.Size
+	}
+
+	entries := make([]replayGCEntry, 0, len(stored))
+	for _, r := range stored {
+		key, ok := s.replays.Key(r.ReplayURI)
+		if !ok {
+			// Replays written to another store are not ours to delete.
+			logging.Warn("Skipping replay outside the configured replay store", "matchID", r.MatchID, "uri", r.ReplayURI)
+			continue
+		}
+		size, exists := sizes[key]
+		entries = append(entries, replayGCEntry{StoredReplay: r, key: key, size: size, missing: !exists})
+	}
+
+	report := planReplayGC(entries, policy, time.Now())
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+
+	var missingIDs []int64
+	for _, e := range report.Missing {
+		missingIDs = append(missingIDs, e.MatchID)
+	}
+	if _, err := s.db.ResetMatchReplays(ctx, missingIDs); err != nil {
+		return report, err
+	}
+
+	expired := make([]ExpiredReplay, 0, len(report.Expired))
+	var expiredIDs []int64
+	for _, e := range report.Expired {
+		if err := s.replays.Delete(ctx, e.Key); err != nil && !errors.Is(err, replay.ErrNotFound) {
+			logging.Error("Failed to delete replay", "matchID", e.MatchID, "key", e.Key, "error", err)
+			report.Failed = append(report.Failed, e)
+			report.ExpiredBytes -= e.Size
+			continue
+		}
+		expired = append(expired, e)
+		expiredIDs = append(expiredIDs, e.MatchID)
+	}
+	report.Expired = expired
+	if _, err := s.db.ExpireMatchReplays(ctx, expiredIDs); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+type replayGCEntry struct {
+	models.StoredReplay
+	key     string
+	size    int64
+	missing bool
+}
+
+// planReplayGC picks the replays to expire. entries must be ordered oldest first so the
+// size cap removes the oldest unprotected replays.
+func planReplayGC(entries []replayGCEntry, policy ReplayRetention, now time.Time) *ReplayGCReport {
+	report := &ReplayGCReport{
+		Scanned: len(entries),
+		Expired: []ExpiredReplay{},
+		Missing: []ExpiredReplay{},
+		Failed:  []ExpiredReplay{},
+	}
+	cutoff := now.Add(-policy.MaxAge).UnixMilli()
+
+	expired := func(e replayGCEntry, reason string) ExpiredReplay {
+		return ExpiredReplay{MatchID: e.MatchID, Key: e.key, Size: e.size, StartedAt: e.StartedAt, Reason: reason}
+	}
+	expire := func(e replayGCEntry, reason string) {
+		report.Expired = append(report.Expired, expired(e, reason))
+		report.ExpiredBytes += e.size
+	}
+
+	var candidates []replayGCEntry
+	for _, e := range entries {
+		protected := (policy.KeepAligned && e.Aligned) || (policy.KeepPentakills && e.Pentakill)
+		switch {
+		case e.missing:
+			report.Missing = append(report.Missing, expired(e, ReplayMissing))
+		case !protected && policy.MaxAge > 0 && e.StartedAt < cutoff:
+			expire(e, ReplayExpiredAge)
+		default:
+			report.KeptBytes += e.size
+			if !protected {
+				candidates = append(candidates, e)
+			}
+		}
+	}
+
+	if policy.MaxBytes > 0 {
+		for _, e := range candidates {
+			if report.KeptBytes <= policy.MaxBytes {
+				break
+			}
+			report.KeptBytes -= e.size
+			expire(e, ReplayExpiredSizeCap)
+		}
+	}
+
+	report.Kept = report.Scanned - len(report.Expired) - len(report.Missing)
+	return report
+}
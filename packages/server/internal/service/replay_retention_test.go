@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanReplayGC(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) int64 { return now.AddDate(0, 0, -n).UnixMilli() }
+	entry := func(id int64, age int, size int64) replayGCEntry {
+		return replayGCEntry{
+			StoredReplay: models.StoredReplay{MatchID: id, StartedAt: daysAgo(age)},
+			key:          "key",
+			size:         size,
+		}
+	}
+
+	old := entry(1, 40, 100)
+	oldAligned := entry(2, 40, 100)
+	oldAligned.Aligned = true
+	oldPenta := entry(3, 40, 100)
+	oldPenta.Pentakill = true
+	missing := entry(4, 1, 0)
+	missing.missing = true
+	recentA := entry(5, 10, 100)
+	recentB := entry(6, 5, 100)
+	recentC := entry(7, 1, 100)
+
+	entries := []replayGCEntry{old, oldAligned, oldPenta, missing, recentA, recentB, recentC}
+	policy := ReplayRetention{MaxAge: 30 * 24 * time.Hour, KeepAligned: true, KeepPentakills: true, MaxBytes: 400}
+
+	report := planReplayGC(entries, policy, now)
+
+	reasons := map[int64]string{}
+	for _, e := range report.Expired {
+		reasons[e.MatchID] = e.Reason
+	}
+	assert.Equal(t, map[int64]string{
+		1: ReplayExpiredAge,
+		5: ReplayExpiredSizeCap,
+	}, reasons)
+	assert.Equal(t, []ExpiredReplay{{MatchID: 4, Key: "key", StartedAt: daysAgo(1), Reason: ReplayMissing}}, report.Missing)
+	assert.Equal(t, 7, report.Scanned)
+	assert.Equal(t, 4, report.Kept)
+	assert.Equal(t, int64(400), report.KeptBytes)
+	assert.Equal(t, int64(200), report.ExpiredBytes)
+}
+
+func TestPlanReplayGCProtectedReplaysIgnoreCap(t *testing.T) {
+	now := time.Now()
+	aligned := replayGCEntry{StoredReplay: models.StoredReplay{MatchID: 1, StartedAt: now.UnixMilli(), Aligned: true}, size: 500}
+
+	report := planReplayGC([]replayGCEntry{aligned}, ReplayRetention{KeepAligned: true, MaxBytes: 100}, now)
+	assert.Empty(t, report.Expired)
+	assert.Equal(t, int64(500), report.KeptBytes)
+
+	report = planReplayGC([]replayGCEntry{aligned}, ReplayRetention{MaxBytes: 100}, now)
+	assert.Len(t, report.Expired, 1)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// DefaultSessionGap is the longest break between two games of the same play session.
+const DefaultSessionGap = 45 * time.Minute
+
+const (
+	// tiltMinGames is the shortest session that can be flagged as tilted.
+	tiltMinGames = 4
+	// tiltKDATrend is the KDA lost per game over a session from which it counts as tilted.
+	tiltKDATrend = -0.3
+)
+
+// rankedQueues are the queues whose games change LP.
+var rankedQueues = []int{420, 440}
+
+// SessionGapFromEnv reads the play session gap from SESSION_GAP, falling back to
+// DefaultSessionGap.
+func SessionGapFromEnv() time.Duration {
+	raw := os.Getenv("SESSION_GAP")
+	if raw == "" {
+		return DefaultSessionGap
+	}
+	gap, err := time.ParseDuration(raw)
+	if err != nil || gap <= 0 {
+		logging.Warn("Ignoring invalid SESSION_GAP", "value", raw)
+		return DefaultSessionGap
+	}
+	return gap
+}
+
+// PlaySessionService groups the games of accounts into play sessions.
+type PlaySessionService struct {
+	db  riotstore.Store
+	lp  *LPService
+	gap time.Duration
+}
+
+// NewPlaySessionService creates a new PlaySessionService that splits sessions at breaks of at
+// least gap.
+func NewPlaySessionService(db riotstore.Store, lp *LPService, gap time.Duration) *PlaySessionService {
+	return &PlaySessionService{db: db, lp: lp, gap: gap}
+}
+
+// AccountSessions returns the play sessions of the account between from and to (unix
+// seconds), newest first. A gap of zero uses the service's gap.
+func (s *PlaySessionService) AccountSessions(ctx context.Context, puuid string, from, to int64, gap time.Duration) ([]models.PlaySession, error) {
+	return s.sessions(ctx, []string{puuid}, from, to, gap)
+}
+
+// StreamerSessions returns the play sessions of the streamer between from and to (unix
+// seconds) over all of their accounts, newest first. A gap of zero uses the service's gap.
+func (s *PlaySessionService) StreamerSessions(ctx context.Context, streamerID int, from, to int64, gap time.Duration) ([]models.PlaySession, error) {
+	accounts, err := s.db.ListRiotAccounts(&models.Account{StreamerID: streamerID}, 1000, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts of streamer %d: %w", streamerID, err)
+	}
+	puuids := make([]string, len(accounts))
+	for i, account := range accounts {
+		puuids[i] = account.PUUID
+	}
+	return s.sessions(ctx, puuids, from, to, gap)
+}
+
+func (s *PlaySessionService) sessions(ctx context.Context, puuids []string, from, to int64, gap time.Duration) ([]models.PlaySession, error) {
+	if gap <= 0 {
+		gap = s.gap
+	}
+	games, err := s.db.ListPlayedGames(ctx, puuids, remakeDuration, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	lpChanges := make(map[int64]models.MatchLPChange)
+	for _, puuid := range puuids {
+		for _, queueID := range rankedQueues {
+			changes, err := s.lp.MatchLPChanges(ctx, puuid, queueID, from, to)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range changes {
+				lpChanges[c.MatchID] = c
+			}
+		}
+	}
+	return splitSessions(games, lpChanges, gap), nil
+}
+
+// splitSessions groups games sorted oldest first into sessions, starting a new session after
+// a break of at least gap, and returns them newest first.
+func splitSessions(games []models.PlayedGame, lpChanges map[int64]models.MatchLPChange, gap time.Duration) []models.PlaySession {
+	var sessions []models.PlaySession
+	start := 0
+	for i := 1; i <= len(games); i++ {
+		if i < len(games) && games[i].StartedAt-games[i-1].EndedAt() < gap.Milliseconds() {
+			continue
+		}
+		sessions = append(sessions, summarizeSession(games[start:i], lpChanges))
+		start = i
+	}
+	for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
+		sessions[i], sessions[j] = sessions[j], sessions[i]
+	}
+	return sessions
+}
+
+// summarizeSession aggregates the games of one session. The session is flagged as tilted when
+// it is long enough, the KDA drops game over game, and the second half won fewer games than
+// the first.
+func summarizeSession(games []models.PlayedGame, lpChanges map[int64]models.MatchLPChange) models.PlaySession {
+	session := models.PlaySession{
+		StartedAt: games[0].StartedAt,
+		EndedAt:   games[len(games)-1].EndedAt(),
+		Games:     len(games),
+		MatchIDs:  make([]int64, len(games)),
+	}
+	var kills, deaths, assists, lossStreak int
+	kdas := make([]float64, len(games))
+	for i, g := range games {
+		session.MatchIDs[i] = g.MatchID
+		if g.Win {
+			session.Wins++
+			lossStreak = 0
+		} else {
+			session.Losses++
+			lossStreak++
+			session.LongestLossStreak = max(session.LongestLossStreak, lossStreak)
+		}
+		if c, ok := lpChanges[g.MatchID]; ok {
+			session.NetLP += c.LPDelta
+			session.LPApproximate = session.LPApproximate || c.Approximate
+		}
+		kills += g.Kills
+		deaths += g.Deaths
+		assists += g.Assists
+		kdas[i] = float64(g.Kills+g.Assists) / float64(max(g.Deaths, 1))
+	}
+	session.KDA = round2(float64(kills+assists) / float64(max(deaths, 1)))
+	session.KDATrend = round2(slope(kdas))
+
+	half := len(games) / 2
+	firstWins, secondWins := 0, 0
+	for i, g := range games {
+		if !g.Win {
+			continue
+		}
+		if i < half {
+			firstWins++
+		} else if i >= len(games)-half {
+			secondWins++
+		}
+	}
+	session.Tilted = len(games) >= tiltMinGames && session.KDATrend <= tiltKDATrend && secondWins < firstWins
+	return session
+}
+
+// slope returns the least squares slope of values over their index.
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x := float64(i)
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
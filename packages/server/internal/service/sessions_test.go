@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSessions(t *testing.T) {
+	const minute = 60_000
+	game := func(id int64, start int64, win bool, kills, deaths, assists int) models.PlayedGame {
+		return models.PlayedGame{
+			MatchID: id, StartedAt: start * minute, Duration: 30 * 60, Win: win, Kills: kills, Deaths: deaths, Assists: assists,
+		}
+	}
+	games := []models.PlayedGame{
+		// A good session of two games.
+		game(1, 0, true, 8, 1, 6),
+		game(2, 40, true, 6, 2, 4),
+		// After a break, a session that goes downhill.
+		game(3, 300, true, 10, 2, 8),
+		game(4, 335, true, 6, 3, 6),
+		game(5, 370, false, 3, 6, 3),
+		game(6, 405, false, 1, 8, 2),
+		game(7, 440, false, 0, 7, 1),
+	}
+	lpChanges := map[int64]models.MatchLPChange{
+		3: {MatchID: 3, LPDelta: 22},
+		4: {MatchID: 4, LPDelta: 21},
+		5: {MatchID: 5, LPDelta: -18, Approximate: true},
+		6: {MatchID: 6, LPDelta: -18, Approximate: true},
+	}
+
+	sessions := splitSessions(games, lpChanges, 45*time.Minute)
+	require.Len(t, sessions, 2)
+
+	tilted := sessions[0]
+	assert.Equal(t, []int64{3, 4, 5, 6, 7}, tilted.MatchIDs)
+	assert.Equal(t, int64(300*minute), tilted.StartedAt)
+	assert.Equal(t, int64(470*minute), tilted.EndedAt)
+	assert.Equal(t, 2, tilted.Wins)
+	assert.Equal(t, 3, tilted.Losses)
+	assert.Equal(t, 3, tilted.LongestLossStreak)
+	assert.Equal(t, 7, tilted.NetLP)
+	assert.True(t, tilted.LPApproximate)
+	assert.Equal(t, 1.54, tilted.KDA)
+	assert.Less(t, tilted.KDATrend, tiltKDATrend)
+	assert.True(t, tilted.Tilted)
+
+	good := sessions[1]
+	assert.Equal(t, []int64{1, 2}, good.MatchIDs)
+	assert.Equal(t, 0, good.NetLP)
+	assert.Zero(t, good.LongestLossStreak)
+	assert.False(t, good.Tilted)
+
+	// A shorter gap splits every game into its own session.
+	assert.Len(t, splitSessions(games, nil, 5*time.Minute), 7)
+}
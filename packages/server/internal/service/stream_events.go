@@ -3,36 +3,52 @@ package service
 import (
 	"fmt"
 
-	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/streaming"
 	"github.com/galchammat/kadeem/internal/twitch/models"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 )
 
 // StreamEventsService manages stream event syncing and retrieval.
 type StreamEventsService struct {
-	db     *twitchstore.Store
-	twitch *twitchapi.TwitchClient
+	db        twitchstore.Store
+	platforms *streaming.Registry
 }
 
 // NewStreamEventsService creates a new StreamEventsService.
-func NewStreamEventsService(db *twitchstore.Store, twitchClient *twitchapi.TwitchClient) *StreamEventsService {
-	return &StreamEventsService{db: db, twitch: twitchClient}
+func NewStreamEventsService(db twitchstore.Store, platforms *streaming.Registry) *StreamEventsService {
+	return &StreamEventsService{db: db, platforms: platforms}
 }
 
-// SyncChannelEvents fetches and persists hype train and clip events for the given channel.
+// SyncChannelEvents fetches and persists the platform events, e.g. hype trains and clips, of
+// the given channel. Only events at or after the newest stored one are fetched, so the view
+// counts of older clips are not refreshed.
 func (s *StreamEventsService) SyncChannelEvents(channelID string) error {
-	hypeEvents, err := s.twitch.FetchHypeTrainEvents(channelID)
+	channels, err := s.db.ListChannels(&models.ChannelFilter{ID: &channelID}, 1, 0)
 	if err != nil {
-		return fmt.Errorf("fetch hype train events for channel %s: %w", channelID, err)
+		return err
+	}
+	if len(channels) == 0 {
+		return fmt.Errorf("channel not found: %s", channelID)
+	}
+	platform, err := s.platforms.Get(channels[0].Platform)
+	if err != nil {
+		return err
 	}
 
-	clipEvents, err := s.twitch.FetchTopClips(channelID)
+	var startTime int64
+	latest, err := s.db.ListStreamEvents(&models.StreamEventFilter{ChannelID: &channelID}, 1, 0)
 	if err != nil {
-		return fmt.Errorf("fetch clip events for channel %s: %w", channelID, err)
+		return fmt.Errorf("list stream events for channel %s: %w", channelID, err)
+	}
+	if len(latest) > 0 {
+		startTime = latest[0].Timestamp
 	}
 
-	all := append(hypeEvents, clipEvents...)
-	if err := s.db.UpsertStreamEvents(all); err != nil {
+	events, err := platform.FetchEvents(channelID, startTime)
+	if err != nil {
+		return fmt.Errorf("fetch stream events for channel %s: %w", channelID, err)
+	}
+	if err := s.db.UpsertStreamEvents(events); err != nil {
 		return fmt.Errorf("upsert stream events for channel %s: %w", channelID, err)
 	}
 	return nil
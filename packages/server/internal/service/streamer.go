@@ -6,18 +6,18 @@ import (
 
 	"github.com/galchammat/kadeem/internal/constants"
 	"github.com/galchammat/kadeem/internal/logging"
-	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/streaming"
 	"github.com/galchammat/kadeem/internal/twitch/models"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 )
 
 type StreamerService struct {
-	db     *twitchstore.Store
-	twitch *twitchapi.TwitchClient
+	db        twitchstore.Store
+	platforms *streaming.Registry
 }
 
-func NewStreamerService(db *twitchstore.Store, twitchClient *twitchapi.TwitchClient) *StreamerService {
-	return &StreamerService{db: db, twitch: twitchClient}
+func NewStreamerService(db twitchstore.Store, platforms *streaming.Registry) *StreamerService {
+	return &StreamerService{db: db, platforms: platforms}
 }
 
 func (s *StreamerService) ListStreamersWithDetails() ([]models.StreamerView, error) {
@@ -62,14 +62,11 @@ func (s *StreamerService) DeleteStreamer(name string) (bool, error) {
 }
 
 func (s *StreamerService) AddChannel(channelInput models.Channel) (bool, error) {
-	var channel models.Channel
-	var err error
-	switch channelInput.Platform {
-	case "twitch":
-		channel, err = s.twitch.FindChannel(channelInput)
-	default:
-		return false, fmt.Errorf("unsupported platform: %s", channelInput.Platform)
+	platform, err := s.platforms.Get(channelInput.Platform)
+	if err != nil {
+		return false, err
 	}
+	channel, err := platform.FindChannel(channelInput)
 	if err != nil {
 		return false, fmt.Errorf("failed to find channel: %w", err)
 	}
@@ -87,14 +84,11 @@ func (s *StreamerService) SyncBroadcasts(channel models.Channel) error {
 	}
 	logging.Info("Syncing broadcasts for channel", "ID", channel.ID, "name", channel.ChannelName)
 
-	var broadcasts []models.Broadcast
-	var err error
-	switch channel.Platform {
-	case "twitch":
-		broadcasts, err = s.twitch.FetchBroadcasts(channel.ID, startTime)
-	default:
-		return fmt.Errorf("unsupported platform: %s", channel.Platform)
+	platform, err := s.platforms.Get(channel.Platform)
+	if err != nil {
+		return err
 	}
+	broadcasts, err := platform.FetchBroadcasts(channel.ID, startTime)
 	if err != nil {
 		return err
 	}
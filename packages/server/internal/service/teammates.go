@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// TeammateService finds who tracked accounts play with, and the games where tracked streamers
+// met each other.
+type TeammateService struct {
+	db riotstore.Store
+}
+
+// NewTeammateService creates a new TeammateService.
+func NewTeammateService(db riotstore.Store) *TeammateService {
+	return &TeammateService{db: db}
+}
+
+// Teammates returns up to limit players who were on the account's team in at least minGames
+// games, most frequent first. Remakes are left out.
+func (s *TeammateService) Teammates(ctx context.Context, puuid string, minGames, limit int) ([]models.Teammate, error) {
+	totals, err := s.db.ListTeammates(ctx, puuid, remakeDuration, max(minGames, 1), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	teammates := make([]models.Teammate, 0, len(totals))
+	for _, t := range totals {
+		teammate := models.Teammate{
+			PUUID:      t.PUUID,
+			GameName:   t.GameName,
+			TagLine:    t.TagLine,
+			Games:      t.Games,
+			Wins:       t.Wins,
+			WinRate:    round2(float64(t.Wins) / float64(t.Games)),
+			LastSeen:   t.LastSeen,
+			StreamerID: t.StreamerID,
+		}
+		if t.StreamerID == nil && t.GameName != "" && t.TagLine != "" && t.Region != "" {
+			teammate.Suggestion = &models.TrackSuggestion{Region: t.Region, GameName: t.GameName, TagLine: t.TagLine}
+		}
+		teammates = append(teammates, teammate)
+	}
+	return teammates, nil
+}
+
+// StreamerMeetings returns the games in which the streamer met another streamer on the same
+// or the opposing team, newest first. Remakes are left out.
+func (s *TeammateService) StreamerMeetings(ctx context.Context, streamerID, limit, offset int) ([]models.StreamerMeeting, error) {
+	return s.db.ListStreamerMeetings(ctx, streamerID, remakeDuration, limit, offset)
+}
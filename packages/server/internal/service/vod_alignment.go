@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+)
+
+// VODAlignmentService finds where a streamer's matches are in their broadcasts.
+type VODAlignmentService struct {
+	db riotstore.Store
+}
+
+// NewVODAlignmentService creates a new VODAlignmentService.
+func NewVODAlignmentService(db riotstore.Store) *VODAlignmentService {
+	return &VODAlignmentService{db: db}
+}
+
+// AlignStreamer recomputes the VOD offsets of every match the streamer played on stream
+// and returns the number of stored alignments.
+func (s *VODAlignmentService) AlignStreamer(streamerID int64) (int, error) {
+	pairs, err := s.db.ListMatchBroadcasts(streamerID)
+	if err != nil {
+		return 0, fmt.Errorf("list match broadcasts for streamer %d: %w", streamerID, err)
+	}
+
+	vods := make([]models.MatchVOD, 0, len(pairs))
+	seen := make(map[[2]int64]bool, len(pairs))
+	for _, pair := range pairs {
+		// Two of the streamer's accounts in one match would yield the same pair twice.
+		key := [2]int64{pair.MatchID, pair.BroadcastID}
+		if seen[key] {
+			continue
+		}
+		vod, ok := alignMatch(pair)
+		if !ok {
+			continue
+		}
+		seen[key] = true
+		vods = append(vods, vod)
+	}
+
+	if err := s.db.ReplaceStreamerMatchVODs(streamerID, vods); err != nil {
+		return 0, err
+	}
+	return len(vods), nil
+}
+
+// ListMatchesOnStream returns the streamer's matches that were found on one of their broadcasts.
+func (s *VODAlignmentService) ListMatchesOnStream(streamerID int64, limit, offset int) ([]models.Match, error) {
+	return s.db.ListLolMatches(&models.MatchFilter{StreamerID: &streamerID}, limit, offset)
+}
+
+// alignMatch computes where the part of the match covered by the broadcast starts in the VOD.
+// A match spanning two broadcasts gets one alignment per broadcast, each with its own offsets.
+func alignMatch(pair models.MatchBroadcast) (models.MatchVOD, bool) {
+	matchStart := pair.StartedAt / 1000
+	matchEnd := matchStart + pair.Duration
+	broadcastStart := pair.BroadcastCreatedAt
+	broadcastEnd := broadcastStart + pair.BroadcastDuration
+
+	start := max(matchStart, broadcastStart)
+	end := min(matchEnd, broadcastEnd)
+	if end <= start {
+		return models.MatchVOD{}, false
+	}
+
+	vodOffset := start - broadcastStart
+	return models.MatchVOD{
+		MatchID:     pair.MatchID,
+		BroadcastID: pair.BroadcastID,
+		StreamerID:  pair.StreamerID,
+		PUUID:       pair.PUUID,
+		VODOffset:   vodOffset,
+		MatchOffset: start - matchStart,
+		Overlap:     end - start,
+		URL:         vodDeepLink(pair.BroadcastURL, vodOffset),
+	}, true
+}
+
+// vodDeepLink appends a Twitch style timestamp (?t=1h23m10s) to the VOD url.
+func vodDeepLink(url string, offset int64) string {
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%st=%dh%dm%ds", url, sep, offset/3600, offset%3600/60, offset%60)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlignMatchInsideBroadcast(t *testing.T) {
+	vod, ok := alignMatch(models.MatchBroadcast{
+		MatchID:            1,
+		StartedAt:          (10_000 + 4990) * 1000,
+		Duration:           1800,
+		BroadcastID:        7,
+		BroadcastURL:       "https://www.twitch.tv/videos/123",
+		BroadcastCreatedAt: 10_000,
+		BroadcastDuration:  4 * 3600,
+	})
+	assert.True(t, ok)
+	assert.Equal(t, int64(4990), vod.VODOffset)
+	assert.Zero(t, vod.MatchOffset)
+	assert.Equal(t, int64(1800), vod.Overlap)
+	assert.Equal(t, "https://www.twitch.tv/videos/123?t=1h23m10s", vod.URL)
+}
+
+func TestAlignMatchSpanningTwoBroadcasts(t *testing.T) {
+	pair := models.MatchBroadcast{
+		StartedAt:          9_000 * 1000,
+		Duration:           2000,
+		BroadcastURL:       "https://www.twitch.tv/videos/1",
+		BroadcastCreatedAt: 5_000,
+		BroadcastDuration:  5_000, // ends at 10000, 1000s into the match
+	}
+	first, ok := alignMatch(pair)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4000), first.VODOffset)
+	assert.Zero(t, first.MatchOffset)
+	assert.Equal(t, int64(1000), first.Overlap)
+
+	pair.BroadcastURL = "https://www.twitch.tv/videos/2"
+	pair.BroadcastCreatedAt = 10_300
+	pair.BroadcastDuration = 3_600
+	second, ok := alignMatch(pair)
+	assert.True(t, ok)
+	assert.Zero(t, second.VODOffset)
+	assert.Equal(t, int64(1300), second.MatchOffset)
+	assert.Equal(t, int64(700), second.Overlap)
+	assert.Equal(t, "https://www.twitch.tv/videos/2?t=0h0m0s", second.URL)
+}
+
+func TestAlignMatchWithoutOverlap(t *testing.T) {
+	_, ok := alignMatch(models.MatchBroadcast{
+		StartedAt:          20_000 * 1000,
+		Duration:           1800,
+		BroadcastCreatedAt: 10_000,
+		BroadcastDuration:  3600,
+	})
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+// Package streaming abstracts the platforms tracked channels stream on.
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	youtubeapi "github.com/galchammat/kadeem/internal/youtube/api"
+)
+
+// StreamingPlatform is a platform channels stream on. Channels, broadcasts, events and live
+// streams of every platform share the models of the twitch/models package; Channel.Platform
+// holds the platform's Name.
+type StreamingPlatform interface {
+	// Name is the platform name channels are stored with, e.g. "twitch".
+	Name() string
+	// FindChannel looks up the channel named by channel.ChannelName, or channel.ID if no
+	// name is set. The result keeps channel.StreamerID.
+	FindChannel(channel models.Channel) (models.Channel, error)
+	// FetchBroadcasts returns the channel's past broadcasts created at or after startTime
+	// (Unix seconds), newest first. A startTime of 0 fetches all of them.
+	FetchBroadcasts(channelID string, startTime int64) ([]models.Broadcast, error)
+	// FetchEvents returns the channel's stream events the platform keeps, e.g. clips, at or
+	// after startTime (Unix seconds). A startTime of 0 fetches all of them.
+	FetchEvents(channelID string, startTime int64) ([]models.StreamEvent, error)
+	// FetchLiveStreams returns the streams of the given channels that are live right now.
+	FetchLiveStreams(channelIDs []string) ([]models.LiveStream, error)
+}
+
+var (
+	_ StreamingPlatform = (*twitchapi.TwitchClient)(nil)
+	_ StreamingPlatform = (*youtubeapi.YouTubeClient)(nil)
+)
+
+// Registry holds the platforms channels can be added on, by name.
+type Registry struct {
+	platforms []StreamingPlatform
+}
+
+// NewRegistry creates a Registry of platforms. All returns them in this order.
+func NewRegistry(platforms ...StreamingPlatform) *Registry {
+	return &Registry{platforms: platforms}
+}
+
+// NewRegistryFromEnv registers twitchClient, and YouTube if YOUTUBE_API_KEY is set.
+func NewRegistryFromEnv(ctx context.Context, twitchClient *twitchapi.TwitchClient) *Registry {
+	platforms := []StreamingPlatform{twitchClient}
+	if os.Getenv("YOUTUBE_API_KEY") != "" {
+		platforms = append(platforms, youtubeapi.NewYouTubeClient(ctx))
+	} else {
+		logging.Info("YOUTUBE_API_KEY not set, YouTube channels are not supported")
+	}
+	return NewRegistry(platforms...)
+}
+
+// Get returns the platform named name.
+func (r *Registry) Get(name string) (StreamingPlatform, error) {
+	for _, p := range r.platforms {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported platform: %s", name)
+}
+
+// All returns every registered platform.
+func (r *Registry) All() []StreamingPlatform {
+	return r.platforms
+}
@@ -1,43 +1,26 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/url"
 
-	"github.com/galchammat/kadeem/internal/logging"
 	"github.com/galchammat/kadeem/internal/twitch/models"
 )
 
+// FetchBroadcasts fetches the channel's past broadcasts, newest first. Paging stops at the
+// first broadcast created before startTime (Unix seconds); a startTime of 0 backfills every
+// broadcast Twitch still exposes.
 func (c *TwitchClient) FetchBroadcasts(channelID string, startTime int64) ([]models.Broadcast, error) {
-	// Build query params
 	params := url.Values{}
 	params.Set("user_id", channelID)
-	params.Set("first", "100")
 	params.Set("type", "archive")
+	params.Set("first", maxPageSize)
 
-	// Construct full URL
-	endpoint := "/videos?" + params.Encode()
-
-	response, statusCode, err := c.makeRequest(endpoint)
-	if err != nil || statusCode != 200 {
-		return []models.Broadcast{}, fmt.Errorf("failed to fetch broadcasts: status=%d, error=%w", statusCode, err)
-	}
-
-	var rawMessages []json.RawMessage
-	if err := json.Unmarshal(response.Data, &rawMessages); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal broadcasts: %w", err)
+	broadcasts, err := paginate(c, "/videos", params, func(b models.Broadcast) bool {
+		return b.CreatedAt < startTime
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch broadcasts: %w", err)
 	}
-
-	broadcasts := make([]models.Broadcast, 0, len(rawMessages))
-	for _, raw := range rawMessages {
-		var b models.Broadcast
-		if err := json.Unmarshal(raw, &b); err != nil {
-			logging.Warn("Failed to unmarshal broadcast", "error", err)
-			continue
-		}
-		broadcasts = append(broadcasts, b)
-	}
-
 	return broadcasts, nil
 }
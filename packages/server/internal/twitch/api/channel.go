@@ -41,7 +41,7 @@ func (c *TwitchClient) FindChannel(streamInput models.Channel) (models.Channel,
 		if strings.EqualFold(ch.DisplayName, query) {
 			result := models.Channel{
 				StreamerID:  streamInput.StreamerID,
-				Platform:    "twitch",
+				Platform:    models.PlatformTwitch,
 				ChannelName: ch.DisplayName,
 				ID:          ch.ID,
 				AvatarURL:   ch.AvatarURL,
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/galchammat/kadeem/internal/logging"
 	"github.com/galchammat/kadeem/internal/twitch/models"
@@ -14,50 +16,135 @@ import (
 	clientcredentials "golang.org/x/oauth2/clientcredentials"
 )
 
+// Default Twitch endpoints, see WithBaseURL and WithTokenURL.
+const (
+	defaultBaseURL  = "https://api.twitch.tv/helix"
+	defaultTokenURL = "https://id.twitch.tv/oauth2/token"
+)
+
 type TwitchClient struct {
 	ctx        context.Context
 	httpClient *http.Client
 	baseUrl    string
+	maxPages   int
+}
+
+type clientConfig struct {
+	clientID     string
+	clientSecret string
+	baseURL      string
+	tokenURL     string
+	maxPages     int
 }
 
-func NewTwitchClient(ctx context.Context) *TwitchClient {
-	clientID := os.Getenv("TWITCH_CLIENT_ID")
-	clientSecret := os.Getenv("TWITCH_CLIENT_SECRET")
+// ClientOption configures a TwitchClient.
+type ClientOption func(*clientConfig)
+
+// WithBaseURL sends Helix requests to baseURL (default https://api.twitch.tv/helix).
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *clientConfig) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithTokenURL fetches app access tokens from tokenURL (default https://id.twitch.tv/oauth2/token).
+func WithTokenURL(tokenURL string) ClientOption {
+	return func(c *clientConfig) {
+		c.tokenURL = tokenURL
+	}
+}
+
+// WithCredentials replaces the TWITCH_CLIENT_ID and TWITCH_CLIENT_SECRET credentials.
+func WithCredentials(clientID, clientSecret string) ClientOption {
+	return func(c *clientConfig) {
+		c.clientID = clientID
+		c.clientSecret = clientSecret
+	}
+}
+
+// WithMaxPages limits how many pages a single fetch follows (default 50, 0 for no limit).
+func WithMaxPages(maxPages int) ClientOption {
+	return func(c *clientConfig) {
+		c.maxPages = maxPages
+	}
+}
+
+func NewTwitchClient(ctx context.Context, opts ...ClientOption) *TwitchClient {
+	cfg := clientConfig{
+		clientID:     os.Getenv("TWITCH_CLIENT_ID"),
+		clientSecret: os.Getenv("TWITCH_CLIENT_SECRET"),
+		baseURL:      defaultBaseURL,
+		tokenURL:     defaultTokenURL,
+		maxPages:     defaultMaxPages,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
 	conf := clientcredentials.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		TokenURL:     "https://id.twitch.tv/oauth2/token",
+		ClientID:     cfg.clientID,
+		ClientSecret: cfg.clientSecret,
+		TokenURL:     cfg.tokenURL,
 	}
 	httpClient := conf.Client(ctx)
 
 	// ensure transport exists and wrap it to inject Client-ID header required by Twitch
-	if clientID != "" {
+	if cfg.clientID != "" {
 		base := httpClient.Transport
 		if base == nil {
 			base = http.DefaultTransport
 		}
-		httpClient.Transport = &clientIDTransport{base: base, clientID: clientID}
+		httpClient.Transport = &clientIDTransport{base: base, clientID: cfg.clientID}
 	}
 
 	return &TwitchClient{
 		ctx:        ctx,
 		httpClient: httpClient,
-		baseUrl:    "https://api.twitch.tv/helix",
+		baseUrl:    cfg.baseURL,
+		maxPages:   cfg.maxPages,
 	}
 }
 
+// Name returns the platform name channels on Twitch are stored with.
+func (c *TwitchClient) Name() string {
+	return models.PlatformTwitch
+}
+
+// WithContext returns a copy of c whose requests use ctx, e.g. to bound a backfill with a deadline.
+func (c *TwitchClient) WithContext(ctx context.Context) *TwitchClient {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
 func (c *TwitchClient) buildURL(endpoint string) string {
 	return fmt.Sprintf("%s%s", c.baseUrl, endpoint)
 }
 
 func (c *TwitchClient) makeRequest(endpoint string) (*models.APIResponse, int, error) {
+	return c.send(http.MethodGet, endpoint, nil)
+}
+
+// send makes a Helix request with body, if not nil, encoded as JSON. Any 2xx status is a
+// success; a response without content yields an empty APIResponse.
+func (c *TwitchClient) send(method, endpoint string, body any) (*models.APIResponse, int, error) {
 	url := c.buildURL(endpoint)
-	req, err := http.NewRequestWithContext(c.ctx, "GET", url, nil)
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(c.ctx, method, url, reqBody)
 	if err != nil {
 		logging.Error("Failed to create Twitch HTTP request", "endpoint", endpoint, "error", err)
 		return nil, 0, err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -66,20 +153,23 @@ func (c *TwitchClient) makeRequest(endpoint string) (*models.APIResponse, int, e
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logging.Error("Failed to read Twitch response body", "url", url, "error", err)
 		return nil, 0, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("HTTP request failed with status %d. body %s", resp.StatusCode, string(body))
-		logging.Error("Twitch API returned non-200 status", "url", url, "statusCode", resp.StatusCode, "body", string(body))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("HTTP request failed with status %d. body %s", resp.StatusCode, string(respBody))
+		logging.Error("Twitch API returned non-2xx status", "method", method, "url", url, "statusCode", resp.StatusCode, "body", string(respBody))
 		return nil, resp.StatusCode, err
 	}
 
 	var response models.APIResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	if len(respBody) == 0 {
+		return &response, resp.StatusCode, nil
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
 		logging.Error("Failed to unmarshal Twitch response", "url", url, "error", err)
 		return nil, resp.StatusCode, err
 	}
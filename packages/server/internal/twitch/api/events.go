@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -35,32 +34,38 @@ type clipItem struct {
 	Duration    float64 `json:"duration"`
 }
 
-// FetchHypeTrainEvents fetches ended hype train events for the given broadcaster.
-func (c *TwitchClient) FetchHypeTrainEvents(broadcasterID string) ([]models.StreamEvent, error) {
+// FetchEvents fetches the broadcaster's hype train and clip events at or after startTime (Unix
+// seconds). A startTime of 0 fetches every event Twitch keeps.
+func (c *TwitchClient) FetchEvents(broadcasterID string, startTime int64) ([]models.StreamEvent, error) {
+	hypeEvents, err := c.FetchHypeTrainEvents(broadcasterID, startTime)
+	if err != nil {
+		return nil, err
+	}
+	clipEvents, err := c.FetchTopClips(broadcasterID, startTime)
+	if err != nil {
+		return nil, err
+	}
+	return append(hypeEvents, clipEvents...), nil
+}
+
+// FetchHypeTrainEvents fetches the ended hype train events of the given broadcaster. Twitch
+// returns them newest first, so paging stops at the first event before startTime (Unix
+// seconds); a startTime of 0 fetches every event Twitch keeps.
+func (c *TwitchClient) FetchHypeTrainEvents(broadcasterID string, startTime int64) ([]models.StreamEvent, error) {
 	params := url.Values{}
 	params.Set("broadcaster_id", broadcasterID)
-	params.Set("first", "100")
+	params.Set("first", maxPageSize)
 
-	response, statusCode, err := c.makeRequest("/helix/hypetrain/events?" + params.Encode())
+	items, err := paginate(c, "/hypetrain/events", params, func(item hypeTrainItem) bool {
+		ts, err := time.Parse(time.RFC3339, item.EventTimestamp)
+		return err == nil && ts.Unix() < startTime
+	})
 	if err != nil {
 		return nil, fmt.Errorf("fetch hype train events: %w", err)
 	}
-	if statusCode != 200 {
-		return nil, fmt.Errorf("fetch hype train events: unexpected status %d", statusCode)
-	}
-
-	var rawMessages []json.RawMessage
-	if err := json.Unmarshal(response.Data, &rawMessages); err != nil {
-		return nil, fmt.Errorf("unmarshal hype train events: %w", err)
-	}
 
 	var events []models.StreamEvent
-	for _, raw := range rawMessages {
-		var item hypeTrainItem
-		if err := json.Unmarshal(raw, &item); err != nil {
-			logging.Warn("failed to unmarshal hype train item", "error", err)
-			continue
-		}
+	for _, item := range items {
 		if item.EventType != "hypetrain.end" {
 			continue
 		}
@@ -92,32 +97,25 @@ func (c *TwitchClient) FetchHypeTrainEvents(broadcasterID string) ([]models.Stre
 	return events, nil
 }
 
-// FetchTopClips fetches recent top clips for the given broadcaster.
-func (c *TwitchClient) FetchTopClips(broadcasterID string) ([]models.StreamEvent, error) {
+// FetchTopClips fetches the broadcaster's clips created at or after startTime (Unix seconds),
+// most viewed first, up to the client's page limit. A startTime of 0 fetches every clip.
+func (c *TwitchClient) FetchTopClips(broadcasterID string, startTime int64) ([]models.StreamEvent, error) {
 	params := url.Values{}
 	params.Set("broadcaster_id", broadcasterID)
-	params.Set("first", "20")
+	params.Set("first", maxPageSize)
+	if startTime > 0 {
+		// Without ended_at Twitch only returns the week after started_at.
+		params.Set("started_at", time.Unix(startTime, 0).UTC().Format(time.RFC3339))
+		params.Set("ended_at", time.Now().UTC().Format(time.RFC3339))
+	}
 
-	response, statusCode, err := c.makeRequest("/helix/clips?" + params.Encode())
+	items, err := paginate[clipItem](c, "/clips", params, nil)
 	if err != nil {
 		return nil, fmt.Errorf("fetch clips: %w", err)
 	}
-	if statusCode != 200 {
-		return nil, fmt.Errorf("fetch clips: unexpected status %d", statusCode)
-	}
-
-	var rawMessages []json.RawMessage
-	if err := json.Unmarshal(response.Data, &rawMessages); err != nil {
-		return nil, fmt.Errorf("unmarshal clips: %w", err)
-	}
 
 	var events []models.StreamEvent
-	for _, raw := range rawMessages {
-		var item clipItem
-		if err := json.Unmarshal(raw, &item); err != nil {
-			logging.Warn("failed to unmarshal clip item", "error", err)
-			continue
-		}
+	for _, item := range items {
 
 		ts, err := time.Parse(time.RFC3339, item.CreatedAt)
 		if err != nil {
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// CreateEventSubSubscription subscribes callback to events of subType for the broadcaster.
+// Twitch verifies the callback with a challenge before the subscription is enabled, and
+// signs every message with secret.
+func (c *TwitchClient) CreateEventSubSubscription(subType, version, broadcasterID, callback, secret string) (models.EventSubSubscription, error) {
+	request := models.EventSubSubscription{
+		Type:      subType,
+		Version:   version,
+		Condition: models.EventSubCondition{BroadcasterUserID: broadcasterID},
+		Transport: models.EventSubTransport{Method: "webhook", Callback: callback, Secret: secret},
+	}
+	response, statusCode, err := c.send(http.MethodPost, "/eventsub/subscriptions", request)
+	if err != nil {
+		return models.EventSubSubscription{}, fmt.Errorf("create %s subscription: status=%d: %w", subType, statusCode, err)
+	}
+
+	var created []models.EventSubSubscription
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		return models.EventSubSubscription{}, fmt.Errorf("unmarshal %s subscription: %w", subType, err)
+	}
+	if len(created) == 0 {
+		return models.EventSubSubscription{}, fmt.Errorf("create %s subscription: empty response", subType)
+	}
+	return created[0], nil
+}
+
+// ListEventSubSubscriptions returns every EventSub subscription of the app.
+func (c *TwitchClient) ListEventSubSubscriptions() ([]models.EventSubSubscription, error) {
+	subscriptions, err := paginate[models.EventSubSubscription](c, "/eventsub/subscriptions", url.Values{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list eventsub subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// DeleteEventSubSubscription removes the subscription with the given ID.
+func (c *TwitchClient) DeleteEventSubSubscription(id string) error {
+	params := url.Values{}
+	params.Set("id", id)
+	if _, statusCode, err := c.send(http.MethodDelete, "/eventsub/subscriptions?"+params.Encode(), nil); err != nil {
+		return fmt.Errorf("delete eventsub subscription %s: status=%d: %w", id, statusCode, err)
+	}
+	return nil
+}
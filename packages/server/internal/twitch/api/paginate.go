@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/galchammat/kadeem/internal/logging"
+)
+
+// defaultMaxPages bounds how many pages a single fetch follows, see WithMaxPages.
+const defaultMaxPages = 50
+
+// maxPageSize is the largest page /videos, /clips and /hypetrain/events serve.
+const maxPageSize = "100"
+
+// paginate requests endpoint with params and follows Pagination.Cursor until Twitch stops
+// returning one, stop reports true for an item, c.maxPages pages were read or the client's
+// context is done. The item that stop reports and everything after it are dropped. The page
+// size is left to the caller, as "first" in params, since not every endpoint takes one.
+//
+// Running into the page limit or the context deadline after the first page is not an error;
+// the items read so far are returned so a long backfill still makes progress.
+func paginate[T any](c *TwitchClient, endpoint string, params url.Values, stop func(T) bool) ([]T, error) {
+	var items []T
+	for page := 0; ; page++ {
+		if c.maxPages > 0 && page == c.maxPages {
+			logging.Warn("Stopped following Twitch cursor at page limit", "endpoint", endpoint, "pages", page, "items", len(items))
+			return items, nil
+		}
+
+		response, statusCode, err := c.makeRequest(endpoint + "?" + params.Encode())
+		if err != nil {
+			if page > 0 && c.ctx.Err() != nil {
+				logging.Warn("Stopped following Twitch cursor at context deadline", "endpoint", endpoint, "pages", page, "items", len(items))
+				return items, nil
+			}
+			return nil, fmt.Errorf("request %s page %d: status=%d: %w", endpoint, page+1, statusCode, err)
+		}
+
+		var rawMessages []json.RawMessage
+		if err := json.Unmarshal(response.Data, &rawMessages); err != nil {
+			return nil, fmt.Errorf("unmarshal %s page %d: %w", endpoint, page+1, err)
+		}
+
+		for _, raw := range rawMessages {
+			var item T
+			if err := json.Unmarshal(raw, &item); err != nil {
+				logging.Warn("Failed to unmarshal Twitch item", "endpoint", endpoint, "error", err)
+				continue
+			}
+			if stop != nil && stop(item) {
+				return items, nil
+			}
+			items = append(items, item)
+		}
+
+		if response.Pagination == nil || response.Pagination.Cursor == "" {
+			return items, nil
+		}
+		params.Set("after", response.Pagination.Cursor)
+	}
+}
@@ -0,0 +1,140 @@
+// External test package: twitchtest imports api.
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/galchammat/kadeem/internal/twitch/twitchtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const channelID = "100000001"
+
+var firstVideo = time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+
+// newServer serves n archive videos, one a day after firstVideo, newest first, and n clips.
+func newServer(t *testing.T, n int) *twitchtest.Server {
+	t.Helper()
+	s := twitchtest.NewServer()
+	t.Cleanup(s.Close)
+
+	videos := make([]json.RawMessage, 0, n)
+	clips := make([]json.RawMessage, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		created := firstVideo.AddDate(0, 0, i).Format(time.RFC3339)
+		videos = append(videos, json.RawMessage(fmt.Sprintf(
+			`{"id":"%d","user_id":%q,"type":"archive","title":"day %d","url":"https://www.twitch.tv/videos/%d","viewable":"public","created_at":%q,"published_at":%q,"duration":"1h0m0s"}`,
+			3000000000+i, channelID, i, 3000000000+i, created, created)))
+		clips = append(clips, json.RawMessage(fmt.Sprintf(
+			`{"id":"clip-%d","broadcaster_id":%q,"title":"clip %d","view_count":%d,"created_at":%q,"creator_name":"viewer","duration":30}`,
+			i, channelID, i, i, created)))
+	}
+	require.NoError(t, s.Add(twitchtest.Videos, videos...))
+	require.NoError(t, s.Add(twitchtest.Clips, clips...))
+	return s
+}
+
+func TestFetchBroadcastsFollowsCursor(t *testing.T) {
+	s := newServer(t, 250)
+
+	broadcasts, err := s.Client(context.Background()).FetchBroadcasts(channelID, 0)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 250)
+	assert.Equal(t, firstVideo.AddDate(0, 0, 249).Unix(), broadcasts[0].CreatedAt)
+	assert.Equal(t, firstVideo.Unix(), broadcasts[249].CreatedAt)
+	assert.Equal(t, 3, s.Hits("/helix/videos"))
+}
+
+func TestFetchBroadcastsStopsAtWatermark(t *testing.T) {
+	s := newServer(t, 250)
+
+	startTime := firstVideo.AddDate(0, 0, 120).Unix()
+	broadcasts, err := s.Client(context.Background()).FetchBroadcasts(channelID, startTime)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 130)
+	assert.Equal(t, startTime, broadcasts[129].CreatedAt)
+	assert.Equal(t, 2, s.Hits("/helix/videos"))
+}
+
+func TestFetchStopsAtMaxPages(t *testing.T) {
+	s := newServer(t, 250)
+	client := s.Client(context.Background(), api.WithMaxPages(2))
+
+	broadcasts, err := client.FetchBroadcasts(channelID, 0)
+	require.NoError(t, err)
+	assert.Len(t, broadcasts, 200)
+
+	clips, err := client.FetchTopClips(channelID, 0)
+	require.NoError(t, err)
+	assert.Len(t, clips, 200)
+	assert.Equal(t, models.StreamEventClip, clips[0].EventType)
+}
+
+func TestFetchUsesClientContext(t *testing.T) {
+	s := newServer(t, 250)
+	client := s.Client(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.WithContext(ctx).FetchBroadcasts(channelID, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	broadcasts, err := client.FetchBroadcasts(channelID, 0)
+	require.NoError(t, err)
+	assert.Len(t, broadcasts, 250)
+}
+
+func TestFetchHypeTrainEvents(t *testing.T) {
+	s := twitchtest.NewServer()
+	t.Cleanup(s.Close)
+	require.NoError(t, s.LoadFixtures("../../../tests/data/twitch"))
+
+	events, err := s.Client(context.Background()).FetchHypeTrainEvents(channelID, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for _, e := range events {
+		assert.Equal(t, models.StreamEventHypeTrain, e.EventType)
+		assert.Equal(t, channelID, e.ChannelID)
+	}
+
+	startTime := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC).Unix()
+	events, err = s.Client(context.Background()).FetchHypeTrainEvents(channelID, startTime)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, time.Date(2026, 1, 5, 20, 5, 12, 0, time.UTC).Unix(), events[0].Timestamp)
+}
+
+func TestFetchTopClipsStartsAtWatermark(t *testing.T) {
+	s := newServer(t, 250)
+
+	startTime := firstVideo.AddDate(0, 0, 240).Unix()
+	clips, err := s.Client(context.Background()).FetchTopClips(channelID, startTime)
+	require.NoError(t, err)
+	require.Len(t, clips, 10)
+	for _, c := range clips {
+		assert.GreaterOrEqual(t, c.Timestamp, startTime)
+	}
+	assert.Equal(t, 1, s.Hits("/helix/clips"))
+}
+
+func TestListEventSubSubscriptionsSendsNoPageSize(t *testing.T) {
+	s := twitchtest.NewServer()
+	t.Cleanup(s.Close)
+	client := s.Client(context.Background())
+
+	for _, subType := range []string{"stream.online", "stream.offline"} {
+		_, err := client.CreateEventSubSubscription(subType, "1", channelID, "https://example.com/eventsub", "0123456789abcdef")
+		require.NoError(t, err)
+	}
+
+	subscriptions, err := client.ListEventSubSubscriptions()
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 2)
+}
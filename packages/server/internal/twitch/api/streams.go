@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// maxStreamUsers is how many user_id parameters Helix /streams accepts per request.
+const maxStreamUsers = 100
+
+// FetchLiveStreams returns the streams of the given channels that are live right now.
+// Channels that are offline are simply missing from the result.
+func (c *TwitchClient) FetchLiveStreams(channelIDs []string) ([]models.LiveStream, error) {
+	var streams []models.LiveStream
+	for start := 0; start < len(channelIDs); start += maxStreamUsers {
+		params := url.Values{}
+		params.Set("type", "live")
+		params["user_id"] = channelIDs[start:min(start+maxStreamUsers, len(channelIDs))]
+
+		page, err := paginate[models.LiveStream](c, "/streams", params, nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetch live streams: %w", err)
+		}
+		streams = append(streams, page...)
+	}
+	return streams, nil
+}
@@ -0,0 +1,104 @@
+package artifact
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// segment is one media segment of an HLS playlist, Start seconds into the video.
+type segment struct {
+	URL      string
+	Start    float64
+	Duration float64
+}
+
+// bestVariant returns the URL of the variant of a master playlist with the highest bandwidth.
+func bestVariant(playlistURL, playlist string) (string, error) {
+	var best string
+	bestBandwidth := -1
+	lines := strings.Split(playlist, "\n")
+	for i, line := range lines {
+		attrs, ok := strings.CutPrefix(strings.TrimSpace(line), "#EXT-X-STREAM-INF:")
+		if !ok {
+			continue
+		}
+		bandwidth := 0
+		for _, attr := range strings.Split(attrs, ",") {
+			if v, ok := strings.CutPrefix(attr, "BANDWIDTH="); ok {
+				bandwidth, _ = strconv.Atoi(v)
+			}
+		}
+		for _, next := range lines[i+1:] {
+			next = strings.TrimSpace(next)
+			if next == "" || strings.HasPrefix(next, "#") {
+				continue
+			}
+			if bandwidth > bestBandwidth {
+				best, bestBandwidth = next, bandwidth
+			}
+			break
+		}
+	}
+	if best == "" {
+		return "", errors.New("master playlist has no variants")
+	}
+	return resolve(playlistURL, best)
+}
+
+// parseMediaPlaylist returns the segments of a media playlist with absolute URLs.
+func parseMediaPlaylist(playlistURL, playlist string) ([]segment, error) {
+	var segments []segment
+	var start float64
+	duration := -1.0
+	for _, line := range strings.Split(playlist, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			raw, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			d, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid segment duration %q", raw)
+			}
+			duration = d
+		case line == "" || strings.HasPrefix(line, "#"):
+		case duration >= 0:
+			segmentURL, err := resolve(playlistURL, line)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{URL: segmentURL, Start: start, Duration: duration})
+			start += duration
+			duration = -1
+		}
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("media playlist has no segments")
+	}
+	return segments, nil
+}
+
+// segmentsBetween returns the segments that overlap the span from start to end (seconds).
+func segmentsBetween(segments []segment, start, end float64) []segment {
+	var selected []segment
+	for _, seg := range segments {
+		if seg.Start < end && seg.Start+seg.Duration > start {
+			selected = append(selected, seg)
+		}
+	}
+	return selected
+}
+
+func resolve(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid playlist URL: %w", err)
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid playlist entry %q: %w", ref, err)
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
@@ -0,0 +1,76 @@
+// Package artifact downloads raw footage from Twitch: clips, and segments of VODs.
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// ErrTooLarge is returned when a download exceeds its size cap.
+var ErrTooLarge = errors.New("artifact exceeds the size cap")
+
+// ErrUnavailable is returned when Twitch will not hand out the footage, because it is gone or
+// the request was refused. Retrying does not help.
+var ErrUnavailable = errors.New("artifact is unavailable")
+
+// Source downloads footage into w.
+type Source interface {
+	Clip(ctx context.Context, slug string, w io.Writer) error
+	// VODSegment writes the part of the video from start to start+duration (seconds). The
+	// segment is cut on HLS segment boundaries, so it may start and end a few seconds early
+	// and late.
+	VODSegment(ctx context.Context, videoID string, start, duration int64, w io.Writer) error
+}
+
+// Key is where a downloaded artifact is stored, below the artifacts/ prefix.
+func Key(a models.Artifact) string {
+	if a.Kind == models.ArtifactClip {
+		return fmt.Sprintf("artifacts/clips/%s.mp4", url.PathEscape(a.SourceID))
+	}
+	return fmt.Sprintf("artifacts/vods/%s-%d-%d.ts", url.PathEscape(a.SourceID), a.StartOffset, a.Duration)
+}
+
+// StagingDir is where artifacts are written while they download: $BIN_DIR/artifacts-partial,
+// or bin/artifacts-partial.
+func StagingDir() string {
+	if binDir := os.Getenv("BIN_DIR"); binDir != "" {
+		return filepath.Join(binDir, "artifacts-partial")
+	}
+	return filepath.Join("bin", "artifacts-partial")
+}
+
+var videoURL = regexp.MustCompile(`^https?://(?:www\.)?twitch\.tv/videos/(\d+)`)
+
+// VideoID returns the video ID of a Twitch VOD URL such as https://www.twitch.tv/videos/123.
+func VideoID(vodURL string) (string, bool) {
+	m := videoURL.FindStringSubmatch(vodURL)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// LimitWriter writes to w until more than max bytes were written, then fails with ErrTooLarge.
+// A max of 0 means no cap.
+type LimitWriter struct {
+	W       io.Writer
+	Max     int64
+	Written int64
+}
+
+func (l *LimitWriter) Write(p []byte) (int, error) {
+	if l.Max > 0 && l.Written+int64(len(p)) > l.Max {
+		return 0, ErrTooLarge
+	}
+	n, err := l.W.Write(p)
+	l.Written += int64(n)
+	return n, err
+}
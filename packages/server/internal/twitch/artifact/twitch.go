@@ -0,0 +1,259 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default Twitch endpoints, see WithGQLURL and WithUsherURL.
+const (
+	defaultGQLURL   = "https://gql.twitch.tv/gql"
+	defaultUsherURL = "https://usher.ttvnw.net"
+)
+
+// ErrNoClientID is returned by NewTwitchSource when TWITCH_GQL_CLIENT_ID is not set.
+var ErrNoClientID = errors.New("TWITCH_GQL_CLIENT_ID is not set")
+
+// TwitchSource downloads clips and VOD segments from Twitch with website playback tokens.
+//
+// Helix has no way to download clips or VODs, so the tokens come from the GQL API of the
+// Twitch website. That API is undocumented and may change or refuse requests at any time:
+// Twitch answers retired queries with 410 Gone and requests it does not trust with integrity
+// errors. Both, like deleted clips and videos, fail the download with ErrUnavailable.
+type TwitchSource struct {
+	httpClient *http.Client
+	gqlURL     string
+	usherURL   string
+	clientID   string
+}
+
+// SourceOption configures a TwitchSource.
+type SourceOption func(*TwitchSource)
+
+// WithGQLURL sends GQL requests to gqlURL (default https://gql.twitch.tv/gql).
+func WithGQLURL(gqlURL string) SourceOption {
+	return func(s *TwitchSource) {
+		s.gqlURL = gqlURL
+	}
+}
+
+// WithUsherURL fetches VOD playlists from usherURL (default https://usher.ttvnw.net).
+func WithUsherURL(usherURL string) SourceOption {
+	return func(s *TwitchSource) {
+		s.usherURL = strings.TrimSuffix(usherURL, "/")
+	}
+}
+
+// NewTwitchSource creates a TwitchSource that sends TWITCH_GQL_CLIENT_ID as its client ID, or
+// fails with ErrNoClientID if it is not set.
+func NewTwitchSource(opts ...SourceOption) (*TwitchSource, error) {
+	clientID := os.Getenv("TWITCH_GQL_CLIENT_ID")
+	if clientID == "" {
+		return nil, ErrNoClientID
+	}
+	s := &TwitchSource{
+		httpClient: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 30 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+		}},
+		gqlURL:   defaultGQLURL,
+		usherURL: defaultUsherURL,
+		clientID: clientID,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+type accessToken struct {
+	Signature string `json:"signature"`
+	Value     string `json:"value"`
+}
+
+const clipQuery = `query($slug: ID!) {
+	clip(slug: $slug) {
+		playbackAccessToken(params: {platform: "web", playerType: "site"}) { signature value }
+		videoQualities { quality sourceURL }
+	}
+}`
+
+const videoTokenQuery = `query($id: ID!) {
+	videoPlaybackAccessToken(id: $id, params: {platform: "web", playerBackend: "mediaplayer", playerType: "site"}) { signature value }
+}`
+
+// Clip writes the clip in its best quality to w.
+func (s *TwitchSource) Clip(ctx context.Context, slug string, w io.Writer) error {
+	var data struct {
+		Clip *struct {
+			PlaybackAccessToken accessToken `json:"playbackAccessToken"`
+			VideoQualities      []struct {
+				Quality   string `json:"quality"`
+				SourceURL string `json:"sourceURL"`
+			} `json:"videoQualities"`
+		} `json:"clip"`
+	}
+	if err := s.gql(ctx, clipQuery, map[string]any{"slug": slug}, &data); err != nil {
+		return fmt.Errorf("get clip %s: %w", slug, err)
+	}
+	if data.Clip == nil || len(data.Clip.VideoQualities) == 0 {
+		return fmt.Errorf("%w: clip %s not found", ErrUnavailable, slug)
+	}
+
+	best, bestQuality := "", -1
+	for _, q := range data.Clip.VideoQualities {
+		if quality, _ := strconv.Atoi(q.Quality); quality > bestQuality {
+			best, bestQuality = q.SourceURL, quality
+		}
+	}
+	token := data.Clip.PlaybackAccessToken
+	sourceURL, err := withToken(best, token)
+	if err != nil {
+		return fmt.Errorf("clip %s: %w", slug, err)
+	}
+	return s.copy(ctx, sourceURL, w)
+}
+
+// VODSegment writes the HLS segments of the video that overlap the requested span, in its
+// best quality, to w.
+func (s *TwitchSource) VODSegment(ctx context.Context, videoID string, start, duration int64, w io.Writer) error {
+	var data struct {
+		Token *accessToken `json:"videoPlaybackAccessToken"`
+	}
+	if err := s.gql(ctx, videoTokenQuery, map[string]any{"id": videoID}, &data); err != nil {
+		return fmt.Errorf("get playback token of video %s: %w", videoID, err)
+	}
+	if data.Token == nil {
+		return fmt.Errorf("%w: video %s not found", ErrUnavailable, videoID)
+	}
+
+	query := url.Values{}
+	query.Set("allow_source", "true")
+	query.Set("sig", data.Token.Signature)
+	query.Set("token", data.Token.Value)
+	masterURL := fmt.Sprintf("%s/vod/%s.m3u8?%s", s.usherURL, url.PathEscape(videoID), query.Encode())
+
+	master, err := s.get(ctx, masterURL)
+	if err != nil {
+		return fmt.Errorf("get playlists of video %s: %w", videoID, err)
+	}
+	mediaURL, err := bestVariant(masterURL, master)
+	if err != nil {
+		return fmt.Errorf("video %s: %w", videoID, err)
+	}
+	media, err := s.get(ctx, mediaURL)
+	if err != nil {
+		return fmt.Errorf("get media playlist of video %s: %w", videoID, err)
+	}
+	segments, err := parseMediaPlaylist(mediaURL, media)
+	if err != nil {
+		return fmt.Errorf("video %s: %w", videoID, err)
+	}
+
+	selected := segmentsBetween(segments, float64(start), float64(start+duration))
+	if len(selected) == 0 {
+		return fmt.Errorf("video %s has no footage between %ds and %ds", videoID, start, start+duration)
+	}
+	for _, seg := range selected {
+		if err := s.copy(ctx, seg.URL, w); err != nil {
+			return fmt.Errorf("video %s: %w", videoID, err)
+		}
+	}
+	return nil
+}
+
+func (s *TwitchSource) gql(ctx context.Context, query string, variables map[string]any, data any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.gqlURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Client-ID", s.clientID)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%w: GQL request failed with status %d. body %s", ErrUnavailable, resp.StatusCode, string(respBody))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GQL request failed with status %d. body %s", resp.StatusCode, string(respBody))
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("invalid GQL response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		msg := envelope.Errors[0].Message
+		if strings.Contains(strings.ToLower(msg), "integrity") {
+			return fmt.Errorf("%w: GQL error: %s", ErrUnavailable, msg)
+		}
+		return fmt.Errorf("GQL error: %s", msg)
+	}
+	return json.Unmarshal(envelope.Data, data)
+}
+
+func (s *TwitchSource) get(ctx context.Context, rawURL string) (string, error) {
+	var buf bytes.Buffer
+	if err := s.copy(ctx, rawURL, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (s *TwitchSource) copy(ctx context.Context, rawURL string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s failed with status code %d", req.URL.Path, resp.StatusCode)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// withToken appends a playback access token to a clip source URL.
+func withToken(sourceURL string, token accessToken) (string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid source URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("sig", token.Signature)
+	query.Set("token", token.Value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,163 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMasterPlaylist = `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1500000,RESOLUTION=852x480
+480p/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=6000000,RESOLUTION=1920x1080
+chunked/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=3000000,RESOLUTION=1280x720
+720p/index.m3u8
+`
+
+const testMediaPlaylist = `#EXTM3U
+#EXT-X-TARGETDURATION:10
+#EXTINF:10.000,
+0.ts
+#EXTINF:10.000,
+1.ts
+#EXTINF:10.000,
+2.ts
+#EXTINF:10.000,
+3.ts
+#EXTINF:4.500,
+4.ts
+#EXT-X-ENDLIST
+`
+
+func fakeTwitch(t *testing.T) *TwitchSource {
+	t.Helper()
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	mux.HandleFunc("/gql", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-client", r.Header.Get("Client-ID"))
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.Contains(req.Query, "clip(") && req.Variables["slug"] == "FunnyClip":
+			_, _ = w.Write([]byte(`{"data":{"clip":{"playbackAccessToken":{"signature":"clipsig","value":"cliptoken"},
+				"videoQualities":[{"quality":"360","sourceURL":"` + ts.URL + `/clips/360.mp4"},
+				{"quality":"1080","sourceURL":"` + ts.URL + `/clips/1080.mp4"},
+				{"quality":"720","sourceURL":"` + ts.URL + `/clips/720.mp4"}]}}}`))
+		case strings.Contains(req.Query, "clip("):
+			_, _ = w.Write([]byte(`{"data":{"clip":null}}`))
+		case req.Variables["id"] == "123":
+			_, _ = w.Write([]byte(`{"data":{"videoPlaybackAccessToken":{"signature":"vodsig","value":"vodtoken"}}}`))
+		case req.Variables["id"] == "410":
+			http.Error(w, "query retired", http.StatusGone)
+		case req.Variables["id"] == "789":
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"failed integrity check"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"video not found"}]}`))
+		}
+	})
+	mux.HandleFunc("/clips/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "clipsig", r.URL.Query().Get("sig"))
+		assert.Equal(t, "cliptoken", r.URL.Query().Get("token"))
+		_, _ = w.Write([]byte("clip " + strings.TrimPrefix(r.URL.Path, "/clips/")))
+	})
+	mux.HandleFunc("/vod/123.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vodsig", r.URL.Query().Get("sig"))
+		assert.Equal(t, "vodtoken", r.URL.Query().Get("token"))
+		_, _ = w.Write([]byte(testMasterPlaylist))
+	})
+	mux.HandleFunc("/vod/chunked/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testMediaPlaylist))
+	})
+	mux.HandleFunc("/vod/chunked/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[" + strings.TrimPrefix(r.URL.Path, "/vod/chunked/") + "]"))
+	})
+	ts = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	t.Setenv("TWITCH_GQL_CLIENT_ID", "test-client")
+	s, err := NewTwitchSource(WithGQLURL(ts.URL+"/gql"), WithUsherURL(ts.URL))
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewTwitchSourceRequiresClientID(t *testing.T) {
+	t.Setenv("TWITCH_GQL_CLIENT_ID", "")
+	_, err := NewTwitchSource()
+	assert.ErrorIs(t, err, ErrNoClientID)
+}
+
+func TestClipDownloadsBestQuality(t *testing.T) {
+	s := fakeTwitch(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Clip(context.Background(), "FunnyClip", &buf))
+	assert.Equal(t, "clip 1080.mp4", buf.String())
+
+	err := s.Clip(context.Background(), "GoneClip", &buf)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestVODSegmentDownloadsOverlappingSegments(t *testing.T) {
+	s := fakeTwitch(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.VODSegment(context.Background(), "123", 15, 10, &buf))
+	assert.Equal(t, "[1.ts][2.ts]", buf.String())
+
+	buf.Reset()
+	require.NoError(t, s.VODSegment(context.Background(), "123", 38, 60, &buf))
+	assert.Equal(t, "[3.ts][4.ts]", buf.String())
+
+	err := s.VODSegment(context.Background(), "123", 50, 10, &buf)
+	assert.ErrorContains(t, err, "no footage")
+
+	err = s.VODSegment(context.Background(), "456", 0, 10, &buf)
+	assert.ErrorContains(t, err, "video not found")
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
+
+func TestRefusedDownloadsAreUnavailable(t *testing.T) {
+	s := fakeTwitch(t)
+
+	var buf bytes.Buffer
+	err := s.VODSegment(context.Background(), "410", 0, 10, &buf)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "status 410")
+
+	err = s.VODSegment(context.Background(), "789", 0, 10, &buf)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "integrity")
+}
+
+func TestLimitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &LimitWriter{W: &buf, Max: 8}
+
+	_, err := w.Write([]byte("12345"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("6789"))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Equal(t, int64(5), w.Written)
+	assert.Equal(t, "12345", buf.String())
+}
+
+func TestVideoID(t *testing.T) {
+	id, ok := VideoID("https://www.twitch.tv/videos/2345678901")
+	assert.True(t, ok)
+	assert.Equal(t, "2345678901", id)
+
+	_, ok = VideoID("https://www.youtube.com/watch?v=abc")
+	assert.False(t, ok)
+}
@@ -0,0 +1,189 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// SpikeConfig controls how chat activity is bucketed and when a bucket counts as a spike.
+type SpikeConfig struct {
+	// Bucket is the width of an activity bucket.
+	Bucket time.Duration
+	// Baseline is how many past buckets the rolling baseline averages over.
+	Baseline int
+	// Warmup is how many past buckets a channel needs before spikes are reported.
+	Warmup int
+	// Factor is how many times the baseline a bucket must reach to be a spike.
+	Factor float64
+	// MinMessages is the smallest bucket that can be a spike, so quiet chats do not spike
+	// on a handful of messages.
+	MinMessages int
+}
+
+// DefaultSpikeConfig uses 10-second buckets against a 5-minute baseline.
+var DefaultSpikeConfig = SpikeConfig{
+	Bucket:      10 * time.Second,
+	Baseline:    30,
+	Warmup:      6,
+	Factor:      3,
+	MinMessages: 20,
+}
+
+// Bucket is the chat activity of a channel during one bucket.
+type Bucket struct {
+	Start    time.Time
+	Messages int
+	Emotes   map[string]int
+}
+
+// TopEmote returns the most used emote of the bucket, if any.
+func (b Bucket) TopEmote() (string, int) {
+	var name string
+	var count int
+	for emote, n := range b.Emotes {
+		if n > count || (n == count && emote < name) {
+			name, count = emote, n
+		}
+	}
+	return name, count
+}
+
+type channelActivity struct {
+	current Bucket
+	history []int // message counts of the last closed buckets, oldest first
+	spiking bool
+}
+
+// Aggregator counts chat messages and emotes per channel and bucket, and turns buckets
+// that exceed the rolling baseline into chat_spike stream events. A spike lasting several
+// buckets yields one event, for its first bucket. It is not safe for concurrent use.
+type Aggregator struct {
+	config   SpikeConfig
+	channels map[string]*channelActivity
+}
+
+// NewAggregator creates an Aggregator with config.
+func NewAggregator(config SpikeConfig) *Aggregator {
+	return &Aggregator{config: config, channels: make(map[string]*channelActivity)}
+}
+
+// Add counts a message sent to channelID at at. Buckets it closes may produce events.
+func (a *Aggregator) Add(channelID string, at time.Time, emotes map[string]int) []models.StreamEvent {
+	events := a.flushChannel(channelID, at)
+	ch := a.channel(channelID, at)
+	ch.current.Messages++
+	for emote, n := range emotes {
+		if ch.current.Emotes == nil {
+			ch.current.Emotes = make(map[string]int)
+		}
+		ch.current.Emotes[emote] += n
+	}
+	return events
+}
+
+// Flush closes every bucket that ended by now, including empty buckets of quiet channels.
+func (a *Aggregator) Flush(now time.Time) []models.StreamEvent {
+	ids := make([]string, 0, len(a.channels))
+	for id := range a.channels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var events []models.StreamEvent
+	for _, id := range ids {
+		events = append(events, a.flushChannel(id, now)...)
+	}
+	return events
+}
+
+// Forget drops the activity of a channel that is no longer ingested.
+func (a *Aggregator) Forget(channelID string) {
+	delete(a.channels, channelID)
+}
+
+func (a *Aggregator) channel(channelID string, at time.Time) *channelActivity {
+	ch, ok := a.channels[channelID]
+	if !ok {
+		ch = &channelActivity{current: Bucket{Start: at.Truncate(a.config.Bucket)}}
+		a.channels[channelID] = ch
+	}
+	return ch
+}
+
+// flushChannel closes the channel's buckets that ended by now. Late messages for a closed
+// bucket are counted in the current one.
+func (a *Aggregator) flushChannel(channelID string, now time.Time) []models.StreamEvent {
+	ch, ok := a.channels[channelID]
+	if !ok {
+		return nil
+	}
+	start := now.Truncate(a.config.Bucket)
+
+	var events []models.StreamEvent
+	for ch.current.Start.Before(start) {
+		if event, ok := a.close(channelID, ch); ok {
+			events = append(events, event)
+		}
+		next := ch.current.Start.Add(a.config.Bucket)
+		// Skip long silences in one step; a baseline of empty buckets is all they add.
+		if gap := int(start.Sub(next) / a.config.Bucket); gap > a.config.Baseline {
+			next = start.Add(-time.Duration(a.config.Baseline) * a.config.Bucket)
+		}
+		ch.current = Bucket{Start: next}
+	}
+	return events
+}
+
+// close moves the current bucket into the history and reports a spike event for it.
+func (a *Aggregator) close(channelID string, ch *channelActivity) (models.StreamEvent, bool) {
+	bucket := ch.current
+	var baseline float64
+	for _, n := range ch.history {
+		baseline += float64(n)
+	}
+	if len(ch.history) > 0 {
+		baseline /= float64(len(ch.history))
+	}
+
+	spike := len(ch.history) >= a.config.Warmup &&
+		bucket.Messages >= a.config.MinMessages &&
+		float64(bucket.Messages) >= baseline*a.config.Factor
+
+	ch.history = append(ch.history, bucket.Messages)
+	if len(ch.history) > a.config.Baseline {
+		ch.history = ch.history[len(ch.history)-a.config.Baseline:]
+	}
+
+	wasSpiking := ch.spiking
+	ch.spiking = spike
+	if !spike || wasSpiking {
+		return models.StreamEvent{}, false
+	}
+	return spikeEvent(channelID, bucket, baseline, a.config.Bucket), true
+}
+
+func spikeEvent(channelID string, bucket Bucket, baseline float64, width time.Duration) models.StreamEvent {
+	description := fmt.Sprintf("%d messages in %s", bucket.Messages, width)
+	if baseline > 0 {
+		description += fmt.Sprintf(" · %.1fx baseline", float64(bucket.Messages)/baseline)
+	}
+	if emote, n := bucket.TopEmote(); n > 0 {
+		description += fmt.Sprintf(" · Top emote: %s ×%d", emote, n)
+	}
+
+	messages := strconv.Itoa(bucket.Messages)
+	externalID := fmt.Sprintf("%s:%d", models.StreamEventChatSpike, bucket.Start.Unix())
+	return models.StreamEvent{
+		ChannelID:   channelID,
+		EventType:   models.StreamEventChatSpike,
+		Title:       "Chat spike",
+		Description: description,
+		Timestamp:   bucket.Start.Unix(),
+		Value:       &messages,
+		ExternalID:  &externalID,
+	}
+}
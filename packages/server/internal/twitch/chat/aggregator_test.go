@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	m, ok := ParseMessage(`@emotes=25:0-4;room-id=100000001;display-name=Some\sOne :someone!someone@someone.tmi.twitch.tv PRIVMSG #kadeemtest :Kappa hello :)` + "\r\n")
+	require.True(t, ok)
+	assert.Equal(t, "PRIVMSG", m.Command)
+	assert.Equal(t, "someone!someone@someone.tmi.twitch.tv", m.Prefix)
+	assert.Equal(t, []string{"#kadeemtest", "Kappa hello :)"}, m.Params)
+	assert.Equal(t, "Some One", m.Tags["display-name"])
+	assert.Equal(t, "100000001", m.Tags["room-id"])
+
+	m, ok = ParseMessage("PING :tmi.twitch.tv")
+	require.True(t, ok)
+	assert.Equal(t, "PING", m.Command)
+	assert.Equal(t, "tmi.twitch.tv", m.Trailing())
+
+	_, ok = ParseMessage("")
+	assert.False(t, ok)
+}
+
+func TestEmotes(t *testing.T) {
+	assert.Equal(t, map[string]int{"Kappa": 2, "PogChamp": 1},
+		Emotes("25:0-4,15-19/88:6-13", "Kappa PogChamp Kappa"))
+	// Positions count characters, not bytes.
+	assert.Equal(t, map[string]int{"Kappa": 1}, Emotes("25:3-7", "éé Kappa"))
+	assert.Equal(t, map[string]int{}, Emotes("25:10-14", "Kappa"))
+	assert.Nil(t, Emotes("", "Kappa"))
+}
+
+var testSpikes = SpikeConfig{Bucket: 10 * time.Second, Baseline: 6, Warmup: 3, Factor: 3, MinMessages: 10}
+
+// feed sends n messages to channelID within the bucket starting at start.
+func feed(a *Aggregator, channelID string, start time.Time, n int, emotes map[string]int) []models.StreamEvent {
+	var events []models.StreamEvent
+	for i := 0; i < n; i++ {
+		events = append(events, a.Add(channelID, start.Add(time.Duration(i)*time.Millisecond), emotes)...)
+	}
+	return events
+}
+
+func TestAggregatorReportsSpike(t *testing.T) {
+	a := NewAggregator(testSpikes)
+	start := time.Date(2026, 1, 7, 18, 0, 0, 0, time.UTC)
+	bucket := func(i int) time.Time { return start.Add(time.Duration(i) * testSpikes.Bucket) }
+
+	for i := 0; i < 3; i++ {
+		assert.Empty(t, feed(a, "1", bucket(i), 5, nil))
+	}
+	assert.Empty(t, feed(a, "1", bucket(3), 40, map[string]int{"PogChamp": 1}))
+	events := a.Flush(bucket(4))
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, "1", e.ChannelID)
+	assert.Equal(t, models.StreamEventChatSpike, e.EventType)
+	assert.Equal(t, bucket(3).Unix(), e.Timestamp)
+	assert.Equal(t, "40 messages in 10s · 8.0x baseline · Top emote: PogChamp ×40", e.Description)
+	require.NotNil(t, e.Value)
+	assert.Equal(t, "40", *e.Value)
+	require.NotNil(t, e.ExternalID)
+	assert.Equal(t, "chat_spike:1767808830", *e.ExternalID)
+
+	// The spike goes on for another bucket without a second event, then ends.
+	assert.Empty(t, feed(a, "1", bucket(4), 40, nil))
+	assert.Empty(t, feed(a, "1", bucket(5), 5, nil))
+	assert.Empty(t, a.Flush(bucket(6)))
+}
+
+func TestAggregatorWarmupAndMinimum(t *testing.T) {
+	a := NewAggregator(testSpikes)
+	start := time.Date(2026, 1, 7, 18, 0, 0, 0, time.UTC)
+
+	// No spike before the channel has a baseline.
+	feed(a, "1", start, 1, nil)
+	assert.Empty(t, feed(a, "1", start.Add(10*time.Second), 50, nil))
+	assert.Empty(t, a.Flush(start.Add(20*time.Second)))
+
+	// A quiet chat does not spike on a handful of messages.
+	b := NewAggregator(testSpikes)
+	for i := 0; i < 3; i++ {
+		feed(b, "2", start.Add(time.Duration(i)*10*time.Second), 1, nil)
+	}
+	feed(b, "2", start.Add(30*time.Second), 9, nil)
+	assert.Empty(t, b.Flush(start.Add(40*time.Second)))
+}
+
+func TestAggregatorQuietGap(t *testing.T) {
+	a := NewAggregator(testSpikes)
+	start := time.Date(2026, 1, 7, 18, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		feed(a, "1", start.Add(time.Duration(i)*10*time.Second), 20, nil)
+	}
+
+	// After an hour of silence the baseline is empty buckets, so a modest burst is a spike.
+	later := start.Add(time.Hour)
+	assert.Empty(t, a.Flush(later))
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0}, a.channels["1"].history)
+	feed(a, "1", later, 12, nil)
+	events := a.Flush(later.Add(10 * time.Second))
+	require.Len(t, events, 1)
+	assert.Equal(t, "12 messages in 10s", events[0].Description)
+
+	a.Forget("1")
+	assert.Empty(t, a.Flush(later.Add(time.Hour)))
+}
@@ -0,0 +1,356 @@
+// Package chat ingests Twitch chat over IRC (TMI) and reports bursts of chat activity as
+// stream events.
+package chat
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+const (
+	// joinBatch and joinInterval keep JOINs within the 20 per 10 seconds Twitch allows.
+	joinBatch    = 20
+	joinInterval = 10 * time.Second
+	dialTimeout  = 10 * time.Second
+)
+
+// Store is what the ingester reads tracked channels from and writes spike events to.
+type Store interface {
+	ListChannels(filter *models.ChannelFilter, limit, offset int) ([]models.Channel, error)
+	UpsertStreamEvents(events []models.StreamEvent) error
+}
+
+// Config configures an Ingester.
+type Config struct {
+	// URL is the IRC server, ircs://host:port for TLS or irc://host:port for plain TCP.
+	URL string
+	// Nick and Token log in to chat. Without a token the ingester joins anonymously, which
+	// is enough to read.
+	Nick  string
+	Token string
+	// Spikes controls bucketing and spike detection.
+	Spikes SpikeConfig
+	// MinBackoff and MaxBackoff bound the wait between reconnects, which doubles after
+	// every connection that fails before login.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// RefreshInterval is how often the tracked channels are re-read to join and part.
+	RefreshInterval time.Duration
+	// ReadTimeout drops a connection that received nothing for this long. Twitch pings
+	// about every five minutes.
+	ReadTimeout time.Duration
+}
+
+// DefaultConfig connects to Twitch chat over TLS.
+var DefaultConfig = Config{
+	URL:             "ircs://irc.chat.twitch.tv:6697",
+	Spikes:          DefaultSpikeConfig,
+	MinBackoff:      time.Second,
+	MaxBackoff:      2 * time.Minute,
+	RefreshInterval: 5 * time.Minute,
+	ReadTimeout:     6 * time.Minute,
+}
+
+// ConfigFromEnv reads TWITCH_IRC_URL, TWITCH_IRC_NICK, TWITCH_IRC_TOKEN,
+// CHAT_SPIKE_FACTOR and CHAT_SPIKE_MIN_MESSAGES on top of DefaultConfig.
+func ConfigFromEnv() Config {
+	config := DefaultConfig
+	if raw := os.Getenv("TWITCH_IRC_URL"); raw != "" {
+		config.URL = raw
+	}
+	config.Nick = os.Getenv("TWITCH_IRC_NICK")
+	config.Token = os.Getenv("TWITCH_IRC_TOKEN")
+	if raw := os.Getenv("CHAT_SPIKE_FACTOR"); raw != "" {
+		if factor, err := strconv.ParseFloat(raw, 64); err == nil && factor > 1 {
+			config.Spikes.Factor = factor
+		} else {
+			logging.Warn("Ignoring invalid CHAT_SPIKE_FACTOR", "value", raw)
+		}
+	}
+	if raw := os.Getenv("CHAT_SPIKE_MIN_MESSAGES"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			config.Spikes.MinMessages = n
+		} else {
+			logging.Warn("Ignoring invalid CHAT_SPIKE_MIN_MESSAGES", "value", raw)
+		}
+	}
+	return config
+}
+
+// Ingester joins the chat of every tracked Twitch channel and stores chat_spike events.
+type Ingester struct {
+	store      Store
+	config     Config
+	aggregator *Aggregator
+	now        func() time.Time
+
+	// channels maps the login of every tracked channel to its channel ID.
+	channels map[string]string
+}
+
+// NewIngester creates an Ingester. Call Run to start it.
+func NewIngester(store Store, config Config) *Ingester {
+	return &Ingester{
+		store:      store,
+		config:     config,
+		aggregator: NewAggregator(config.Spikes),
+		now:        time.Now,
+		channels:   make(map[string]string),
+	}
+}
+
+// Run ingests chat until ctx is done, reconnecting with exponential backoff whenever the
+// connection drops.
+func (in *Ingester) Run(ctx context.Context) {
+	backoff := in.config.MinBackoff
+	for {
+		loggedIn, err := in.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if loggedIn {
+			backoff = in.config.MinBackoff
+		}
+		logging.Warn("Twitch chat connection lost, reconnecting", "error", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if !loggedIn {
+			backoff = min(backoff*2, in.config.MaxBackoff)
+		}
+	}
+}
+
+// session runs one connection until it fails. It reports whether the server welcomed us,
+// which resets the backoff.
+func (in *Ingester) session(ctx context.Context) (bool, error) {
+	conn, err := in.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	nick, pass := in.config.Nick, ""
+	if in.config.Token != "" {
+		pass = "oauth:" + strings.TrimPrefix(in.config.Token, "oauth:")
+	} else {
+		nick = fmt.Sprintf("justinfan%d", 10000+rand.IntN(90000))
+	}
+	send := func(format string, args ...any) error {
+		_, err := fmt.Fprintf(conn, format+"\r\n", args...)
+		return err
+	}
+	if err := send("CAP REQ :twitch.tv/tags twitch.tv/commands"); err != nil {
+		return false, err
+	}
+	if pass != "" {
+		if err := send("PASS %s", pass); err != nil {
+			return false, err
+		}
+	}
+	if err := send("NICK %s", strings.ToLower(nick)); err != nil {
+		return false, err
+	}
+
+	lines := make(chan Message, 256)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go in.read(conn, lines, readErr, done)
+
+	flush := time.NewTicker(in.config.Spikes.Bucket)
+	defer flush.Stop()
+	refresh := time.NewTicker(in.config.RefreshInterval)
+	defer refresh.Stop()
+
+	loggedIn := false
+	joined := make(map[string]bool)
+	var lastJoin time.Time
+	join := func() error {
+		if !loggedIn || time.Since(lastJoin) < joinInterval {
+			return nil
+		}
+		var batch []string
+		for login := range in.channels {
+			if !joined[login] && len(batch) < joinBatch {
+				batch = append(batch, "#"+login)
+				joined[login] = true
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		lastJoin = time.Now()
+		logging.Info("Joining Twitch chat", "channels", batch)
+		return send("JOIN %s", strings.Join(batch, ","))
+	}
+	part := func() error {
+		for login := range joined {
+			if _, ok := in.channels[login]; !ok {
+				delete(joined, login)
+				if err := send("PART #%s", login); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return loggedIn, ctx.Err()
+
+		case err := <-readErr:
+			return loggedIn, err
+
+		case m := <-lines:
+			switch m.Command {
+			case "PING":
+				if err := send("PONG :%s", m.Trailing()); err != nil {
+					return loggedIn, err
+				}
+			case "001":
+				loggedIn = true
+				logging.Info("Connected to Twitch chat", "url", in.config.URL, "nick", nick)
+				in.refreshChannels()
+				if err := join(); err != nil {
+					return loggedIn, err
+				}
+			case "RECONNECT":
+				return loggedIn, errors.New("server asked to reconnect")
+			case "NOTICE":
+				if strings.Contains(m.Trailing(), "authentication failed") || strings.Contains(m.Trailing(), "Improperly formatted auth") {
+					return loggedIn, fmt.Errorf("login failed: %s", m.Trailing())
+				}
+			case "PRIVMSG":
+				in.handleMessage(m)
+			}
+
+		case <-flush.C:
+			in.storeEvents(in.aggregator.Flush(in.now()))
+			if err := join(); err != nil {
+				return loggedIn, err
+			}
+
+		case <-refresh.C:
+			in.refreshChannels()
+			if err := part(); err != nil {
+				return loggedIn, err
+			}
+			if err := join(); err != nil {
+				return loggedIn, err
+			}
+		}
+	}
+}
+
+func (in *Ingester) dial(ctx context.Context) (net.Conn, error) {
+	u, err := url.Parse(in.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse IRC URL: %w", err)
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	switch u.Scheme {
+	case "ircs":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		return tlsDialer.DialContext(ctx, "tcp", u.Host)
+	case "irc":
+		return dialer.DialContext(ctx, "tcp", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported IRC URL scheme %q", u.Scheme)
+	}
+}
+
+// read parses lines from conn until it fails, stays silent for ReadTimeout or done closes.
+func (in *Ingester) read(conn net.Conn, lines chan<- Message, readErr chan<- error, done <-chan struct{}) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), 64*1024)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(in.config.ReadTimeout)); err != nil {
+			readErr <- err
+			return
+		}
+		if !scanner.Scan() {
+			err := scanner.Err()
+			if err == nil {
+				err = errors.New("connection closed by server")
+			}
+			readErr <- err
+			return
+		}
+		if m, ok := ParseMessage(scanner.Text()); ok {
+			select {
+			case lines <- m:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// refreshChannels re-reads the tracked Twitch channels. Activity of channels that are no
+// longer tracked is dropped.
+func (in *Ingester) refreshChannels() {
+	platform := models.PlatformTwitch
+	channels, err := in.store.ListChannels(&models.ChannelFilter{Platform: &platform}, 1000, 0)
+	if err != nil {
+		logging.Error("Failed to list channels for chat ingestion", "error", err)
+		return
+	}
+	tracked := make(map[string]string, len(channels))
+	for _, ch := range channels {
+		tracked[strings.ToLower(ch.ChannelName)] = ch.ID
+	}
+	for login, id := range in.channels {
+		if _, ok := tracked[login]; !ok {
+			in.aggregator.Forget(id)
+		}
+	}
+	in.channels = tracked
+}
+
+func (in *Ingester) handleMessage(m Message) {
+	if len(m.Params) < 2 {
+		return
+	}
+	login := strings.TrimPrefix(m.Params[0], "#")
+	channelID, ok := in.channels[login]
+	if !ok {
+		return
+	}
+	if roomID := m.Tags["room-id"]; roomID != "" && roomID != channelID {
+		return
+	}
+	in.storeEvents(in.aggregator.Add(channelID, in.now(), Emotes(m.Tags["emotes"], m.Trailing())))
+}
+
+func (in *Ingester) storeEvents(events []models.StreamEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for _, e := range events {
+		logging.Info("Chat spike", "channel_id", e.ChannelID, "timestamp", e.Timestamp, "description", e.Description)
+	}
+	if err := in.store.UpsertStreamEvents(events); err != nil {
+		logging.Error("Failed to store chat spike events", "error", err)
+	}
+}
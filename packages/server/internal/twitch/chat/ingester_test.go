@@ -0,0 +1,102 @@
+package chat_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/chat"
+	"github.com/galchammat/kadeem/internal/twitch/chattest"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	channels []models.Channel
+	events   []models.StreamEvent
+}
+
+func (s *fakeStore) ListChannels(filter *models.ChannelFilter, limit, offset int) ([]models.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.Channel(nil), s.channels...), nil
+}
+
+func (s *fakeStore) UpsertStreamEvents(events []models.StreamEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *fakeStore) Events() []models.StreamEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.StreamEvent(nil), s.events...)
+}
+
+func startIngester(t *testing.T) (*chattest.Server, *fakeStore) {
+	t.Helper()
+	server := chattest.NewServer()
+	t.Cleanup(server.Close)
+	store := &fakeStore{channels: []models.Channel{{ID: "100000001", Platform: "twitch", ChannelName: "KadeemTest"}}}
+
+	config := chat.DefaultConfig
+	config.URL = server.URL
+	config.MinBackoff = 10 * time.Millisecond
+	config.MaxBackoff = 50 * time.Millisecond
+	config.Spikes = chat.SpikeConfig{Bucket: 200 * time.Millisecond, Baseline: 5, Warmup: 0, Factor: 2, MinMessages: 3}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		chat.NewIngester(store, config).Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	require.True(t, server.WaitJoined("kadeemtest", 5*time.Second))
+	return server, store
+}
+
+func TestIngesterRecordsSpike(t *testing.T) {
+	server, store := startIngester(t)
+	assert.Contains(t, server.Received(), "CAP REQ :twitch.tv/tags twitch.tv/commands")
+
+	for i := 0; i < 5; i++ {
+		server.Send("kadeemtest", "100000001", "viewer", "Kappa Kappa", "25:0-4,6-10")
+	}
+	// Messages for another room are ignored.
+	server.Send("kadeemtest", "999", "viewer", "Kappa", "25:0-4")
+
+	require.Eventually(t, func() bool { return len(store.Events()) > 0 }, 5*time.Second, 20*time.Millisecond)
+	e := store.Events()[0]
+	assert.Equal(t, "100000001", e.ChannelID)
+	assert.Equal(t, models.StreamEventChatSpike, e.EventType)
+	require.NotNil(t, e.Value)
+	assert.Equal(t, "5", *e.Value)
+	assert.Contains(t, e.Description, "Top emote: Kappa ×10")
+}
+
+func TestIngesterAnswersPing(t *testing.T) {
+	server, _ := startIngester(t)
+	server.Ping()
+	assert.True(t, server.WaitPongs(1, 5*time.Second))
+}
+
+func TestIngesterReconnects(t *testing.T) {
+	server, _ := startIngester(t)
+
+	server.Drop()
+	require.True(t, server.WaitConnections(2, 5*time.Second))
+	assert.True(t, server.WaitJoined("kadeemtest", 5*time.Second))
+
+	server.Reconnect()
+	require.True(t, server.WaitConnections(3, 5*time.Second))
+	assert.True(t, server.WaitJoined("kadeemtest", 5*time.Second))
+}
@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Message is one IRC line as Twitch sends it, with IRCv3 tags.
+type Message struct {
+	Tags    map[string]string
+	Prefix  string
+	Command string
+	Params  []string
+}
+
+// Trailing returns the last parameter, which holds the text of PRIVMSG and similar commands.
+func (m Message) Trailing() string {
+	if len(m.Params) == 0 {
+		return ""
+	}
+	return m.Params[len(m.Params)-1]
+}
+
+// ParseMessage parses a line without its trailing CRLF. It reports false for a line without
+// a command.
+func ParseMessage(line string) (Message, bool) {
+	var m Message
+	line = strings.TrimRight(line, "\r\n")
+
+	if rest, ok := strings.CutPrefix(line, "@"); ok {
+		var rawTags string
+		rawTags, line, _ = strings.Cut(rest, " ")
+		m.Tags = make(map[string]string)
+		for _, tag := range strings.Split(rawTags, ";") {
+			key, value, _ := strings.Cut(tag, "=")
+			m.Tags[key] = unescapeTag(value)
+		}
+	}
+	if rest, ok := strings.CutPrefix(line, ":"); ok {
+		m.Prefix, line, _ = strings.Cut(rest, " ")
+	}
+
+	m.Command, line, _ = strings.Cut(line, " ")
+	if m.Command == "" {
+		return m, false
+	}
+	for line != "" {
+		if trailing, ok := strings.CutPrefix(line, ":"); ok {
+			m.Params = append(m.Params, trailing)
+			break
+		}
+		var param string
+		param, line, _ = strings.Cut(line, " ")
+		if param != "" {
+			m.Params = append(m.Params, param)
+		}
+	}
+	return m, true
+}
+
+var tagUnescaper = strings.NewReplacer(`\:`, ";", `\s`, " ", `\\`, `\`, `\r`, "\r", `\n`, "\n")
+
+func unescapeTag(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return tagUnescaper.Replace(value)
+}
+
+// Emotes returns how often each emote appears in text according to an emotes tag such as
+// "25:0-4,12-16/1902:6-10". Emotes are named by their text; positions count characters.
+func Emotes(tag, text string) map[string]int {
+	if tag == "" {
+		return nil
+	}
+	runes := []rune(text)
+	counts := make(map[string]int)
+	for _, emote := range strings.Split(tag, "/") {
+		_, positions, ok := strings.Cut(emote, ":")
+		if !ok {
+			continue
+		}
+		for _, position := range strings.Split(positions, ",") {
+			rawStart, rawEnd, _ := strings.Cut(position, "-")
+			start, err1 := strconv.Atoi(rawStart)
+			end, err2 := strconv.Atoi(rawEnd)
+			if err1 != nil || err2 != nil || start < 0 || end < start || end >= len(runes) {
+				continue
+			}
+			counts[string(runes[start:end+1])]++
+		}
+	}
+	return counts
+}
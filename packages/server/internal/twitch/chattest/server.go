@@ -0,0 +1,253 @@
+// Package chattest provides a local stand-in for the Twitch chat IRC server (TMI) for tests.
+// It speaks plain IRC over TCP; point an ingester at URL.
+package chattest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake Twitch chat server.
+type Server struct {
+	// URL is irc://host:port of the listener.
+	URL string
+
+	ln net.Listener
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	conns       map[*conn]bool
+	connections int
+	pongs       int
+	received    []string
+	closed      bool
+}
+
+type conn struct {
+	net.Conn
+	mu     sync.Mutex
+	nick   string
+	joined map[string]bool
+}
+
+func (c *conn) send(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = fmt.Fprintf(c.Conn, "%s\r\n", line)
+}
+
+// NewServer starts a fake chat server on a local port. The caller must Close it.
+func NewServer() *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("chattest: failed to listen: %v", err))
+	}
+	s := &Server{URL: "irc://" + ln.Addr().String(), ln: ln, conns: make(map[*conn]bool)}
+	s.cond = sync.NewCond(&s.mu)
+	go s.accept()
+	return s
+}
+
+// Close stops the listener and drops every connection.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.ln.Close()
+	s.Drop()
+}
+
+// Drop closes every open connection, as a network failure would.
+func (s *Server) Drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.Close()
+		delete(s.conns, c)
+	}
+}
+
+// Connections returns how many connections the server accepted.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Pongs returns how many PONGs the server received.
+func (s *Server) Pongs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pongs
+}
+
+// Received returns every line clients sent, in order.
+func (s *Server) Received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+// WaitJoined waits until a connected client joined channel (a login, without #) and
+// reports whether one did within timeout.
+func (s *Server) WaitJoined(channel string, timeout time.Duration) bool {
+	return s.wait(timeout, func() bool { return s.joinedLocked(channel) })
+}
+
+// WaitConnections waits until the server accepted n connections.
+func (s *Server) WaitConnections(n int, timeout time.Duration) bool {
+	return s.wait(timeout, func() bool { return s.connections >= n })
+}
+
+// WaitPongs waits until the server received n PONGs.
+func (s *Server) WaitPongs(n int, timeout time.Duration) bool {
+	return s.wait(timeout, func() bool { return s.pongs >= n })
+}
+
+func (s *Server) wait(timeout time.Duration, done func() bool) bool {
+	timer := time.AfterFunc(timeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !done() {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		s.cond.Wait()
+	}
+	return true
+}
+
+func (s *Server) joinedLocked(channel string) bool {
+	for c := range s.conns {
+		c.mu.Lock()
+		joined := c.joined[channel]
+		c.mu.Unlock()
+		if joined {
+			return true
+		}
+	}
+	return false
+}
+
+// Send delivers a chat message to every client that joined channel. roomID becomes the
+// room-id tag and emotes the emotes tag, e.g. "25:0-4".
+func (s *Server) Send(channel, roomID, user, text, emotes string) {
+	tags := fmt.Sprintf("@badge-info=;badges=;display-name=%s;emotes=%s;room-id=%s;tmi-sent-ts=%d;user-id=1",
+		user, emotes, roomID, time.Now().UnixMilli())
+	line := fmt.Sprintf("%s :%s!%s@%s.tmi.twitch.tv PRIVMSG #%s :%s", tags, user, user, user, channel, text)
+	s.broadcast(func(c *conn) bool { return c.joined[channel] }, line)
+}
+
+// Ping sends PING to every client.
+func (s *Server) Ping() {
+	s.broadcast(func(*conn) bool { return true }, "PING :tmi.twitch.tv")
+}
+
+// Reconnect asks every client to reconnect, as Twitch does before maintenance.
+func (s *Server) Reconnect() {
+	s.broadcast(func(*conn) bool { return true }, ":tmi.twitch.tv RECONNECT")
+}
+
+func (s *Server) broadcast(match func(*conn) bool, line string) {
+	s.mu.Lock()
+	var targets []*conn
+	for c := range s.conns {
+		c.mu.Lock()
+		ok := match(c)
+		c.mu.Unlock()
+		if ok {
+			targets = append(targets, c)
+		}
+	}
+	s.mu.Unlock()
+	for _, c := range targets {
+		c.send(line)
+	}
+}
+
+func (s *Server) accept() {
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &conn{Conn: nc, joined: make(map[string]bool)}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return
+		}
+		s.conns[c] = true
+		s.connections++
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c *conn) {
+	defer func() {
+		c.Close()
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		line := scanner.Text()
+		s.mu.Lock()
+		s.received = append(s.received, line)
+		s.mu.Unlock()
+
+		command, args, _ := strings.Cut(line, " ")
+		switch command {
+		case "CAP":
+			c.send(":tmi.twitch.tv CAP * ACK :twitch.tv/tags twitch.tv/commands")
+		case "PASS":
+		case "NICK":
+			c.mu.Lock()
+			c.nick = args
+			c.mu.Unlock()
+			for _, welcome := range []string{
+				"001 %s :Welcome, GLHF!",
+				"002 %s :Your host is tmi.twitch.tv",
+				"376 %s :>",
+			} {
+				c.send(":tmi.twitch.tv " + fmt.Sprintf(welcome, args))
+			}
+		case "JOIN", "PART":
+			for _, channel := range strings.Split(args, ",") {
+				channel = strings.TrimPrefix(channel, "#")
+				c.mu.Lock()
+				c.joined[channel] = command == "JOIN"
+				nick := c.nick
+				c.mu.Unlock()
+				c.send(fmt.Sprintf(":%s!%s@%s.tmi.twitch.tv %s #%s", nick, nick, nick, command, channel))
+			}
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case "PING":
+			c.send(":tmi.twitch.tv PONG tmi.twitch.tv " + args)
+		case "PONG":
+			s.mu.Lock()
+			s.pongs++
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		}
+	}
+}
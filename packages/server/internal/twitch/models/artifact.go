@@ -0,0 +1,48 @@
+package models
+
+type ArtifactKind string
+
+const (
+	ArtifactClip       ArtifactKind = "clip"
+	ArtifactVODSegment ArtifactKind = "vod_segment"
+)
+
+type ArtifactStatus string
+
+const (
+	ArtifactPending    ArtifactStatus = "pending"
+	ArtifactDownloaded ArtifactStatus = "downloaded"
+	ArtifactFailed     ArtifactStatus = "failed"
+)
+
+// Artifact is raw footage downloaded from a platform: a clip, or a segment of a VOD around a
+// match highlight. A pending artifact with attempts is waiting for a retry at NextAttemptAt.
+type Artifact struct {
+	ID        int64        `json:"id" db:"id"`
+	Kind      ArtifactKind `json:"kind" db:"kind"`
+	ChannelID string       `json:"channelId" db:"channel_id"`
+	// SourceID is the clip slug, or the ID of the video a segment is cut from.
+	SourceID string `json:"sourceId" db:"source_id"`
+	MatchID  *int64 `json:"matchId,omitempty" db:"match_id"`
+	// StartOffset and Duration locate a segment in its VOD, in seconds. Both are 0 for clips.
+	StartOffset   int64          `json:"startOffset" db:"start_offset"`
+	Duration      int64          `json:"duration" db:"duration"`
+	Title         string         `json:"title" db:"title"`
+	Status        ArtifactStatus `json:"status" db:"status"`
+	Attempts      int            `json:"attempts" db:"attempts"`
+	LastError     string         `json:"lastError,omitempty" db:"last_error"`
+	NextAttemptAt int64          `json:"nextAttemptAt,omitempty" db:"next_attempt_at"`
+	StorageKey    string         `json:"-" db:"storage_key"`
+	Size          int64          `json:"size" db:"size"`
+	CreatedAt     int64          `json:"createdAt" db:"created_at"`
+	UpdatedAt     int64          `json:"updatedAt" db:"updated_at"`
+}
+
+type ArtifactFilter struct {
+	ID         *int64
+	ChannelID  *string
+	StreamerID *int64
+	MatchID    *int64
+	Kind       *ArtifactKind
+	Status     *ArtifactStatus
+}
@@ -0,0 +1,70 @@
+package models
+
+import "encoding/json"
+
+// EventSub subscription types the webhook receiver handles.
+const (
+	EventSubStreamOnline  = "stream.online"
+	EventSubStreamOffline = "stream.offline"
+	EventSubChannelUpdate = "channel.update"
+)
+
+// EventSub message types, sent in the Twitch-Eventsub-Message-Type header.
+const (
+	EventSubMessageNotification = "notification"
+	EventSubMessageVerification = "webhook_callback_verification"
+	EventSubMessageRevocation   = "revocation"
+)
+
+type EventSubCondition struct {
+	BroadcasterUserID string `json:"broadcaster_user_id"`
+}
+
+type EventSubTransport struct {
+	Method   string `json:"method"`
+	Callback string `json:"callback,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+type EventSubSubscription struct {
+	ID        string            `json:"id,omitempty"`
+	Status    string            `json:"status,omitempty"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition EventSubCondition `json:"condition"`
+	Transport EventSubTransport `json:"transport"`
+	CreatedAt string            `json:"created_at,omitempty"`
+}
+
+// EventSubMessage is the body of a webhook request. Challenge is only set for
+// webhook_callback_verification messages and Event only for notifications.
+type EventSubMessage struct {
+	Subscription EventSubSubscription `json:"subscription"`
+	Challenge    string               `json:"challenge,omitempty"`
+	Event        json.RawMessage      `json:"event,omitempty"`
+}
+
+type StreamOnlineEvent struct {
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	Type                 string `json:"type"`
+	StartedAt            string `json:"started_at"`
+}
+
+type StreamOfflineEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
+
+type ChannelUpdateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	Title                string `json:"title"`
+	Language             string `json:"language"`
+	CategoryID           string `json:"category_id"`
+	CategoryName         string `json:"category_name"`
+}
@@ -0,0 +1,40 @@
+package models
+
+// LiveSession is one live stream of a channel, from going online to going offline.
+type LiveSession struct {
+	ID        int64  `json:"id" db:"id"`
+	ChannelID string `json:"channelId" db:"channel_id"`
+	StreamID  string `json:"streamId" db:"stream_id"`
+	Title     string `json:"title" db:"title"`
+	Category  string `json:"category" db:"category"`
+	StartedAt int64  `json:"startedAt" db:"started_at"`
+	EndedAt   *int64 `json:"endedAt,omitempty" db:"ended_at"`
+}
+
+type LiveSessionFilter struct {
+	ID         *int64
+	ChannelID  *string
+	StreamerID *int64
+	// Live keeps only sessions that have (true) or have not (false) ended.
+	Live *bool
+}
+
+// ViewerSample is the viewer count of a live session at one point in time.
+type ViewerSample struct {
+	SessionID int64 `json:"-" db:"session_id"`
+	Timestamp int64 `json:"timestamp" db:"sampled_at"`
+	Viewers   int   `json:"viewers" db:"viewer_count"`
+}
+
+// LiveStream is a stream as Helix /streams reports it while the channel is live.
+type LiveStream struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	UserLogin   string `json:"user_login"`
+	GameID      string `json:"game_id"`
+	GameName    string `json:"game_name"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	ViewerCount int    `json:"viewer_count"`
+	StartedAt   string `json:"started_at"`
+}
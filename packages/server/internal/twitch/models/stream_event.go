@@ -5,6 +5,10 @@ type StreamEventType string
 const (
 	StreamEventHypeTrain StreamEventType = "hype_train"
 	StreamEventClip      StreamEventType = "clip"
+	StreamEventOnline    StreamEventType = "stream_online"
+	StreamEventOffline   StreamEventType = "stream_offline"
+	StreamEventUpdate    StreamEventType = "channel_update"
+	StreamEventChatSpike StreamEventType = "chat_spike"
 )
 
 type StreamEvent struct {
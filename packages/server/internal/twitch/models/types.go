@@ -5,6 +5,12 @@ import (
 	"time"
 )
 
+// Streaming platforms a channel can be on, see Channel.Platform.
+const (
+	PlatformTwitch  = "twitch"
+	PlatformYouTube = "youtube"
+)
+
 type Streamer struct {
 	ID   int64  `json:"id" db:"id"`
 	Name string `json:"name" db:"name"`
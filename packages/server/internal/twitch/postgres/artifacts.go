@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	twitch "github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+const artifactColumns = `a.id, a.kind, a.channel_id, a.source_id, a.match_id, a.start_offset, a.duration, a.title,
+	a.status, a.attempts, a.last_error, a.next_attempt_at, a.storage_key, a.size, a.created_at, a.updated_at`
+
+// EnqueueArtifacts adds pending artifacts created at now and returns how many were new.
+// Artifacts that are already known keep their status.
+func (s *DB) EnqueueArtifacts(artifacts []twitch.Artifact, now int64) (int64, error) {
+	if len(artifacts) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.SQL.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO stream_artifacts (kind, channel_id, source_id, match_id, start_offset, duration, title, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (kind, source_id, start_offset, duration) DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var added int64
+	for _, a := range artifacts {
+		res, err := stmt.Exec(a.Kind, a.ChannelID, a.SourceID, a.MatchID, a.StartOffset, a.Duration, a.Title, now)
+		if err != nil {
+			return 0, fmt.Errorf("insert artifact %s %s: %w", a.Kind, a.SourceID, err)
+		}
+		n, _ := res.RowsAffected()
+		added += n
+	}
+	return added, tx.Commit()
+}
+
+// ListDueArtifacts returns up to limit pending artifacts whose next attempt is due at now,
+// oldest first.
+func (s *DB) ListDueArtifacts(now int64, limit int) ([]twitch.Artifact, error) {
+	rows, err := s.db.SQL.Query(`
+		SELECT `+artifactColumns+` FROM stream_artifacts a
+		WHERE a.status = $1 AND a.next_attempt_at <= $2
+		ORDER BY a.id
+		LIMIT $3
+	`, twitch.ArtifactPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due artifacts: %w", err)
+	}
+	defer rows.Close()
+	return scanArtifacts(rows)
+}
+
+// CompleteArtifact marks an artifact downloaded to storageKey.
+func (s *DB) CompleteArtifact(id int64, storageKey string, size, now int64) error {
+	_, err := s.db.SQL.Exec(`
+		UPDATE stream_artifacts
+		SET status = $2, storage_key = $3, size = $4, attempts = attempts + 1, last_error = '', updated_at = $5
+		WHERE id = $1
+	`, id, twitch.ArtifactDownloaded, storageKey, size, now)
+	if err != nil {
+		return fmt.Errorf("complete artifact %d: %w", id, err)
+	}
+	return nil
+}
+
+// FailArtifact records a failed attempt. The artifact stays pending until nextAttemptAt, or
+// is failed for good if nextAttemptAt is nil.
+func (s *DB) FailArtifact(id int64, lastError string, nextAttemptAt *int64, now int64) error {
+	status, next := twitch.ArtifactFailed, int64(0)
+	if nextAttemptAt != nil {
+		status, next = twitch.ArtifactPending, *nextAttemptAt
+	}
+	_, err := s.db.SQL.Exec(`
+		UPDATE stream_artifacts
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4, updated_at = $5
+		WHERE id = $1
+	`, id, status, lastError, next, now)
+	if err != nil {
+		return fmt.Errorf("fail artifact %d: %w", id, err)
+	}
+	return nil
+}
+
+// RetryArtifact puts a failed artifact back in the queue with a fresh set of attempts.
+func (s *DB) RetryArtifact(id int64, now int64) (bool, error) {
+	res, err := s.db.SQL.Exec(`
+		UPDATE stream_artifacts
+		SET status = $2, attempts = 0, last_error = '', next_attempt_at = 0, updated_at = $4
+		WHERE id = $1 AND status = $3
+	`, id, twitch.ArtifactPending, twitch.ArtifactFailed, now)
+	if err != nil {
+		return false, fmt.Errorf("retry artifact %d: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n != 0, nil
+}
+
+// ListArtifacts returns artifacts matching the filter, newest first.
+func (s *DB) ListArtifacts(filter *twitch.ArtifactFilter, limit, offset int) ([]twitch.Artifact, error) {
+	joins, where, args := artifactConditions(filter)
+	query := `SELECT ` + artifactColumns + ` FROM stream_artifacts a` + joins + where
+	query += fmt.Sprintf(" ORDER BY a.created_at DESC, a.id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.SQL.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	defer rows.Close()
+	return scanArtifacts(rows)
+}
+
+// CountArtifacts counts the artifacts matching the filter by status.
+func (s *DB) CountArtifacts(filter *twitch.ArtifactFilter) (map[twitch.ArtifactStatus]int, error) {
+	joins, where, args := artifactConditions(filter)
+	rows, err := s.db.SQL.Query(`SELECT a.status, COUNT(*) FROM stream_artifacts a`+joins+where+` GROUP BY a.status`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[twitch.ArtifactStatus]int)
+	for rows.Next() {
+		var status twitch.ArtifactStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan artifact count: %w", err)
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+func artifactConditions(filter *twitch.ArtifactFilter) (string, string, []any) {
+	var joins string
+	var where []string
+	var args []any
+	argN := 1
+
+	if filter != nil {
+		if filter.ID != nil {
+			where = append(where, fmt.Sprintf("a.id = $%d", argN))
+			args = append(args, *filter.ID)
+			argN++
+		}
+		if filter.StreamerID != nil {
+			joins = " INNER JOIN channels c ON a.channel_id = c.id"
+			where = append(where, fmt.Sprintf("c.streamer_id = $%d", argN))
+			args = append(args, *filter.StreamerID)
+			argN++
+		}
+		if filter.ChannelID != nil {
+			where = append(where, fmt.Sprintf("a.channel_id = $%d", argN))
+			args = append(args, *filter.ChannelID)
+			argN++
+		}
+		if filter.MatchID != nil {
+			where = append(where, fmt.Sprintf("a.match_id = $%d", argN))
+			args = append(args, *filter.MatchID)
+			argN++
+		}
+		if filter.Kind != nil {
+			where = append(where, fmt.Sprintf("a.kind = $%d", argN))
+			args = append(args, *filter.Kind)
+			argN++
+		}
+		if filter.Status != nil {
+			where = append(where, fmt.Sprintf("a.status = $%d", argN))
+			args = append(args, *filter.Status)
+		}
+	}
+
+	if len(where) == 0 {
+		return joins, "", args
+	}
+	return joins, " WHERE " + strings.Join(where, " AND "), args
+}
+
+func scanArtifacts(rows *sql.Rows) ([]twitch.Artifact, error) {
+	var artifacts []twitch.Artifact
+	for rows.Next() {
+		var a twitch.Artifact
+		if err := rows.Scan(
+			&a.ID, &a.Kind, &a.ChannelID, &a.SourceID, &a.MatchID, &a.StartOffset, &a.Duration, &a.Title,
+			&a.Status, &a.Attempts, &a.LastError, &a.NextAttemptAt, &a.StorageKey, &a.Size, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan artifact: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
@@ -1,4 +1,4 @@
-package store
+package postgres
 
 import (
 	"fmt"
@@ -8,7 +8,7 @@ import (
 	twitch "github.com/galchammat/kadeem/internal/twitch/models"
 )
 
-func (s *Store) ListBroadcasts(filter *twitch.Broadcast, limit int, offset int) ([]twitch.Broadcast, error) {
+func (s *DB) ListBroadcasts(filter *twitch.Broadcast, limit int, offset int) ([]twitch.Broadcast, error) {
 	if filter == nil || filter.ChannelID == "" {
 		return nil, fmt.Errorf("channel_id is required for ListBroadcasts")
 	}
@@ -51,7 +51,7 @@ func (s *Store) ListBroadcasts(filter *twitch.Broadcast, limit int, offset int)
 	return broadcasts, nil
 }
 
-func (s *Store) InsertBroadcasts(broadcasts []twitch.Broadcast) error {
+func (s *DB) InsertBroadcasts(broadcasts []twitch.Broadcast) error {
 	if len(broadcasts) == 0 {
 		return nil
 	}
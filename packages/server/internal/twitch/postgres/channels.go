@@ -1,4 +1,4 @@
-package store
+package postgres
 
 import (
 	"database/sql"
@@ -10,7 +10,7 @@ import (
 )
 
 // SaveStreamer saves a streamer to the database (shared pool)
-func (s *Store) SaveStreamer(streamer twitch.Streamer) (int64, error) {
+func (s *DB) SaveStreamer(streamer twitch.Streamer) (int64, error) {
 	var id int64
 	err := s.db.SQL.QueryRow(
 		`INSERT INTO streamers (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`,
@@ -24,7 +24,7 @@ func (s *Store) SaveStreamer(streamer twitch.Streamer) (int64, error) {
 }
 
 // GetStreamerByName retrieves a streamer by name
-func (s *Store) GetStreamerByName(name string) (*twitch.Streamer, error) {
+func (s *DB) GetStreamerByName(name string) (*twitch.Streamer, error) {
 	var streamer twitch.Streamer
 	err := s.db.SQL.QueryRow(`SELECT id, name FROM streamers WHERE name = $1`, name).Scan(&streamer.ID, &streamer.Name)
 	if err == sql.ErrNoRows {
@@ -38,7 +38,7 @@ func (s *Store) GetStreamerByName(name string) (*twitch.Streamer, error) {
 }
 
 // GetStreamerByID retrieves a streamer by ID
-func (s *Store) GetStreamerByID(id int) (*twitch.Streamer, error) {
+func (s *DB) GetStreamerByID(id int) (*twitch.Streamer, error) {
 	var streamer twitch.Streamer
 	err := s.db.SQL.QueryRow(`SELECT id, name FROM streamers WHERE id = $1`, id).Scan(&streamer.ID, &streamer.Name)
 	if err == sql.ErrNoRows {
@@ -52,7 +52,7 @@ func (s *Store) GetStreamerByID(id int) (*twitch.Streamer, error) {
 }
 
 // FindOrCreateStreamer finds or creates a streamer (idempotent)
-func (s *Store) FindOrCreateStreamer(name string) (*twitch.Streamer, error) {
+func (s *DB) FindOrCreateStreamer(name string) (*twitch.Streamer, error) {
 	streamer, err := s.GetStreamerByName(name)
 	if err != nil {
 		return nil, err
@@ -70,7 +70,7 @@ func (s *Store) FindOrCreateStreamer(name string) (*twitch.Streamer, error) {
 }
 
 // ListTrackedStreamers returns streamers a user is tracking with pagination
-func (s *Store) ListTrackedStreamers(userID string, limit, offset int) ([]twitch.Streamer, error) {
+func (s *DB) ListTrackedStreamers(userID string, limit, offset int) ([]twitch.Streamer, error) {
 	query := `SELECT s.id, s.name 
 	          FROM streamers s
 	          INNER JOIN user_tracked_streamers uts ON s.id = uts.streamer_id
@@ -102,7 +102,7 @@ func (s *Store) ListTrackedStreamers(userID string, limit, offset int) ([]twitch
 }
 
 // TrackStreamer adds a tracking relationship (idempotent)
-func (s *Store) TrackStreamer(userID string, streamerID int64) error {
+func (s *DB) TrackStreamer(userID string, streamerID int64) error {
 	query := `INSERT INTO user_tracked_streamers (user_id, streamer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
 	_, err := s.db.SQL.Exec(query, userID, streamerID)
 	if err != nil {
@@ -112,7 +112,7 @@ func (s *Store) TrackStreamer(userID string, streamerID int64) error {
 }
 
 // UntrackStreamer removes a tracking relationship
-func (s *Store) UntrackStreamer(userID string, streamerID int64) error {
+func (s *DB) UntrackStreamer(userID string, streamerID int64) error {
 	query := `DELETE FROM user_tracked_streamers WHERE user_id = $1 AND streamer_id = $2`
 	_, err := s.db.SQL.Exec(query, userID, streamerID)
 	if err != nil {
@@ -122,7 +122,7 @@ func (s *Store) UntrackStreamer(userID string, streamerID int64) error {
 }
 
 // IsTrackingStreamer checks if user is tracking a streamer
-func (s *Store) IsTrackingStreamer(userID string, streamerID int64) (bool, error) {
+func (s *DB) IsTrackingStreamer(userID string, streamerID int64) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM user_tracked_streamers WHERE user_id = $1 AND streamer_id = $2)`
 	var exists bool
 	err := s.db.SQL.QueryRow(query, userID, streamerID).Scan(&exists)
@@ -134,7 +134,7 @@ func (s *Store) IsTrackingStreamer(userID string, streamerID int64) (bool, error
 }
 
 // GetTrackedStreamersForSync returns all streamers with at least one tracker (for background jobs)
-func (s *Store) GetTrackedStreamersForSync() ([]twitch.Streamer, error) {
+func (s *DB) GetTrackedStreamersForSync() ([]twitch.Streamer, error) {
 	query := `SELECT DISTINCT s.id, s.name 
 	          FROM streamers s
 	          INNER JOIN user_tracked_streamers uts ON s.id = uts.streamer_id`
@@ -163,7 +163,7 @@ func (s *Store) GetTrackedStreamersForSync() ([]twitch.Streamer, error) {
 }
 
 // DeleteStreamer deletes a streamer by name (admin only)
-func (s *Store) DeleteStreamer(name string) (bool, error) {
+func (s *DB) DeleteStreamer(name string) (bool, error) {
 	res, err := s.db.SQL.Exec(
 		`DELETE FROM streamers WHERE name = $1`,
 		name,
@@ -177,7 +177,7 @@ func (s *Store) DeleteStreamer(name string) (bool, error) {
 }
 
 // ListStreamers lists all streamers with pagination (for admin/internal use)
-func (s *Store) ListStreamers(limit, offset int) ([]twitch.Streamer, error) {
+func (s *DB) ListStreamers(limit, offset int) ([]twitch.Streamer, error) {
 	rows, err := s.db.SQL.Query("SELECT id, name FROM streamers ORDER BY name LIMIT $1 OFFSET $2", limit, offset)
 	if err != nil {
 		logging.Error("Failed to query streamers from database", "error", err)
@@ -202,7 +202,7 @@ func (s *Store) ListStreamers(limit, offset int) ([]twitch.Streamer, error) {
 }
 
 // ListChannels lists channels with optional filtering and pagination
-func (s *Store) ListChannels(filter *twitch.ChannelFilter, limit, offset int) ([]twitch.Channel, error) {
+func (s *DB) ListChannels(filter *twitch.ChannelFilter, limit, offset int) ([]twitch.Channel, error) {
 	query := `SELECT id, streamer_id, platform, channel_name, avatar_url, synced_at FROM channels`
 	var where []string
 	var args []any
@@ -265,7 +265,7 @@ func (s *Store) ListChannels(filter *twitch.ChannelFilter, limit, offset int) ([
 	return channels, nil
 }
 
-func (s *Store) SaveChannel(channel twitch.Channel) (bool, error) {
+func (s *DB) SaveChannel(channel twitch.Channel) (bool, error) {
 	res, err := s.db.SQL.Exec(
 		`INSERT INTO channels (streamer_id, platform, channel_name, id, avatar_url) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO NOTHING`,
 		channel.StreamerID, channel.Platform, channel.ChannelName, channel.ID, channel.AvatarURL,
@@ -286,7 +286,7 @@ var allowedChannelColumns = map[string]bool{
 	"platform":     true,
 }
 
-func (s *Store) UpdateChannel(channelID string, updates map[string]any) (bool, error) {
+func (s *DB) UpdateChannel(channelID string, updates map[string]any) (bool, error) {
 	var setClauses []string
 	var args []any
 	argN := 1
@@ -315,7 +315,7 @@ func (s *Store) UpdateChannel(channelID string, updates map[string]any) (bool, e
 	return (n != 0), nil
 }
 
-func (s *Store) DeleteChannel(channelID string) (bool, error) {
+func (s *DB) DeleteChannel(channelID string) (bool, error) {
 	res, err := s.db.SQL.Exec(
 		`DELETE FROM channels WHERE id = $1`,
 		channelID,
@@ -1,11 +1,11 @@
-package store
+package postgres
 
 import platformdb "github.com/galchammat/kadeem/internal/platform/database"
 
-type Store struct {
+type DB struct {
 	db *platformdb.DB
 }
 
-func New(db *platformdb.DB) *Store {
-	return &Store{db: db}
+func New(db *platformdb.DB) *DB {
+	return &DB{db: db}
 }
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// StartLiveSession records that a channel went live and returns the session ID. Starting a
+// stream that is already recorded only fills in a missing title or category. Any other
+// session of the channel still open is closed, since its offline event was missed.
+func (s *DB) StartLiveSession(session twitch.LiveSession) (int64, error) {
+	tx, err := s.db.SQL.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`
+		UPDATE live_sessions SET ended_at = $3
+		WHERE channel_id = $1 AND stream_id <> $2 AND ended_at IS NULL
+	`, session.ChannelID, session.StreamID, session.StartedAt); err != nil {
+		return 0, fmt.Errorf("close stale live sessions: %w", err)
+	}
+
+	var id int64
+	err = tx.QueryRow(`
+		INSERT INTO live_sessions (channel_id, stream_id, title, category, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (channel_id, stream_id) DO UPDATE SET
+			title = COALESCE(NULLIF(EXCLUDED.title, ''), live_sessions.title),
+			category = COALESCE(NULLIF(EXCLUDED.category, ''), live_sessions.category)
+		RETURNING id
+	`, session.ChannelID, session.StreamID, session.Title, session.Category, session.StartedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert live session: %w", err)
+	}
+	return id, tx.Commit()
+}
+
+// UpdateLiveSession sets the title and category of the channel's open session, if any.
+func (s *DB) UpdateLiveSession(channelID, title, category string) (bool, error) {
+	res, err := s.db.SQL.Exec(`
+		UPDATE live_sessions SET title = $2, category = $3
+		WHERE channel_id = $1 AND ended_at IS NULL
+	`, channelID, title, category)
+	if err != nil {
+		return false, fmt.Errorf("update live session: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n != 0, nil
+}
+
+// EndLiveSession closes the channel's open session, if any.
+func (s *DB) EndLiveSession(channelID string, endedAt int64) (bool, error) {
+	res, err := s.db.SQL.Exec(`
+		UPDATE live_sessions SET ended_at = $2
+		WHERE channel_id = $1 AND ended_at IS NULL
+	`, channelID, endedAt)
+	if err != nil {
+		return false, fmt.Errorf("end live session: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n != 0, nil
+}
+
+// ListLiveSessions returns live sessions matching the filter, newest first.
+func (s *DB) ListLiveSessions(filter *twitch.LiveSessionFilter, limit, offset int) ([]twitch.LiveSession, error) {
+	query := `SELECT ls.id, ls.channel_id, ls.stream_id, ls.title, ls.category, ls.started_at, ls.ended_at
+	          FROM live_sessions ls`
+
+	var joins []string
+	var where []string
+	var args []any
+	argN := 1
+
+	if filter != nil {
+		if filter.ID != nil {
+			where = append(where, fmt.Sprintf("ls.id = $%d", argN))
+			args = append(args, *filter.ID)
+			argN++
+		}
+		if filter.StreamerID != nil {
+			joins = append(joins, "INNER JOIN channels c ON ls.channel_id = c.id")
+			where = append(where, fmt.Sprintf("c.streamer_id = $%d", argN))
+			args = append(args, *filter.StreamerID)
+			argN++
+		}
+		if filter.ChannelID != nil {
+			where = append(where, fmt.Sprintf("ls.channel_id = $%d", argN))
+			args = append(args, *filter.ChannelID)
+			argN++
+		}
+		if filter.Live != nil {
+			if *filter.Live {
+				where = append(where, "ls.ended_at IS NULL")
+			} else {
+				where = append(where, "ls.ended_at IS NOT NULL")
+			}
+		}
+	}
+
+	if len(joins) > 0 {
+		query += " " + strings.Join(joins, " ")
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY ls.started_at DESC LIMIT $%d OFFSET $%d", argN, argN+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.SQL.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list live sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []twitch.LiveSession
+	for rows.Next() {
+		var ls twitch.LiveSession
+		if err := rows.Scan(
+			&ls.ID, &ls.ChannelID, &ls.StreamID, &ls.Title, &ls.Category, &ls.StartedAt, &ls.EndedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan live session: %w", err)
+		}
+		sessions = append(sessions, ls)
+	}
+	return sessions, rows.Err()
+}
+
+// AddViewerSample records a viewer count, replacing a sample taken at the same second.
+func (s *DB) AddViewerSample(sample twitch.ViewerSample) error {
+	_, err := s.db.SQL.Exec(`
+		INSERT INTO live_viewer_samples (session_id, sampled_at, viewer_count)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (session_id, sampled_at) DO UPDATE SET viewer_count = EXCLUDED.viewer_count
+	`, sample.SessionID, sample.Timestamp, sample.Viewers)
+	if err != nil {
+		return fmt.Errorf("add viewer sample for session %d: %w", sample.SessionID, err)
+	}
+	return nil
+}
+
+// ListViewerSamples returns the viewer counts of a session, oldest first.
+func (s *DB) ListViewerSamples(sessionID int64) ([]twitch.ViewerSample, error) {
+	rows, err := s.db.SQL.Query(`
+		SELECT session_id, sampled_at, viewer_count FROM live_viewer_samples
+		WHERE session_id = $1
+		ORDER BY sampled_at
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("list viewer samples for session %d: %w", sessionID, err)
+	}
+	defer rows.Close()
+
+	var samples []twitch.ViewerSample
+	for rows.Next() {
+		var vs twitch.ViewerSample
+		if err := rows.Scan(&vs.SessionID, &vs.Timestamp, &vs.Viewers); err != nil {
+			return nil, fmt.Errorf("scan viewer sample: %w", err)
+		}
+		samples = append(samples, vs)
+	}
+	return samples, rows.Err()
+}
@@ -1,4 +1,4 @@
-package store
+package postgres
 
 import (
 	"fmt"
@@ -8,7 +8,7 @@ import (
 )
 
 // ListStreamEvents returns stream events matching the filter, ordered by timestamp descending.
-func (s *Store) ListStreamEvents(filter *twitch.StreamEventFilter, limit, offset int) ([]twitch.StreamEvent, error) {
+func (s *DB) ListStreamEvents(filter *twitch.StreamEventFilter, limit, offset int) ([]twitch.StreamEvent, error) {
 	query := `SELECT se.id, se.channel_id, se.event_type, se.title, se.description,
 	                 se.timestamp, se.value, se.external_id
 	          FROM stream_events se`
@@ -77,7 +77,7 @@ func (s *Store) ListStreamEvents(filter *twitch.StreamEventFilter, limit, offset
 }
 
 // UpsertStreamEvents inserts stream events, ignoring duplicates by (channel_id, external_id).
-func (s *Store) UpsertStreamEvents(events []twitch.StreamEvent) error {
+func (s *DB) UpsertStreamEvents(events []twitch.StreamEvent) error {
 	if len(events) == 0 {
 		return nil
 	}
@@ -0,0 +1,17 @@
+// Package sqlite implements the twitch store on SQLite for local development and tests.
+// Every query is shared with the postgres package; the SQLite connection rewrites its
+// placeholders.
+package sqlite
+
+import (
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	"github.com/galchammat/kadeem/internal/twitch/postgres"
+)
+
+type DB struct {
+	*postgres.DB
+}
+
+func New(db *platformdb.DB) *DB {
+	return &DB{DB: postgres.New(db)}
+}
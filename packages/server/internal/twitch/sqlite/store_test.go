@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	twitch "github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := platformdb.OpenSQLite(filepath.Join(t.TempDir(), "kadeem.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.SQL.Close() })
+
+	m, err := platformdb.NewMigrate(db, "../../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	return New(db)
+}
+
+func TestStreamersAndChannels(t *testing.T) {
+	s := newTestDB(t)
+
+	streamer, err := s.FindOrCreateStreamer("caedrel")
+	require.NoError(t, err)
+	require.NotZero(t, streamer.ID)
+	again, err := s.FindOrCreateStreamer("caedrel")
+	require.NoError(t, err)
+	assert.Equal(t, streamer.ID, again.ID)
+
+	require.NoError(t, s.TrackStreamer("user", streamer.ID))
+	tracked, err := s.ListTrackedStreamers("user", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []twitch.Streamer{*streamer}, tracked)
+
+	saved, err := s.SaveChannel(twitch.Channel{ID: "c1", StreamerID: streamer.ID, Platform: "twitch", ChannelName: "caedrel"})
+	require.NoError(t, err)
+	assert.True(t, saved)
+	channels, err := s.ListChannels(&twitch.ChannelFilter{StreamerID: &streamer.ID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "c1", channels[0].ID)
+
+	deleted, err := s.DeleteStreamer("caedrel")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	channels, err = s.ListChannels(nil, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
+
+func TestBroadcastsAndEvents(t *testing.T) {
+	s := newTestDB(t)
+
+	streamer, err := s.FindOrCreateStreamer("caedrel")
+	require.NoError(t, err)
+	_, err = s.SaveChannel(twitch.Channel{ID: "c1", StreamerID: streamer.ID, Platform: "twitch", ChannelName: "caedrel"})
+	require.NoError(t, err)
+
+	broadcast := twitch.Broadcast{ChannelID: "c1", Title: "ranked", URL: "https://vod/1", Viewable: "public", CreatedAt: 100, PublishedAt: 100, Duration: 3600}
+	require.NoError(t, s.InsertBroadcasts([]twitch.Broadcast{broadcast}))
+	require.NoError(t, s.InsertBroadcasts([]twitch.Broadcast{broadcast}))
+	broadcasts, err := s.ListBroadcasts(&twitch.Broadcast{ChannelID: "c1"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 1)
+	assert.Equal(t, "ranked", broadcasts[0].Title)
+
+	externalID := "clip-1"
+	event := twitch.StreamEvent{ChannelID: "c1", EventType: twitch.StreamEventClip, Title: "outplay", Timestamp: 200, ExternalID: &externalID}
+	require.NoError(t, s.UpsertStreamEvents([]twitch.StreamEvent{event}))
+	require.NoError(t, s.UpsertStreamEvents([]twitch.StreamEvent{event}))
+	events, err := s.ListStreamEvents(&twitch.StreamEventFilter{StreamerID: &streamer.ID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "outplay", events[0].Title)
+}
+
+func TestLiveSessions(t *testing.T) {
+	s := newTestDB(t)
+
+	streamer, err := s.FindOrCreateStreamer("caedrel")
+	require.NoError(t, err)
+	_, err = s.SaveChannel(twitch.Channel{ID: "c1", StreamerID: streamer.ID, Platform: "twitch", ChannelName: "caedrel"})
+	require.NoError(t, err)
+
+	first, err := s.StartLiveSession(twitch.LiveSession{ChannelID: "c1", StreamID: "s1", StartedAt: 100})
+	require.NoError(t, err)
+	updated, err := s.UpdateLiveSession("c1", "ranked", "League of Legends")
+	require.NoError(t, err)
+	assert.True(t, updated)
+	again, err := s.StartLiveSession(twitch.LiveSession{ChannelID: "c1", StreamID: "s1", StartedAt: 100})
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	// s1 never went offline, so starting s2 closes it.
+	_, err = s.StartLiveSession(twitch.LiveSession{ChannelID: "c1", StreamID: "s2", StartedAt: 500})
+	require.NoError(t, err)
+	live := true
+	sessions, err := s.ListLiveSessions(&twitch.LiveSessionFilter{StreamerID: &streamer.ID, Live: &live}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "s2", sessions[0].StreamID)
+
+	ended, err := s.EndLiveSession("c1", 900)
+	require.NoError(t, err)
+	assert.True(t, ended)
+	ended, err = s.EndLiveSession("c1", 950)
+	require.NoError(t, err)
+	assert.False(t, ended)
+
+	sessions, err = s.ListLiveSessions(&twitch.LiveSessionFilter{ChannelID: &sessions[0].ChannelID}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.NotNil(t, sessions[0].EndedAt)
+	assert.Equal(t, int64(900), *sessions[0].EndedAt)
+	assert.Equal(t, "ranked", sessions[1].Title)
+	assert.Equal(t, "League of Legends", sessions[1].Category)
+	require.NotNil(t, sessions[1].EndedAt)
+	assert.Equal(t, int64(500), *sessions[1].EndedAt)
+}
@@ -0,0 +1,90 @@
+// Package store defines the storage interface of the streaming domain and opens the backend
+// that matches the database driver.
+package store
+
+import (
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	twitch "github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/galchammat/kadeem/internal/twitch/postgres"
+	"github.com/galchammat/kadeem/internal/twitch/sqlite"
+)
+
+// StreamerStore keeps streamers and who tracks them.
+type StreamerStore interface {
+	SaveStreamer(streamer twitch.Streamer) (int64, error)
+	GetStreamerByName(name string) (*twitch.Streamer, error)
+	GetStreamerByID(id int) (*twitch.Streamer, error)
+	FindOrCreateStreamer(name string) (*twitch.Streamer, error)
+	ListTrackedStreamers(userID string, limit, offset int) ([]twitch.Streamer, error)
+	TrackStreamer(userID string, streamerID int64) error
+	UntrackStreamer(userID string, streamerID int64) error
+	IsTrackingStreamer(userID string, streamerID int64) (bool, error)
+	GetTrackedStreamersForSync() ([]twitch.Streamer, error)
+	DeleteStreamer(name string) (bool, error)
+	ListStreamers(limit, offset int) ([]twitch.Streamer, error)
+}
+
+// ChannelStore keeps the platform channels of streamers.
+type ChannelStore interface {
+	ListChannels(filter *twitch.ChannelFilter, limit, offset int) ([]twitch.Channel, error)
+	SaveChannel(channel twitch.Channel) (bool, error)
+	UpdateChannel(channelID string, updates map[string]any) (bool, error)
+	DeleteChannel(channelID string) (bool, error)
+}
+
+// BroadcastStore keeps past broadcasts (VODs) of channels.
+type BroadcastStore interface {
+	ListBroadcasts(filter *twitch.Broadcast, limit int, offset int) ([]twitch.Broadcast, error)
+	InsertBroadcasts(broadcasts []twitch.Broadcast) error
+}
+
+// StreamEventStore keeps channel events such as raids and hype trains.
+type StreamEventStore interface {
+	ListStreamEvents(filter *twitch.StreamEventFilter, limit, offset int) ([]twitch.StreamEvent, error)
+	UpsertStreamEvents(events []twitch.StreamEvent) error
+}
+
+// LiveSessionStore keeps the live streams of channels as they start and end, with their
+// viewer counts over time.
+type LiveSessionStore interface {
+	StartLiveSession(session twitch.LiveSession) (int64, error)
+	UpdateLiveSession(channelID, title, category string) (bool, error)
+	EndLiveSession(channelID string, endedAt int64) (bool, error)
+	ListLiveSessions(filter *twitch.LiveSessionFilter, limit, offset int) ([]twitch.LiveSession, error)
+	AddViewerSample(sample twitch.ViewerSample) error
+	ListViewerSamples(sessionID int64) ([]twitch.ViewerSample, error)
+}
+
+// ArtifactStore keeps the download queue of clips and VOD segments and where they ended up.
+type ArtifactStore interface {
+	EnqueueArtifacts(artifacts []twitch.Artifact, now int64) (int64, error)
+	ListDueArtifacts(now int64, limit int) ([]twitch.Artifact, error)
+	CompleteArtifact(id int64, storageKey string, size, now int64) error
+	FailArtifact(id int64, lastError string, nextAttemptAt *int64, now int64) error
+	RetryArtifact(id int64, now int64) (bool, error)
+	ListArtifacts(filter *twitch.ArtifactFilter, limit, offset int) ([]twitch.Artifact, error)
+	CountArtifacts(filter *twitch.ArtifactFilter) (map[twitch.ArtifactStatus]int, error)
+}
+
+// Store is everything the streaming domain persists.
+type Store interface {
+	StreamerStore
+	ChannelStore
+	BroadcastStore
+	StreamEventStore
+	LiveSessionStore
+	ArtifactStore
+}
+
+var (
+	_ Store = (*postgres.DB)(nil)
+	_ Store = (*sqlite.DB)(nil)
+)
+
+// New returns the Store backend for the driver of db.
+func New(db *platformdb.DB) Store {
+	if db.Driver == platformdb.DriverSQLite {
+		return sqlite.New(db)
+	}
+	return postgres.New(db)
+}
@@ -0,0 +1,120 @@
+package twitchtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// SignEventSub returns the Twitch-Eventsub-Message-Signature header Twitch sends for a
+// message signed with secret.
+func SignEventSub(secret, messageID, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscriptions returns the EventSub subscriptions created on the server.
+func (s *Server) Subscriptions() []models.EventSubSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.EventSubSubscription(nil), s.subscriptions...)
+}
+
+// SetSubscriptionStatus changes the status of a subscription, e.g. to simulate a revocation.
+func (s *Server) SetSubscriptionStatus(id, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.subscriptions {
+		if s.subscriptions[i].ID == id {
+			s.subscriptions[i].Status = status
+		}
+	}
+}
+
+// createSubscription enables webhook subscriptions right away; the fake does not call back
+// to verify them.
+func (s *Server) createSubscription(r *http.Request) (int, any) {
+	var sub models.EventSubSubscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		return http.StatusBadRequest, "Invalid request body"
+	}
+	switch {
+	case sub.Type == "" || sub.Version == "":
+		return http.StatusBadRequest, "Missing type or version"
+	case sub.Condition.BroadcasterUserID == "":
+		return http.StatusBadRequest, "Missing condition.broadcaster_user_id"
+	case sub.Transport.Method != "webhook" || sub.Transport.Callback == "":
+		return http.StatusBadRequest, "Only webhook transports with a callback are supported"
+	case len(sub.Transport.Secret) < 10 || len(sub.Transport.Secret) > 100:
+		return http.StatusBadRequest, "The secret must be between 10 and 100 characters"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.subscriptions {
+		if existing.Type == sub.Type && existing.Condition == sub.Condition && existing.Transport.Callback == sub.Transport.Callback {
+			return http.StatusConflict, "subscription already exists"
+		}
+	}
+	s.nextSubID++
+	sub.ID = "sub-" + strconv.Itoa(s.nextSubID)
+	sub.Status = "enabled"
+	sub.Transport.Secret = ""
+	sub.CreatedAt = s.now().UTC().Format("2006-01-02T15:04:05.000000000Z")
+	s.subscriptions = append(s.subscriptions, sub)
+
+	return http.StatusAccepted, map[string]any{
+		"data":           []models.EventSubSubscription{sub},
+		"total":          len(s.subscriptions),
+		"total_cost":     len(s.subscriptions),
+		"max_total_cost": 10000,
+	}
+}
+
+// listSubscriptions pages with Twitch's fixed page size; unlike Helix resources the endpoint
+// takes no first parameter.
+func (s *Server) listSubscriptions(r *http.Request) (int, any) {
+	q := r.URL.Query()
+	if q.Has("first") {
+		return http.StatusBadRequest, "The parameter \"first\" is not supported"
+	}
+	s.mu.Lock()
+	var objects []json.RawMessage
+	for _, sub := range s.subscriptions {
+		if status := q.Get("status"); status != "" && sub.Status != status {
+			continue
+		}
+		if subType := q.Get("type"); subType != "" && sub.Type != subType {
+			continue
+		}
+		raw, _ := json.Marshal(sub)
+		objects = append(objects, raw)
+	}
+	s.mu.Unlock()
+	return paginate(r, objects, 100)
+}
+
+func (s *Server) deleteSubscription(r *http.Request) (int, any) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		return http.StatusBadRequest, "Missing required parameter \"id\""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subscriptions {
+		if sub.ID == id {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			return http.StatusNoContent, nil
+		}
+	}
+	return http.StatusNotFound, "subscription not found"
+}
@@ -0,0 +1,527 @@
+// Package twitchtest provides a fake Twitch Helix API and client-credentials token endpoint
+// for tests. Helix resources are served from fixtures with cursor pagination and the
+// Ratelimit-* headers Twitch sends; EventSub subscriptions are kept in memory.
+package twitchtest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/api"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// Helix resources served by the fake. Fixture files are named after them with slashes
+// replaced by underscores, e.g. hypetrain_events.json.
+const (
+	Users           = "users"
+	Videos          = "videos"
+	Clips           = "clips"
+	HypeTrainEvents = "hypetrain/events"
+	Streams         = "streams"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	DefaultClientID     = "twitchtest-client-id"
+	DefaultClientSecret = "twitchtest-client-secret"
+)
+
+// DefaultRateLimit is the size of the app token bucket Twitch grants per minute.
+const DefaultRateLimit = 800
+
+// Server is a fake Twitch API. Helix is served under /helix and tokens under /oauth2/token.
+type Server struct {
+	// URL is the base URL of the server, without a trailing slash.
+	URL string
+
+	ts  *httptest.Server
+	mux *http.ServeMux
+
+	mu           sync.Mutex
+	now          func() time.Time
+	clientID     string
+	clientSecret string
+	tokens       map[string]bool
+	resources    map[string][]item
+	hits         map[string]int
+
+	subscriptions []models.EventSubSubscription
+	nextSubID     int
+
+	limit     int
+	window    time.Duration
+	remaining int
+	resetAt   time.Time
+}
+
+// item is a single fixture object with its string and number fields for filtering.
+type item struct {
+	raw    json.RawMessage
+	fields map[string]any
+}
+
+func (i item) field(name string) string {
+	switch v := i.fields[name].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// NewServer starts a fake Twitch API without fixtures. The caller must Close it.
+func NewServer() *Server {
+	s := &Server{
+		mux:          http.NewServeMux(),
+		now:          time.Now,
+		clientID:     DefaultClientID,
+		clientSecret: DefaultClientSecret,
+		tokens:       make(map[string]bool),
+		resources:    make(map[string][]item),
+		hits:         make(map[string]int),
+		limit:        DefaultRateLimit,
+		window:       time.Minute,
+	}
+	s.remaining = s.limit
+
+	s.mux.HandleFunc("POST /oauth2/token", s.token)
+	s.mux.HandleFunc("GET /helix/users", s.helix(s.users))
+	s.mux.HandleFunc("GET /helix/videos", s.helix(s.videos))
+	s.mux.HandleFunc("GET /helix/clips", s.helix(s.clips))
+	s.mux.HandleFunc("GET /helix/hypetrain/events", s.helix(s.hypeTrainEvents))
+	s.mux.HandleFunc("GET /helix/streams", s.helix(s.streams))
+	s.mux.HandleFunc("GET /helix/search/channels", s.helix(s.searchChannels))
+	s.mux.HandleFunc("POST /helix/eventsub/subscriptions", s.helix(s.createSubscription))
+	s.mux.HandleFunc("GET /helix/eventsub/subscriptions", s.helix(s.listSubscriptions))
+	s.mux.HandleFunc("DELETE /helix/eventsub/subscriptions", s.helix(s.deleteSubscription))
+
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.ts.Close()
+}
+
+// Client returns a TwitchClient that authenticates against and talks to s.
+func (s *Server) Client(ctx context.Context, opts ...api.ClientOption) *api.TwitchClient {
+	s.mu.Lock()
+	clientID, clientSecret := s.clientID, s.clientSecret
+	s.mu.Unlock()
+	return api.NewTwitchClient(ctx, append([]api.ClientOption{
+		api.WithBaseURL(s.URL + "/helix"),
+		api.WithTokenURL(s.URL + "/oauth2/token"),
+		api.WithCredentials(clientID, clientSecret),
+	}, opts...)...)
+}
+
+// SetCredentials changes the client ID and secret the token endpoint accepts.
+func (s *Server) SetCredentials(clientID, clientSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientID, s.clientSecret = clientID, clientSecret
+}
+
+// SetRateLimit resizes the token bucket to limit requests per window and refills it.
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit, s.window, s.remaining = limit, window, limit
+	s.resetAt = time.Time{}
+}
+
+// Hits returns how many requests were made to paths starting with prefix.
+func (s *Server) Hits(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for path, count := range s.hits {
+		if strings.HasPrefix(path, prefix) {
+			n += count
+		}
+	}
+	return n
+}
+
+// TokensIssued returns how many access tokens the token endpoint handed out.
+func (s *Server) TokensIssued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tokens)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+	s.mu.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+// Add registers Helix objects (one JSON object each) under resource.
+func (s *Server) Add(resource string, objects ...json.RawMessage) error {
+	items := make([]item, 0, len(objects))
+	for _, raw := range objects {
+		var fields map[string]any
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return fmt.Errorf("decode %s object: %w", resource, err)
+		}
+		items = append(items, item{raw: raw, fields: fields})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources[resource] = append(s.resources[resource], items...)
+	return nil
+}
+
+// Clear removes every object registered under resource, e.g. to take a stream offline.
+func (s *Server) Clear(resource string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.resources, resource)
+}
+
+// LoadFixtures registers the Helix responses ({"data": [...]}) in dir, one file per resource.
+// Missing files are skipped.
+func (s *Server) LoadFixtures(dir string) error {
+	for _, resource := range []string{Users, Videos, Clips, HypeTrainEvents, Streams} {
+		path := filepath.Join(dir, strings.ReplaceAll(resource, "/", "_")+".json")
+		raw, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var response struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return fmt.Errorf("load fixture %s: %w", filepath.Base(path), err)
+		}
+		if err := s.Add(resource, response.Data...); err != nil {
+			return fmt.Errorf("load fixture %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+// token implements the client credentials grant of id.twitch.tv/oauth2/token.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, "unsupported grant type")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if clientID != s.clientID {
+		writeError(w, http.StatusBadRequest, "invalid client")
+		return
+	}
+	if clientSecret != s.clientSecret {
+		writeError(w, http.StatusForbidden, "invalid client secret")
+		return
+	}
+
+	token := fmt.Sprintf("twitchtest-token-%d", len(s.tokens)+1)
+	s.tokens[token] = true
+	writeJSON(w, http.StatusOK, map[string]any{"access_token": token, "expires_in": 5011271, "token_type": "bearer"})
+}
+
+// helix wraps a Helix handler with the token and Client-Id checks and the rate limit bucket.
+func (s *Server) helix(next func(r *http.Request) (int, any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		s.mu.Lock()
+		if !ok || !s.tokens[token] {
+			s.mu.Unlock()
+			writeError(w, http.StatusUnauthorized, "Invalid OAuth token")
+			return
+		}
+		if r.Header.Get("Client-Id") != s.clientID {
+			s.mu.Unlock()
+			writeError(w, http.StatusUnauthorized, "Client ID and OAuth token do not match")
+			return
+		}
+
+		now := s.now()
+		if !now.Before(s.resetAt) {
+			s.remaining = s.limit
+			s.resetAt = now.Add(s.window)
+		}
+		limited := s.remaining == 0
+		if !limited {
+			s.remaining--
+		}
+		w.Header().Set("Ratelimit-Limit", strconv.Itoa(s.limit))
+		w.Header().Set("Ratelimit-Remaining", strconv.Itoa(s.remaining))
+		w.Header().Set("Ratelimit-Reset", strconv.FormatInt(s.resetAt.Unix(), 10))
+		s.mu.Unlock()
+
+		if limited {
+			writeError(w, http.StatusTooManyRequests, "")
+			return
+		}
+
+		status, body := next(r)
+		switch {
+		case status >= http.StatusBadRequest:
+			writeError(w, status, fmt.Sprint(body))
+		case status == http.StatusNoContent:
+			w.WriteHeader(status)
+		default:
+			writeJSON(w, status, body)
+		}
+	}
+}
+
+func (s *Server) users(r *http.Request) (int, any) {
+	q := r.URL.Query()
+	ids, logins := q["id"], q["login"]
+	if len(ids)+len(logins) == 0 {
+		return http.StatusBadRequest, "Must provide an id or login when using an app access token"
+	}
+	if len(ids)+len(logins) > 100 {
+		return http.StatusBadRequest, "The sum of the id and login query parameters may not exceed 100"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var data []json.RawMessage
+	for _, it := range s.resources[Users] {
+		if slices.Contains(ids, it.field("id")) || slices.ContainsFunc(logins, func(l string) bool {
+			return strings.EqualFold(l, it.field("login"))
+		}) {
+			data = append(data, it.raw)
+		}
+	}
+	return http.StatusOK, map[string]any{"data": nonNil(data)}
+}
+
+func (s *Server) videos(r *http.Request) (int, any) {
+	q := r.URL.Query()
+	ids, userID := q["id"], q.Get("user_id")
+	if len(ids) == 0 && userID == "" && q.Get("game_id") == "" {
+		return http.StatusBadRequest, "Missing required parameter: id, user_id or game_id"
+	}
+	videoType := q.Get("type")
+	if videoType == "" {
+		videoType = "all"
+	}
+
+	return s.page(r, Videos, 20, func(it item) bool {
+		if len(ids) > 0 && !slices.Contains(ids, it.field("id")) {
+			return false
+		}
+		if userID != "" && it.field("user_id") != userID {
+			return false
+		}
+		return videoType == "all" || it.field("type") == videoType
+	})
+}
+
+func (s *Server) clips(r *http.Request) (int, any) {
+	q := r.URL.Query()
+	ids, broadcasterID := q["id"], q.Get("broadcaster_id")
+	if len(ids) == 0 && broadcasterID == "" && q.Get("game_id") == "" {
+		return http.StatusBadRequest, "Missing required parameter: id, broadcaster_id or game_id"
+	}
+	startedAt, err := parseTime(q.Get("started_at"))
+	if err != nil {
+		return http.StatusBadRequest, "Invalid started_at"
+	}
+	endedAt, err := parseTime(q.Get("ended_at"))
+	if err != nil {
+		return http.StatusBadRequest, "Invalid ended_at"
+	}
+
+	return s.page(r, Clips, 20, func(it item) bool {
+		if len(ids) > 0 && !slices.Contains(ids, it.field("id")) {
+			return false
+		}
+		if broadcasterID != "" && it.field("broadcaster_id") != broadcasterID {
+			return false
+		}
+		created, _ := time.Parse(time.RFC3339, it.field("created_at"))
+		if !startedAt.IsZero() && created.Before(startedAt) {
+			return false
+		}
+		return endedAt.IsZero() || !created.After(endedAt)
+	})
+}
+
+func (s *Server) hypeTrainEvents(r *http.Request) (int, any) {
+	broadcasterID := r.URL.Query().Get("broadcaster_id")
+	if broadcasterID == "" {
+		return http.StatusBadRequest, "Missing required parameter \"broadcaster_id\""
+	}
+	return s.page(r, HypeTrainEvents, 1, func(it item) bool {
+		data, _ := it.fields["event_data"].(map[string]any)
+		return data["broadcaster_id"] == broadcasterID
+	})
+}
+
+func (s *Server) streams(r *http.Request) (int, any) {
+	q := r.URL.Query()
+	userIDs, logins := q["user_id"], q["user_login"]
+	return s.page(r, Streams, 20, func(it item) bool {
+		if len(userIDs)+len(logins) == 0 {
+			return true
+		}
+		return slices.Contains(userIDs, it.field("user_id")) || slices.ContainsFunc(logins, func(l string) bool {
+			return strings.EqualFold(l, it.field("user_login"))
+		})
+	})
+}
+
+// searchChannels matches the login and display name of every known user, like
+// /search/channels does for channels that streamed recently.
+func (s *Server) searchChannels(r *http.Request) (int, any) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	if query == "" {
+		return http.StatusBadRequest, "Missing required parameter \"query\""
+	}
+
+	s.mu.Lock()
+	var channels []map[string]any
+	for _, user := range s.resources[Users] {
+		if !strings.Contains(strings.ToLower(user.field("login")), query) &&
+			!strings.Contains(strings.ToLower(user.field("display_name")), query) {
+			continue
+		}
+		live := slices.ContainsFunc(s.resources[Streams], func(st item) bool { return st.field("user_id") == user.field("id") })
+		channels = append(channels, map[string]any{
+			"id":                user.field("id"),
+			"broadcaster_login": user.field("login"),
+			"display_name":      user.field("display_name"),
+			"thumbnail_url":     user.field("profile_image_url"),
+			"is_live":           live,
+		})
+	}
+	s.mu.Unlock()
+
+	objects := make([]json.RawMessage, len(channels))
+	for i, ch := range channels {
+		objects[i], _ = json.Marshal(ch)
+	}
+	return paginate(r, objects, 20)
+}
+
+// page returns the objects of resource that match keep, one page at a time.
+func (s *Server) page(r *http.Request, resource string, defaultFirst int, keep func(item) bool) (int, any) {
+	s.mu.Lock()
+	var objects []json.RawMessage
+	for _, it := range s.resources[resource] {
+		if keep(it) {
+			objects = append(objects, it.raw)
+		}
+	}
+	s.mu.Unlock()
+	return paginate(r, objects, defaultFirst)
+}
+
+// paginate slices objects by the first and after parameters. The cursor is opaque to
+// clients; it encodes the offset of the next page.
+func paginate(r *http.Request, objects []json.RawMessage, defaultFirst int) (int, any) {
+	q := r.URL.Query()
+	first := defaultFirst
+	if raw := q.Get("first"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 100 {
+			return http.StatusBadRequest, "The parameter \"first\" was malformed: the value must be less than or equal to 100"
+		}
+		first = n
+	}
+	offset := 0
+	if after := q.Get("after"); after != "" {
+		n, err := decodeCursor(after)
+		if err != nil {
+			return http.StatusBadRequest, "Invalid cursor"
+		}
+		offset = n
+	}
+
+	offset = min(offset, len(objects))
+	end := min(offset+first, len(objects))
+	pagination := map[string]any{}
+	if end < len(objects) {
+		pagination["cursor"] = encodeCursor(end)
+	}
+	return http.StatusOK, map[string]any{"data": nonNil(objects[offset:end]), "pagination": pagination}
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, ok := strings.CutPrefix(string(raw), "offset:")
+	if !ok {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return strconv.Atoi(offset)
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func nonNil(objects []json.RawMessage) []json.RawMessage {
+	if objects == nil {
+		return []json.RawMessage{}
+	}
+	return objects
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(raw)
+}
+
+// writeError writes an error in the shape Helix and the token endpoint use.
+func writeError(w http.ResponseWriter, status int, message string) {
+	raw, _ := json.Marshal(map[string]any{"error": http.StatusText(status), "status": status, "message": message})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(raw)
+}
@@ -0,0 +1,149 @@
+package twitchtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "../../../tests/data/twitch"
+
+func newFixtureServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	require.NoError(t, s.LoadFixtures(fixtureDir))
+	return s
+}
+
+// get requests a Helix path with a fresh app token and decodes the response.
+func get(t *testing.T, s *Server, path string) (*http.Response, models.APIResponse) {
+	t.Helper()
+	resp, err := http.PostForm(s.URL+"/oauth2/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {DefaultClientID},
+		"client_secret": {DefaultClientSecret},
+	})
+	require.NoError(t, err)
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+	resp.Body.Close()
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"/helix"+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Client-Id", DefaultClientID)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body models.APIResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body
+}
+
+func TestClientUsesFake(t *testing.T) {
+	s := newFixtureServer(t)
+	client := s.Client(context.Background())
+
+	broadcasts, err := client.FetchBroadcasts("100000001", 0)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 5)
+	assert.Equal(t, "100000001", broadcasts[0].ChannelID)
+	assert.Equal(t, models.DurationSeconds(4*3600+12*60+3), broadcasts[0].Duration)
+
+	channel, err := client.FindChannel(models.Channel{ChannelName: "kadeemtest"})
+	require.NoError(t, err)
+	assert.Equal(t, "100000001", channel.ID)
+	assert.Equal(t, "KadeemTest", channel.ChannelName)
+
+	assert.Equal(t, 1, s.TokensIssued())
+}
+
+func TestRejectsBadCredentials(t *testing.T) {
+	s := newFixtureServer(t)
+	client := s.Client(context.Background())
+	s.SetCredentials(DefaultClientID, "rotated")
+
+	_, err := client.FetchBroadcasts("100000001", 0)
+	assert.Error(t, err)
+	assert.Zero(t, s.Hits("/helix"))
+}
+
+func TestCursorPagination(t *testing.T) {
+	s := newFixtureServer(t)
+
+	var ids []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+		path := "/videos?user_id=100000001&type=archive&first=2"
+		if cursor != "" {
+			path += "&after=" + cursor
+		}
+		resp, body := get(t, s, path)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page []struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(body.Data, &page))
+		for _, v := range page {
+			ids = append(ids, v.ID)
+		}
+		if body.Pagination == nil || body.Pagination.Cursor == "" {
+			break
+		}
+		cursor = body.Pagination.Cursor
+	}
+	assert.Equal(t, []string{"2000000006", "2000000005", "2000000004", "2000000003", "2000000001"}, ids)
+
+	resp, _ := get(t, s, "/videos?user_id=100000001&after=bogus")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestFilters(t *testing.T) {
+	s := newFixtureServer(t)
+
+	_, body := get(t, s, "/clips?broadcaster_id=100000001&started_at=2026-01-04T00:00:00Z")
+	var clips []map[string]any
+	require.NoError(t, json.Unmarshal(body.Data, &clips))
+	assert.Len(t, clips, 2)
+
+	_, body = get(t, s, "/hypetrain/events?broadcaster_id=100000001&first=100")
+	var events []map[string]any
+	require.NoError(t, json.Unmarshal(body.Data, &events))
+	assert.Len(t, events, 3)
+
+	_, body = get(t, s, "/streams?user_login=KadeemTest")
+	assert.True(t, strings.Contains(string(body.Data), `"viewer_count":4321`))
+
+	_, body = get(t, s, "/users?login=kadeemduo")
+	assert.True(t, strings.Contains(string(body.Data), `"id":"100000002"`))
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	s := newFixtureServer(t)
+	s.SetRateLimit(2, time.Minute)
+
+	resp, _ := get(t, s, "/users?id=100000001")
+	assert.Equal(t, "2", resp.Header.Get("Ratelimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("Ratelimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("Ratelimit-Reset"))
+
+	resp, _ = get(t, s, "/users?id=100000001")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = get(t, s, "/users?id=100000001")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("Ratelimit-Remaining"))
+}
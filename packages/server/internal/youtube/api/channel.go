@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+type thumbnails struct {
+	Default *struct {
+		URL string `json:"url"`
+	} `json:"default"`
+	High *struct {
+		URL string `json:"url"`
+	} `json:"high"`
+}
+
+type channelItem struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Title      string     `json:"title"`
+		CustomURL  string     `json:"customUrl"`
+		Thumbnails thumbnails `json:"thumbnails"`
+	} `json:"snippet"`
+}
+
+// FindChannel looks a channel up by its ID (UC...) or its handle, with or without the @.
+// The channel is named after its handle, which unlike its title is unique and short.
+func (c *YouTubeClient) FindChannel(streamInput models.Channel) (models.Channel, error) {
+	query := strings.TrimSpace(streamInput.ChannelName)
+	if query == "" {
+		query = strings.TrimSpace(streamInput.ID)
+	}
+	if query == "" {
+		return models.Channel{}, fmt.Errorf("missing channel query (channelName or channelID required)")
+	}
+
+	params := url.Values{}
+	params.Set("part", "snippet")
+	if isChannelID(query) {
+		params.Set("id", query)
+	} else {
+		params.Set("forHandle", "@"+strings.TrimPrefix(query, "@"))
+	}
+	var resp listResponse[channelItem]
+	if err := c.get("channels", params, &resp); err != nil {
+		return models.Channel{}, fmt.Errorf("youtube channel request failed: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return models.Channel{}, fmt.Errorf("no youtube channel named %q was found", query)
+	}
+
+	item := resp.Items[0]
+	name := item.Snippet.CustomURL
+	if name == "" {
+		name = item.ID
+	}
+	var avatarURL string
+	if item.Snippet.Thumbnails.Default != nil {
+		avatarURL = item.Snippet.Thumbnails.Default.URL
+	}
+	return models.Channel{
+		StreamerID:  streamInput.StreamerID,
+		Platform:    models.PlatformYouTube,
+		ChannelName: name,
+		ID:          item.ID,
+		AvatarURL:   avatarURL,
+	}, nil
+}
+
+// isChannelID reports whether s looks like a channel ID rather than a handle.
+func isChannelID(s string) bool {
+	return len(s) == 24 && strings.HasPrefix(s, "UC")
+}
+
+// uploadsPlaylist returns the ID of the playlist holding every upload of the channel,
+// live VODs included. YouTube derives it from the channel ID.
+func uploadsPlaylist(channelID string) string {
+	return "UU" + strings.TrimPrefix(channelID, "UC")
+}
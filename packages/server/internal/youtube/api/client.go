@@ -0,0 +1,154 @@
+// Package api is a client for the YouTube Data API v3 that maps YouTube channels, videos and
+// live streams onto the shared channel models.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/galchammat/kadeem/internal/logging"
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+const (
+	// defaultBaseURL is the YouTube Data API, see WithBaseURL.
+	defaultBaseURL = "https://www.googleapis.com/youtube/v3"
+	// defaultMaxPages bounds a backfill of a channel's uploads to 1000 videos.
+	defaultMaxPages = 20
+	// maxResults is the largest page and the most IDs a list request accepts.
+	maxResults = 50
+)
+
+type YouTubeClient struct {
+	ctx        context.Context
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	maxPages   int
+}
+
+type clientConfig struct {
+	apiKey   string
+	baseURL  string
+	maxPages int
+}
+
+// ClientOption configures a YouTubeClient.
+type ClientOption func(*clientConfig)
+
+// WithBaseURL sends requests to baseURL (default https://www.googleapis.com/youtube/v3).
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *clientConfig) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithAPIKey replaces the YOUTUBE_API_KEY key.
+func WithAPIKey(apiKey string) ClientOption {
+	return func(c *clientConfig) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithMaxPages limits how many pages a single fetch follows (default 20, 0 for no limit).
+func WithMaxPages(maxPages int) ClientOption {
+	return func(c *clientConfig) {
+		c.maxPages = maxPages
+	}
+}
+
+func NewYouTubeClient(ctx context.Context, opts ...ClientOption) *YouTubeClient {
+	cfg := clientConfig{
+		apiKey:   os.Getenv("YOUTUBE_API_KEY"),
+		baseURL:  defaultBaseURL,
+		maxPages: defaultMaxPages,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &YouTubeClient{
+		ctx:        ctx,
+		httpClient: http.DefaultClient,
+		baseURL:    cfg.baseURL,
+		apiKey:     cfg.apiKey,
+		maxPages:   cfg.maxPages,
+	}
+}
+
+// Name returns the platform name channels on YouTube are stored with.
+func (c *YouTubeClient) Name() string {
+	return models.PlatformYouTube
+}
+
+// listResponse is the envelope of every list method of the Data API.
+type listResponse[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// get lists resource with params and decodes the response into v.
+func (c *YouTubeClient) get(resource string, params url.Values, v any) error {
+	query := url.Values{}
+	for k, vs := range params {
+		query[k] = vs
+	}
+	query.Set("key", c.apiKey)
+	endpoint := fmt.Sprintf("%s/%s?%s", c.baseURL, resource, query.Encode())
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logging.Error("YouTube HTTP request failed", "resource", resource, "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read YouTube response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logging.Error("YouTube API returned non-2xx status", "resource", resource, "statusCode", resp.StatusCode, "body", string(body))
+		return fmt.Errorf("HTTP request failed with status %d. body %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid YouTube response: %w", err)
+	}
+	return nil
+}
+
+// list follows nextPageToken until the last page, the client's page limit or stop returns
+// true for an item. That item and everything after it are left out.
+func list[T any](c *YouTubeClient, resource string, params url.Values, stop func(T) bool) ([]T, error) {
+	params.Set("maxResults", fmt.Sprint(maxResults))
+	var items []T
+	for page := 1; ; page++ {
+		var resp listResponse[T]
+		if err := c.get(resource, params, &resp); err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Items {
+			if stop != nil && stop(item) {
+				return items, nil
+			}
+			items = append(items, item)
+		}
+		if resp.NextPageToken == "" {
+			return items, nil
+		}
+		if c.maxPages > 0 && page >= c.maxPages {
+			logging.Warn("Stopped paging YouTube results at the page limit", "resource", resource, "pages", page)
+			return items, nil
+		}
+		params.Set("pageToken", resp.NextPageToken)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChannelID = "UCkadeemtest0000000000aa"
+
+var testVideos = map[string]string{
+	"vod1": `{"id":"vod1","snippet":{"channelId":"UCkadeemtest0000000000aa","title":"ranked grind","publishedAt":"2026-01-07T22:00:00Z",
+		"liveBroadcastContent":"none","thumbnails":{"high":{"url":"https://i.ytimg.com/vi/vod1/hq.jpg"}}},
+		"contentDetails":{"duration":"PT4H2M5S"},"status":{"privacyStatus":"public"},
+		"liveStreamingDetails":{"actualStartTime":"2026-01-07T18:00:00Z","actualEndTime":"2026-01-07T22:02:05Z"}}`,
+	"upload": `{"id":"upload","snippet":{"channelId":"UCkadeemtest0000000000aa","title":"best of","publishedAt":"2026-01-05T12:00:00Z",
+		"liveBroadcastContent":"none","thumbnails":{"default":{"url":"https://i.ytimg.com/vi/upload/default.jpg"}}},
+		"contentDetails":{"duration":"PT12M"},"status":{"privacyStatus":"unlisted"}}`,
+	"live": `{"id":"live","snippet":{"channelId":"UCkadeemtest0000000000aa","title":"live now","publishedAt":"2026-01-08T18:00:00Z",
+		"liveBroadcastContent":"live"},"contentDetails":{"duration":"P0D"},"status":{"privacyStatus":"public"},
+		"liveStreamingDetails":{"actualStartTime":"2026-01-08T18:00:05Z","concurrentViewers":"1234"}}`,
+}
+
+// fakeYouTube serves the uploads playlist live, vod1, upload, old, one item per page.
+func fakeYouTube(t *testing.T) *YouTubeClient {
+	t.Helper()
+	uploads := []struct{ id, publishedAt string }{
+		{"live", "2026-01-08T18:00:00Z"},
+		{"vod1", "2026-01-07T22:00:00Z"},
+		{"upload", "2026-01-05T12:00:00Z"},
+		{"old", "2025-06-01T00:00:00Z"},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("id") != testChannelID && q.Get("forHandle") != "@kadeemtest" {
+			_, _ = w.Write([]byte(`{"items":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":"UCkadeemtest0000000000aa","snippet":{"title":"Kadeem Test Channel",
+			"customUrl":"@kadeemtest","thumbnails":{"default":{"url":"https://yt3.ggpht.com/avatar.jpg"}}}}]}`))
+	})
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		require.Equal(t, "UUkadeemtest0000000000aa", q.Get("playlistId"))
+		page := 0
+		if token := q.Get("pageToken"); token != "" {
+			page = int(token[0] - '0')
+		}
+		resp := map[string]any{"items": []any{map[string]any{"contentDetails": map[string]string{
+			"videoId": uploads[page].id, "videoPublishedAt": uploads[page].publishedAt,
+		}}}}
+		if page+1 < len(uploads) {
+			resp["nextPageToken"] = string(rune('0' + page + 1))
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+	mux.HandleFunc("/youtube/v3/videos", func(w http.ResponseWriter, r *http.Request) {
+		var items []string
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			if v, ok := testVideos[id]; ok {
+				items = append(items, v)
+			}
+		}
+		_, _ = w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `]}`))
+	})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" {
+			http.Error(w, `{"error":{"code":403,"message":"API key not valid"}}`, http.StatusForbidden)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return NewYouTubeClient(context.Background(), WithBaseURL(ts.URL+"/youtube/v3"), WithAPIKey("test-key"))
+}
+
+func TestFindChannel(t *testing.T) {
+	c := fakeYouTube(t)
+	want := models.Channel{
+		ID:          testChannelID,
+		StreamerID:  7,
+		Platform:    models.PlatformYouTube,
+		ChannelName: "@kadeemtest",
+		AvatarURL:   "https://yt3.ggpht.com/avatar.jpg",
+	}
+
+	for _, query := range []models.Channel{
+		{StreamerID: 7, ChannelName: "kadeemtest"},
+		{StreamerID: 7, ChannelName: "@kadeemtest"},
+		{StreamerID: 7, ID: testChannelID},
+	} {
+		got, err := c.FindChannel(query)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := c.FindChannel(models.Channel{ChannelName: "nobody"})
+	assert.ErrorContains(t, err, `no youtube channel named "nobody"`)
+}
+
+func TestFetchBroadcasts(t *testing.T) {
+	c := fakeYouTube(t)
+
+	broadcasts, err := c.FetchBroadcasts(testChannelID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Broadcast{
+		{
+			ChannelID:    testChannelID,
+			Title:        "ranked grind",
+			URL:          "https://www.youtube.com/watch?v=vod1",
+			ThumbnailURL: "https://i.ytimg.com/vi/vod1/hq.jpg",
+			Viewable:     "public",
+			CreatedAt:    1767808800,
+			PublishedAt:  1767823200,
+			Duration:     4*3600 + 2*60 + 5,
+		},
+		{
+			ChannelID:    testChannelID,
+			Title:        "best of",
+			URL:          "https://www.youtube.com/watch?v=upload",
+			ThumbnailURL: "https://i.ytimg.com/vi/upload/default.jpg",
+			Viewable:     "unlisted",
+			CreatedAt:    1767614400,
+			PublishedAt:  1767614400,
+			Duration:     12 * 60,
+		},
+	}, broadcasts)
+
+	// Paging stops at the first upload older than the watermark.
+	broadcasts, err = c.FetchBroadcasts(testChannelID, 1767700000)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 1)
+	assert.Equal(t, "ranked grind", broadcasts[0].Title)
+}
+
+func TestFetchLiveStreams(t *testing.T) {
+	c := fakeYouTube(t)
+	streams, err := c.FetchLiveStreams([]string{testChannelID})
+	require.NoError(t, err)
+	assert.Equal(t, []models.LiveStream{{
+		ID:          "live",
+		UserID:      testChannelID,
+		Type:        "live",
+		Title:       "live now",
+		ViewerCount: 1234,
+		StartedAt:   "2026-01-08T18:00:05Z",
+	}}, streams)
+}
+
+func TestRejectsBadKey(t *testing.T) {
+	c := fakeYouTube(t)
+	c.apiKey = "wrong"
+	_, err := c.FetchBroadcasts(testChannelID, 0)
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, want := range map[string]int64{
+		"PT4H2M5S": 4*3600 + 2*60 + 5,
+		"PT12M":    720,
+		"PT45S":    45,
+		"P1DT1S":   86401,
+		"P0D":      0,
+	} {
+		got, err := parseDuration(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	_, err := parseDuration("4:02:05")
+	assert.Error(t, err)
+}
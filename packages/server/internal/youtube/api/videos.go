@@ -0,0 +1,208 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galchammat/kadeem/internal/twitch/models"
+)
+
+// recentUploads is how many of a channel's latest uploads are checked for a live stream.
+const recentUploads = 5
+
+type playlistItem struct {
+	ContentDetails struct {
+		VideoID          string `json:"videoId"`
+		VideoPublishedAt string `json:"videoPublishedAt"`
+	} `json:"contentDetails"`
+}
+
+type videoItem struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		ChannelID            string     `json:"channelId"`
+		Title                string     `json:"title"`
+		PublishedAt          string     `json:"publishedAt"`
+		LiveBroadcastContent string     `json:"liveBroadcastContent"`
+		Thumbnails           thumbnails `json:"thumbnails"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		Duration string `json:"duration"`
+	} `json:"contentDetails"`
+	Status struct {
+		PrivacyStatus string `json:"privacyStatus"`
+	} `json:"status"`
+	LiveStreamingDetails *struct {
+		ActualStartTime   string `json:"actualStartTime"`
+		ActualEndTime     string `json:"actualEndTime"`
+		ConcurrentViewers string `json:"concurrentViewers"`
+	} `json:"liveStreamingDetails"`
+}
+
+// FetchBroadcasts fetches the channel's uploads, live VODs included, newest first. Paging
+// stops at the first upload published before startTime (Unix seconds); a startTime of 0
+// backfills up to the client's page limit. Streams that are live or upcoming are left out.
+func (c *YouTubeClient) FetchBroadcasts(channelID string, startTime int64) ([]models.Broadcast, error) {
+	params := url.Values{}
+	params.Set("part", "contentDetails")
+	params.Set("playlistId", uploadsPlaylist(channelID))
+	uploads, err := list(c, "playlistItems", params, func(item playlistItem) bool {
+		return unixTime(item.ContentDetails.VideoPublishedAt) < startTime
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch uploads: %w", err)
+	}
+
+	videos, err := c.fetchVideos(uploads, "snippet,contentDetails,status,liveStreamingDetails")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch broadcasts: %w", err)
+	}
+
+	var broadcasts []models.Broadcast
+	for _, v := range videos {
+		if v.Snippet.LiveBroadcastContent != "none" {
+			continue
+		}
+		createdAt := unixTime(v.Snippet.PublishedAt)
+		if v.LiveStreamingDetails != nil && v.LiveStreamingDetails.ActualStartTime != "" {
+			createdAt = unixTime(v.LiveStreamingDetails.ActualStartTime)
+		}
+		duration, err := parseDuration(v.ContentDetails.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("video %s: %w", v.ID, err)
+		}
+		broadcasts = append(broadcasts, models.Broadcast{
+			ChannelID:    channelID,
+			Title:        v.Snippet.Title,
+			URL:          "https://www.youtube.com/watch?v=" + v.ID,
+			ThumbnailURL: v.Snippet.Thumbnails.url(),
+			Viewable:     v.Status.PrivacyStatus,
+			CreatedAt:    createdAt,
+			PublishedAt:  unixTime(v.Snippet.PublishedAt),
+			Duration:     models.DurationSeconds(duration),
+		})
+	}
+	return broadcasts, nil
+}
+
+// FetchEvents returns no events: the Data API exposes nothing like Twitch clips or hype
+// trains.
+func (c *YouTubeClient) FetchEvents(channelID string, startTime int64) ([]models.StreamEvent, error) {
+	return nil, nil
+}
+
+// FetchLiveStreams returns the streams of the given channels that are live right now. A
+// stream shows up among its channel's uploads once it starts, so only the latest uploads of
+// each channel are checked; this costs two quota units per channel instead of a search's 100.
+func (c *YouTubeClient) FetchLiveStreams(channelIDs []string) ([]models.LiveStream, error) {
+	var uploads []playlistItem
+	for _, channelID := range channelIDs {
+		params := url.Values{}
+		params.Set("part", "contentDetails")
+		params.Set("playlistId", uploadsPlaylist(channelID))
+		params.Set("maxResults", strconv.Itoa(recentUploads))
+		var resp listResponse[playlistItem]
+		if err := c.get("playlistItems", params, &resp); err != nil {
+			return nil, fmt.Errorf("fetch recent uploads of channel %s: %w", channelID, err)
+		}
+		uploads = append(uploads, resp.Items...)
+	}
+
+	videos, err := c.fetchVideos(uploads, "snippet,liveStreamingDetails")
+	if err != nil {
+		return nil, fmt.Errorf("fetch live streams: %w", err)
+	}
+
+	var streams []models.LiveStream
+	for _, v := range videos {
+		if v.Snippet.LiveBroadcastContent != "live" || v.LiveStreamingDetails == nil {
+			continue
+		}
+		viewers, _ := strconv.Atoi(v.LiveStreamingDetails.ConcurrentViewers)
+		streams = append(streams, models.LiveStream{
+			ID:          v.ID,
+			UserID:      v.Snippet.ChannelID,
+			Type:        "live",
+			Title:       v.Snippet.Title,
+			ViewerCount: viewers,
+			StartedAt:   v.LiveStreamingDetails.ActualStartTime,
+		})
+	}
+	return streams, nil
+}
+
+// fetchVideos returns the videos of the playlist items in their order. Videos that were
+// deleted or made private since are missing.
+func (c *YouTubeClient) fetchVideos(items []playlistItem, part string) ([]videoItem, error) {
+	byID := make(map[string]videoItem, len(items))
+	for start := 0; start < len(items); start += maxResults {
+		var ids []string
+		for _, item := range items[start:min(start+maxResults, len(items))] {
+			ids = append(ids, item.ContentDetails.VideoID)
+		}
+		params := url.Values{}
+		params.Set("part", part)
+		params.Set("id", strings.Join(ids, ","))
+		var resp listResponse[videoItem]
+		if err := c.get("videos", params, &resp); err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Items {
+			byID[v.ID] = v
+		}
+	}
+
+	videos := make([]videoItem, 0, len(byID))
+	for _, item := range items {
+		if v, ok := byID[item.ContentDetails.VideoID]; ok {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
+
+func (t thumbnails) url() string {
+	switch {
+	case t.High != nil:
+		return t.High.URL
+	case t.Default != nil:
+		return t.Default.URL
+	default:
+		return ""
+	}
+}
+
+// unixTime parses an RFC 3339 timestamp, returning 0 if it is missing or malformed.
+func unixTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an ISO 8601 video duration such as PT1H2M3S into seconds.
+func parseDuration(s string) (int64, error) {
+	m := isoDuration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var seconds int64
+	for i, unit := range []int64{24 * 60 * 60, 60 * 60, 60, 1} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(m[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		seconds += n * unit
+	}
+	return seconds, nil
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_lol_matches_status_next_attempt;
+
+ALTER TABLE lol_matches
+	DROP COLUMN IF EXISTS next_attempt_at,
+	DROP COLUMN IF EXISTS last_error,
+	DROP COLUMN IF EXISTS attempts;
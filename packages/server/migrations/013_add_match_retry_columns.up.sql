@@ -0,0 +1,7 @@
+ALTER TABLE lol_matches
+	ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS last_error TEXT,
+	ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_lol_matches_status_next_attempt
+    ON lol_matches(status, next_attempt_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_lol_matches_lease_expires_at;
+
+ALTER TABLE lol_matches
+	DROP COLUMN IF EXISTS lease_expires_at,
+	DROP COLUMN IF EXISTS leased_by;
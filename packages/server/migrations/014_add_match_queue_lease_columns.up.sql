@@ -0,0 +1,11 @@
+ALTER TABLE lol_matches
+	ADD COLUMN IF NOT EXISTS leased_by TEXT,
+	ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+
+-- Statuses written by the old ack/nack helpers.
+UPDATE lol_matches SET status = 'done' WHERE status = 'completed';
+UPDATE lol_matches SET status = 'retry', next_attempt_at = NOW() WHERE status = 'failed';
+
+CREATE INDEX IF NOT EXISTS idx_lol_matches_lease_expires_at
+    ON lol_matches(lease_expires_at)
+    WHERE status = 'processing';
@@ -0,0 +1,3 @@
+UPDATE lol_matches
+SET replay_uri = 'bin/replays/' || substring(replay_uri FROM length('local://') + 1)
+WHERE replay_uri LIKE 'local://%';
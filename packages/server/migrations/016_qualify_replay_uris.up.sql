@@ -0,0 +1,5 @@
+-- Replays used to be stored as raw paths of the form <dir>/<match id>.rofl on the daemon host.
+UPDATE lol_matches
+SET replay_uri = 'local://' || regexp_replace(replay_uri, '^.*/', '')
+WHERE replay_uri IS NOT NULL
+  AND replay_uri NOT LIKE '%://%';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS live_sessions (
+    id          BIGSERIAL PRIMARY KEY,
+    channel_id  VARCHAR(30) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    stream_id   VARCHAR(30) NOT NULL,
+    title       TEXT NOT NULL DEFAULT '',
+    category    TEXT NOT NULL DEFAULT '',
+    started_at  BIGINT NOT NULL,
+    ended_at    BIGINT,
+    CONSTRAINT live_sessions_channel_stream_unique UNIQUE (channel_id, stream_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_live_sessions_channel_time
+    ON live_sessions(channel_id, started_at DESC);
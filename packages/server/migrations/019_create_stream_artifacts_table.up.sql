@@ -0,0 +1,26 @@
+-- Raw footage downloaded from a platform: clips, and VOD segments around match highlights.
+-- source_id is the clip slug or the video ID; start_offset and duration (seconds) locate a
+-- segment in its VOD and are 0 for clips.
+CREATE TABLE IF NOT EXISTS stream_artifacts (
+    id              BIGSERIAL PRIMARY KEY,
+    kind            VARCHAR(20) NOT NULL,
+    channel_id      VARCHAR(30) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    source_id       VARCHAR(100) NOT NULL,
+    match_id        BIGINT REFERENCES lol_matches(id) ON DELETE CASCADE,
+    start_offset    INTEGER NOT NULL DEFAULT 0,
+    duration        INTEGER NOT NULL DEFAULT 0,
+    title           TEXT NOT NULL DEFAULT '',
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at BIGINT NOT NULL DEFAULT 0,
+    storage_key     TEXT NOT NULL DEFAULT '',
+    size            BIGINT NOT NULL DEFAULT 0,
+    created_at      BIGINT NOT NULL,
+    updated_at      BIGINT NOT NULL,
+    CONSTRAINT stream_artifacts_source_unique UNIQUE (kind, source_id, start_offset, duration)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stream_artifacts_status_next_attempt
+    ON stream_artifacts(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_stream_artifacts_channel_id ON stream_artifacts(channel_id);
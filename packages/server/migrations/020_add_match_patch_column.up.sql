@@ -0,0 +1,2 @@
+-- Patch the match was played on, e.g. "15.24" for game version 15.24.734.7485.
+ALTER TABLE lol_matches ADD COLUMN IF NOT EXISTS patch VARCHAR(10);
@@ -0,0 +1,10 @@
+-- Team of the participant (100 or 200 on Summoner's Rift) and its assigned position, as
+-- teamId and teamPosition in match-v5. Arena and other queues have more than two teams, so
+-- neither the win flag nor the participant number tells teams apart.
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS team_id INTEGER;
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS team_position TEXT;
+
+-- Rows stored before this migration: in a ten-player match participants 1-5 are team 100.
+UPDATE participants SET team_id = CASE WHEN participant_id <= 5 THEN 100 ELSE 200 END
+WHERE team_id IS NULL
+	AND match_id IN (SELECT match_id FROM participants GROUP BY match_id HAVING COUNT(*) = 10);
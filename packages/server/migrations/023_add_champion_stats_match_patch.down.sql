@@ -0,0 +1 @@
+ALTER TABLE champion_stats_matches DROP COLUMN IF EXISTS patch;
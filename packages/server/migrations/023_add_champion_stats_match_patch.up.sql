@@ -0,0 +1,8 @@
+-- Patch a match was counted under in champion_stats, '' while it was unknown. A refresh moves
+-- such matches to their patch once the match has one.
+ALTER TABLE champion_stats_matches ADD COLUMN IF NOT EXISTS patch VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Matches counted so far may have been counted under '' and cannot be told apart, so the
+-- stats are rebuilt from participants by the next refresh.
+DELETE FROM champion_stats;
+DELETE FROM champion_stats_matches;
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS streamers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(30) UNIQUE NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS lol_accounts;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS lol_accounts (
+    puuid VARCHAR(78) NOT NULL PRIMARY KEY,
+    streamer_id INTEGER NOT NULL REFERENCES streamers (id) ON DELETE CASCADE,
+    tag_line VARCHAR(5),
+    game_name VARCHAR(16),
+    region VARCHAR(4),
+    synced_at BIGINT
+);
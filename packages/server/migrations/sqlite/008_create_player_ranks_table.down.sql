@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_player_ranks_puuid_time;
+DROP INDEX IF EXISTS idx_player_ranks_lookup;
+DROP TABLE IF EXISTS player_ranks;
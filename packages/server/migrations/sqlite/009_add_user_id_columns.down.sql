@@ -0,0 +1,3 @@
+-- Drop junction tables
+DROP TABLE IF EXISTS user_tracked_streamers;
+DROP TABLE IF EXISTS user_tracked_accounts;
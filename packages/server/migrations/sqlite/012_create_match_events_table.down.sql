@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_match_events_participant_time;
+DROP TABLE IF EXISTS match_events;
@@ -0,0 +1,20 @@
+-- assist_ids holds a Postgres style array literal such as {2,5}.
+CREATE TABLE IF NOT EXISTS match_events (
+    match_id BIGINT NOT NULL REFERENCES lol_matches(id) ON DELETE CASCADE,
+    event_index INTEGER NOT NULL,
+    participant_id INTEGER NOT NULL,
+    time BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    victim_id INTEGER NOT NULL DEFAULT 0,
+    assist_ids TEXT NOT NULL DEFAULT '{}',
+    team_id INTEGER NOT NULL DEFAULT 0,
+    item_id INTEGER NOT NULL DEFAULT 0,
+    level INTEGER NOT NULL DEFAULT 0,
+    subtype TEXT NOT NULL DEFAULT '',
+    position_x INTEGER,
+    position_y INTEGER,
+    PRIMARY KEY (match_id, event_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_events_participant_time
+    ON match_events(match_id, participant_id, time);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_lol_matches_status_next_attempt;
+
+ALTER TABLE lol_matches DROP COLUMN next_attempt_at;
+ALTER TABLE lol_matches DROP COLUMN last_error;
+ALTER TABLE lol_matches DROP COLUMN attempts;
@@ -0,0 +1,6 @@
+ALTER TABLE lol_matches ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE lol_matches ADD COLUMN last_error TEXT;
+ALTER TABLE lol_matches ADD COLUMN next_attempt_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_lol_matches_status_next_attempt
+    ON lol_matches(status, next_attempt_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_lol_matches_lease_expires_at;
+
+ALTER TABLE lol_matches DROP COLUMN lease_expires_at;
+ALTER TABLE lol_matches DROP COLUMN leased_by;
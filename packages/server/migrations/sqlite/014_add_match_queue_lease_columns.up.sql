@@ -0,0 +1,10 @@
+ALTER TABLE lol_matches ADD COLUMN leased_by TEXT;
+ALTER TABLE lol_matches ADD COLUMN lease_expires_at DATETIME;
+
+-- Statuses written by the old ack/nack helpers.
+UPDATE lol_matches SET status = 'done' WHERE status = 'completed';
+UPDATE lol_matches SET status = 'retry', next_attempt_at = CURRENT_TIMESTAMP WHERE status = 'failed';
+
+CREATE INDEX IF NOT EXISTS idx_lol_matches_lease_expires_at
+    ON lol_matches(lease_expires_at)
+    WHERE status = 'processing';
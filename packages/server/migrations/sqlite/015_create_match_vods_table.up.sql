@@ -0,0 +1,14 @@
+-- One row per (match, broadcast) overlap; a match that spans two broadcasts has two rows.
+CREATE TABLE IF NOT EXISTS match_vods (
+    match_id     BIGINT NOT NULL REFERENCES lol_matches(id) ON DELETE CASCADE,
+    broadcast_id INTEGER NOT NULL REFERENCES broadcasts(id) ON DELETE CASCADE,
+    streamer_id  INTEGER NOT NULL REFERENCES streamers(id) ON DELETE CASCADE,
+    puuid        VARCHAR(78) NOT NULL,
+    vod_offset   INTEGER NOT NULL,
+    match_offset INTEGER NOT NULL DEFAULT 0,
+    overlap      INTEGER NOT NULL,
+    url          TEXT NOT NULL,
+    PRIMARY KEY (match_id, broadcast_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_vods_streamer_id ON match_vods(streamer_id);
@@ -0,0 +1,6 @@
+-- Replays used to be stored as raw paths of the form <dir>/<match id>.rofl on the daemon host.
+-- rtrim(uri, replace(uri, '/', '')) is everything up to the last slash.
+UPDATE lol_matches
+SET replay_uri = 'local://' || substr(replay_uri, length(rtrim(replay_uri, replace(replay_uri, '/', ''))) + 1)
+WHERE replay_uri IS NOT NULL
+  AND replay_uri NOT LIKE '%://%';
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS live_viewer_samples (
+    session_id   BIGINT NOT NULL REFERENCES live_sessions(id) ON DELETE CASCADE,
+    sampled_at   BIGINT NOT NULL,
+    viewer_count INTEGER NOT NULL,
+    PRIMARY KEY (session_id, sampled_at)
+);
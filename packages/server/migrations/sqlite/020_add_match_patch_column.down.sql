@@ -0,0 +1 @@
+ALTER TABLE lol_matches DROP COLUMN patch;
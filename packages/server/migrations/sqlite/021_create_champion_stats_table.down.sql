@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS champion_stats_matches;
+DROP TABLE IF EXISTS champion_stats;
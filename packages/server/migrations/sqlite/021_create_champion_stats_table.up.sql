@@ -0,0 +1,27 @@
+-- Running totals of an account's games per champion, lane, queue, patch and UTC day
+-- (days since the epoch). Matches are added once, see champion_stats_matches.
+CREATE TABLE IF NOT EXISTS champion_stats (
+    puuid        VARCHAR(78) NOT NULL,
+    champion_id  INTEGER NOT NULL,
+    lane         TEXT NOT NULL,
+    queue_id     INTEGER NOT NULL,
+    patch        VARCHAR(10) NOT NULL,
+    day          INTEGER NOT NULL,
+    games        INTEGER NOT NULL,
+    wins         INTEGER NOT NULL,
+    kills        INTEGER NOT NULL,
+    deaths       INTEGER NOT NULL,
+    assists      INTEGER NOT NULL,
+    cs           BIGINT NOT NULL,
+    damage       BIGINT NOT NULL,
+    champ_levels INTEGER NOT NULL,
+    duration     BIGINT NOT NULL,
+    PRIMARY KEY (puuid, champion_id, lane, queue_id, patch, day)
+);
+
+-- Matches already counted in champion_stats.
+CREATE TABLE IF NOT EXISTS champion_stats_matches (
+    puuid    VARCHAR(78) NOT NULL,
+    match_id BIGINT NOT NULL REFERENCES lol_matches(id) ON DELETE CASCADE,
+    PRIMARY KEY (puuid, match_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE participants DROP COLUMN team_position;
+ALTER TABLE participants DROP COLUMN team_id;
@@ -6,10 +6,25 @@ import (
 
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	riotapi "github.com/galchammat/kadeem/internal/riot/api"
-	riotpostgres "github.com/galchammat/kadeem/internal/riot/postgres"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	"github.com/galchammat/kadeem/internal/riot/riottest"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 	"github.com/galchammat/kadeem/internal/service"
 )
 
+// newFakeRiotClient returns a client for a fake Riot API that serves the recorded fixtures and
+// the accounts the tests add, so the tests never call the production API.
+func newFakeRiotClient(t *testing.T) *riotapi.Client {
+	t.Helper()
+	server := riottest.NewServer()
+	t.Cleanup(server.Close)
+	if err := server.LoadFixtures("../data/riot/raw"); err != nil {
+		t.Fatalf("Failed to load Riot fixtures: %v", err)
+	}
+	server.AddAccount(riot.Account{PUUID: "the-thirsty-rock", GameName: "the thirsty rock", TagLine: "NA1"})
+	return server.Client()
+}
+
 func testListRiotAccounts(t *testing.T) {
 	if os.Getenv("RUN_INTEGRATION_TESTS") != "true" {
 		t.Skip("Skipping integration test; set RUN_INTEGRATION_TESTS=true to run it")
@@ -20,9 +35,9 @@ func testListRiotAccounts(t *testing.T) {
 		t.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.SQL.Close()
-	store := riotpostgres.New(db)
+	store := riotstore.New(db)
 
-	accountSvc := service.NewAccountService(store, riotapi.NewClient())
+	accountSvc := service.NewAccountService(store, newFakeRiotClient(t))
 	accounts, err := accountSvc.ListAccounts(nil)
 	if err != nil {
 		t.Fatalf("Failed to list accounts: %v", err)
@@ -41,9 +56,9 @@ func testAddRiotAccount(t *testing.T) {
 		t.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.SQL.Close()
-	store := riotpostgres.New(db)
+	store := riotstore.New(db)
 
-	accountSvc := service.NewAccountService(store, riotapi.NewClient())
+	accountSvc := service.NewAccountService(store, newFakeRiotClient(t))
 	err = accountSvc.AddAccount("NA", "the thirsty rock", "NA1", 0)
 	if err != nil {
 		t.Fatalf("Failed to add account: %v", err)
@@ -5,9 +5,9 @@ import (
 	"testing"
 
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
-	riotapi "github.com/galchammat/kadeem/internal/riot/api"
 	riot "github.com/galchammat/kadeem/internal/riot/models"
-	riotpostgres "github.com/galchammat/kadeem/internal/riot/postgres"
+	"github.com/galchammat/kadeem/internal/riot/replay"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
 	"github.com/galchammat/kadeem/internal/service"
 	"github.com/galchammat/kadeem/tests/logs"
 )
@@ -23,9 +23,9 @@ func TestListLolMatches(t *testing.T) {
 		tlog.Fatalf("Failed to open database", "error", err)
 	}
 	defer db.SQL.Close()
-	store := riotpostgres.New(db)
+	store := riotstore.New(db)
 
-	matchSvc := service.NewMatchService(store, riotapi.NewClient())
+	matchSvc := service.NewMatchService(store, newFakeRiotClient(t), replay.NewLocalStore(replay.DefaultDir()))
 	testPuuid := "OXR0AfpBu2Z-fFGu8KCE1sNzJLJbTpgClA42okBn-VsEVTwjJwMZu306s5JTLBmxPkVe2SSBIGe9ww"
 
 	account, err := store.GetRiotAccount(testPuuid)
@@ -0,0 +1,349 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	platformdb "github.com/galchammat/kadeem/internal/platform/database"
+	riot "github.com/galchammat/kadeem/internal/riot/models"
+	riotstore "github.com/galchammat/kadeem/internal/riot/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStatsStore returns a migrated, empty riot store on the backend of DATABASE_URL with the
+// streamers 1 "streamer" and 2 "rival". On Postgres it lives in a schema of its own that is
+// dropped after the test; a SQLite DATABASE_URL gets a throwaway in-memory database.
+func newStatsStore(t *testing.T) riotstore.Store {
+	t.Helper()
+	if os.Getenv("RUN_INTEGRATION_TESTS") != "true" {
+		t.Skip("Skipping integration test; set RUN_INTEGRATION_TESTS=true to run it")
+	}
+
+	var db *platformdb.DB
+	dbURL := os.Getenv("DATABASE_URL")
+	if strings.HasPrefix(dbURL, "sqlite://") {
+		var err error
+		db, err = platformdb.OpenSQLite(":memory:")
+		require.NoError(t, err)
+	} else {
+		db = openTestSchema(t, dbURL)
+	}
+	t.Cleanup(func() { db.SQL.Close() })
+
+	m, err := platformdb.NewMigrate(db, "../../migrations")
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	_, err = db.SQL.Exec(`INSERT INTO streamers (id, name) VALUES (1, 'streamer'), (2, 'rival')`)
+	require.NoError(t, err)
+	return riotstore.New(db)
+}
+
+// openTestSchema creates a fresh schema in the Postgres database at dbURL and connects to it.
+func openTestSchema(t *testing.T, dbURL string) *platformdb.DB {
+	t.Helper()
+	admin, err := platformdb.OpenDB()
+	require.NoError(t, err)
+	schema := fmt.Sprintf("kadeem_test_%d", time.Now().UnixNano())
+	_, err = admin.SQL.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = admin.SQL.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.SQL.Close()
+	})
+
+	if u, err := url.Parse(dbURL); err == nil && u.Scheme != "" {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		dbURL = u.String()
+	} else {
+		dbURL += " search_path=" + schema
+	}
+	t.Setenv("DATABASE_URL", dbURL)
+	db, err := platformdb.OpenDB()
+	require.NoError(t, err)
+	return db
+}
+
+func TestStoreChampionStats(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	const day = 86_400_000
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 10 * day, Duration: 1800, QueueID: 420, Patch: "15.9"},
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420, Patch: "15.9"},
+		{ID: 3, Region: "na1", StartedAt: 11 * day, Duration: 200, QueueID: 420, Patch: "15.10"},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true, Kills: 10, Deaths: 2, Assists: 5, TotalMinionsKilled: 240, ChampLevel: 17},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Kills: 2, Deaths: 6, Assists: 3, TotalMinionsKilled: 150, ChampLevel: 14},
+		{GameID: 2, ParticipantID: 2, PUUID: "p2", ChampionID: 157, Lane: "MIDDLE"},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE"},
+	}))
+
+	added, err := s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), added)
+	added, err = s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Zero(t, added)
+
+	totals, err := s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.ChampionStatTotals{{
+		ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 2, Wins: 1, Kills: 12, Deaths: 8, Assists: 8,
+		CS: 390, ChampLevels: 31, Duration: 3000,
+	}}, totals)
+
+	fromDay := int64(11)
+	totals, err = s.ListChampionStats(ctx, "p1", &riot.ChampionStatsFilter{FromDay: &fromDay})
+	require.NoError(t, err)
+	assert.Empty(t, totals)
+}
+
+func TestStoreChampionStatsLatePatch(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	const day = 86_400_000
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 10 * day, Duration: 1800, QueueID: 420, Patch: "15.9"},
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true, Kills: 10, Deaths: 2, ChampLevel: 17},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Kills: 2, Deaths: 6, ChampLevel: 14},
+	}))
+
+	// A match without a patch is counted under an empty one until it gets one.
+	added, err := s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), added)
+	totals, err := s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []riot.ChampionStatTotals{
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 1, Wins: 1, Kills: 10, Deaths: 2, ChampLevels: 17, Duration: 1800},
+		{ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "", Games: 1, Kills: 2, Deaths: 6, ChampLevels: 14, Duration: 1200},
+	}, totals)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 2, Region: "na1", StartedAt: 10*day + 5000, Duration: 1200, QueueID: 420, Patch: "15.9"},
+	}))
+	added, err = s.RefreshChampionStats(ctx, "p1", 300)
+	require.NoError(t, err)
+	assert.Zero(t, added)
+	totals, err = s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.ChampionStatTotals{{
+		ChampionID: 103, Lane: "MIDDLE", QueueID: 420, Patch: "15.9", Games: 2, Wins: 1, Kills: 12, Deaths: 8,
+		ChampLevels: 31, Duration: 3000,
+	}}, totals)
+}
+
+func TestStoreChampionStatsConcurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	const matches = 50
+	summaries := make([]riot.MatchSummary, matches)
+	participants := make([]riot.MatchParticipantSummary, matches)
+	for i := range matches {
+		id := int64(i + 1)
+		summaries[i] = riot.MatchSummary{ID: id, Region: "na1", StartedAt: id * 1000, Duration: 1800, QueueID: 420}
+		participants[i] = riot.MatchParticipantSummary{GameID: id, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", Win: true}
+	}
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, summaries))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, participants))
+
+	// Every match is counted by exactly one of the overlapping refreshes.
+	var wg sync.WaitGroup
+	added := make([]int64, 8)
+	errs := make([]error, len(added))
+	for i := range added {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			added[i], errs[i] = s.RefreshChampionStats(ctx, "p1", 300)
+		}()
+	}
+	wg.Wait()
+
+	var total int64
+	for i := range added {
+		require.NoError(t, errs[i])
+		total += added[i]
+	}
+	assert.Equal(t, int64(matches), total)
+
+	totals, err := s.ListChampionStats(ctx, "p1", nil)
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, matches, totals[0].Games)
+	assert.Equal(t, matches, totals[0].Wins)
+}
+
+func TestStoreLaneMatchups(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 2_000_000, Duration: 1500, QueueID: 420},
+		{ID: 3, Region: "na1", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "na1", StartedAt: 4_000_000, Duration: 1600, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE", Win: true, Kills: 8, Deaths: 1, TotalMinionsKilled: 220, TotalDamageDealtToChampions: 25_000},
+		{GameID: 1, ParticipantID: 2, PUUID: "p2", ChampionID: 238, Lane: "MIDDLE", TeamID: 100, TeamPosition: "JUNGLE", Win: true},
+		{GameID: 1, ParticipantID: 6, PUUID: "e1", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Kills: 2, Deaths: 6, TotalMinionsKilled: 200, TotalDamageDealtToChampions: 15_000},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE", Kills: 1, Deaths: 5, TotalMinionsKilled: 150, TotalDamageDealtToChampions: 10_000},
+		{GameID: 2, ParticipantID: 6, PUUID: "e2", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Win: true, Kills: 5, Deaths: 1, TotalMinionsKilled: 170, TotalDamageDealtToChampions: 20_000},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Lane: "MIDDLE", TeamID: 100, TeamPosition: "MIDDLE"},
+		{GameID: 3, ParticipantID: 6, PUUID: "e3", ChampionID: 157, Lane: "MIDDLE", TeamID: 200, TeamPosition: "MIDDLE", Win: true},
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 1, Lane: "BOTTOM", TeamID: 100, TeamPosition: "BOTTOM", Win: true, Kills: 4},
+		{GameID: 4, ParticipantID: 2, PUUID: "p3", ChampionID: 40, Lane: "BOTTOM", TeamID: 100, TeamPosition: "UTILITY", Win: true},
+		{GameID: 4, ParticipantID: 6, PUUID: "e4", ChampionID: 22, Lane: "BOTTOM", TeamID: 200, TeamPosition: "BOTTOM", Kills: 1},
+		{GameID: 4, ParticipantID: 7, PUUID: "e5", ChampionID: 412, Lane: "BOTTOM", TeamID: 200, TeamPosition: "UTILITY"},
+	}))
+
+	matchups, err := s.ListLaneMatchups(ctx, "p1", 300, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.LaneMatchupTotals{
+		{EnemyChampionID: 157, Lane: "MIDDLE", Games: 2, Wins: 1, KillDiff: 2, DeathDiff: -1, DamageDiff: 0, CSDiff: 0},
+		{EnemyChampionID: 22, Lane: "BOTTOM", Games: 1, Wins: 1, KillDiff: 3},
+	}, matchups)
+
+	supportLane := "UTILITY"
+	matchups, err = s.ListLaneMatchups(ctx, "p3", 300, &riot.MatchupFilter{Lane: &supportLane})
+	require.NoError(t, err)
+	assert.Equal(t, []riot.LaneMatchupTotals{{EnemyChampionID: 412, Lane: "UTILITY", Games: 1, Wins: 1}}, matchups)
+
+	matchups, err = s.ListLaneMatchups(ctx, "p1", 300, &riot.MatchupFilter{MinGames: 3})
+	require.NoError(t, err)
+	assert.Empty(t, matchups)
+}
+
+func TestStoreRankedGames(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	for _, rank := range []riot.PlayerRank{
+		{PUUID: "p1", Timestamp: 100, Tier: "GOLD", Rank: "II", QueueID: 420},
+		{PUUID: "p1", Timestamp: 200, Tier: "GOLD", Rank: "II", QueueID: 420},
+		{PUUID: "p1", Timestamp: 300, Tier: "GOLD", Rank: "I", QueueID: 420},
+		{PUUID: "p1", Timestamp: 400, Tier: "GOLD", Rank: "I", QueueID: 420},
+		{PUUID: "p1", Timestamp: 250, Tier: "SILVER", Rank: "I", QueueID: 440},
+	} {
+		require.NoError(t, s.InsertPlayerRank(&rank))
+	}
+
+	ranks, err := s.ListPlayerRanks(ctx, "p1", 420, 250, 260)
+	require.NoError(t, err)
+	require.Len(t, ranks, 2)
+	assert.Equal(t, int64(200), ranks[0].Timestamp)
+	assert.Equal(t, int64(300), ranks[1].Timestamp)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 150_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 160_000, Duration: 200, QueueID: 420},
+		{ID: 3, Region: "na1", StartedAt: 170_000, Duration: 1800, QueueID: 440},
+		{ID: 4, Region: "na1", StartedAt: 500_000, Duration: 1800, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Win: true},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+	}))
+
+	games, err := s.ListRankedGames(ctx, "p1", 420, 300, 0, 400)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.RankedGame{
+		{MatchID: 1, QueueID: 420, StartedAt: 150_000, Duration: 1800, ChampionID: 103, Win: true},
+	}, games)
+}
+
+func TestStorePlayedGames(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "na1", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "na1", StartedAt: 2_000_000, Duration: 1500, QueueID: 450},
+		{ID: 3, Region: "na1", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "na1", StartedAt: 9_000_000, Duration: 1500, QueueID: 420},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", ChampionID: 103, Win: true, Kills: 5, Deaths: 1, Assists: 7},
+		{GameID: 1, ParticipantID: 6, PUUID: "e1", ChampionID: 157},
+		{GameID: 2, ParticipantID: 1, PUUID: "smurf", ChampionID: 238},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", ChampionID: 103},
+	}))
+
+	games, err := s.ListPlayedGames(ctx, []string{"p1", "smurf"}, 300, 0, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.PlayedGame{
+		{MatchID: 1, PUUID: "p1", QueueID: 420, StartedAt: 1_000_000, Duration: 1800, ChampionID: 103, Win: true, Kills: 5, Deaths: 1, Assists: 7},
+		{MatchID: 2, PUUID: "smurf", QueueID: 450, StartedAt: 2_000_000, Duration: 1500, ChampionID: 238},
+	}, games)
+
+	games, err = s.ListPlayedGames(ctx, nil, 300, 0, 5000)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestStoreTeammates(t *testing.T) {
+	ctx := context.Background()
+	s := newStatsStore(t)
+
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "p1", StreamerID: 1, GameName: "Faker", TagLine: "KR1", Region: "kr"}))
+	require.NoError(t, s.SaveRiotAccount(&riot.Account{PUUID: "r1", StreamerID: 2, GameName: "Rival", TagLine: "KR1", Region: "kr"}))
+
+	require.NoError(t, s.SaveMatchSummaryBatch(ctx, []riot.MatchSummary{
+		{ID: 1, Region: "kr", StartedAt: 1_000_000, Duration: 1800, QueueID: 420},
+		{ID: 2, Region: "kr", StartedAt: 2_000_000, Duration: 1800, QueueID: 420},
+		{ID: 3, Region: "kr", StartedAt: 3_000_000, Duration: 200, QueueID: 420},
+		{ID: 4, Region: "kr", StartedAt: 500_000, Duration: 1200, QueueID: 1700},
+	}))
+	require.NoError(t, s.SaveMatchParticipantBatch(ctx, []riot.MatchParticipantSummary{
+		{GameID: 1, ParticipantID: 1, PUUID: "p1", TeamID: 100, Win: true},
+		{GameID: 1, ParticipantID: 2, PUUID: "duo", TeamID: 100, RiotIDGameName: "OldName", RiotIDTagline: "KR1", Win: true},
+		{GameID: 1, ParticipantID: 6, PUUID: "r1", TeamID: 200},
+		{GameID: 2, ParticipantID: 1, PUUID: "p1", TeamID: 100},
+		{GameID: 2, ParticipantID: 2, PUUID: "duo", TeamID: 100, RiotIDGameName: "Duo", RiotIDTagline: "KR1"},
+		{GameID: 2, ParticipantID: 3, PUUID: "r1", TeamID: 100},
+		{GameID: 2, ParticipantID: 6, PUUID: "enemy", TeamID: 200, Win: true},
+		{GameID: 3, ParticipantID: 1, PUUID: "p1", TeamID: 100},
+		{GameID: 3, ParticipantID: 2, PUUID: "r1", TeamID: 100},
+		// Arena has more than two teams, so losing together does not make teammates.
+		{GameID: 4, ParticipantID: 1, PUUID: "p1", TeamID: 1},
+		{GameID: 4, ParticipantID: 3, PUUID: "duo", TeamID: 2},
+	}))
+
+	teammates, err := s.ListTeammates(ctx, "p1", 300, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, teammates, 2)
+	assert.Equal(t, riot.TeammateTotals{
+		PUUID: "duo", GameName: "Duo", TagLine: "KR1", Region: "kr", Games: 2, Wins: 1, LastSeen: 2_000_000,
+	}, teammates[0])
+	assert.Equal(t, "r1", teammates[1].PUUID)
+	require.NotNil(t, teammates[1].StreamerID)
+	assert.Equal(t, 2, *teammates[1].StreamerID)
+
+	meetings, err := s.ListStreamerMeetings(ctx, 1, 300, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []riot.StreamerMeeting{
+		{MatchID: 2, StartedAt: 2_000_000, QueueID: 420, PUUID: "p1", OtherStreamerID: 2, OtherStreamerName: "rival", OtherPUUID: "r1", SameTeam: true},
+		{MatchID: 1, StartedAt: 1_000_000, QueueID: 420, PUUID: "p1", OtherStreamerID: 2, OtherStreamerName: "rival", OtherPUUID: "r1", SameTeam: false},
+	}, meetings)
+}
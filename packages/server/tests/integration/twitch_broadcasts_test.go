@@ -7,6 +7,7 @@ import (
 
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/streaming"
 	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
 	twitch "github.com/galchammat/kadeem/internal/twitch/models"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
@@ -27,7 +28,7 @@ func TestListBroadcasts(t *testing.T) {
 	store := twitchstore.New(db)
 
 	twitchClient := twitchapi.NewTwitchClient(context.Background())
-	streamerSvc := service.NewStreamerService(store, twitchClient)
+	streamerSvc := service.NewStreamerService(store, streaming.NewRegistry(twitchClient))
 	broadcasts, err := streamerSvc.ListBroadcasts(&twitch.Broadcast{ChannelID: channelID}, limit, offset)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
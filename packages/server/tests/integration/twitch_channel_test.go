@@ -7,6 +7,7 @@ import (
 
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/streaming"
 	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
 	twitch "github.com/galchammat/kadeem/internal/twitch/models"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
@@ -51,7 +52,7 @@ func testAddTwitchChannel(t *testing.T) {
 	store := twitchstore.New(db)
 
 	twitchClient := twitchapi.NewTwitchClient(context.Background())
-	streamerSvc := service.NewStreamerService(store, twitchClient)
+	streamerSvc := service.NewStreamerService(store, streaming.NewRegistry(twitchClient))
 
 	streamers, err := store.ListStreamers(1000, 0)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 
 	platformdb "github.com/galchammat/kadeem/internal/platform/database"
 	"github.com/galchammat/kadeem/internal/service"
+	"github.com/galchammat/kadeem/internal/streaming"
 	twitchapi "github.com/galchammat/kadeem/internal/twitch/api"
 	twitchstore "github.com/galchammat/kadeem/internal/twitch/store"
 )
@@ -26,7 +27,7 @@ func TestSyncStreamEvents(t *testing.T) {
 	store := twitchstore.New(db)
 
 	twitchClient := twitchapi.NewTwitchClient(context.Background())
-	svc := service.NewStreamEventsService(store, twitchClient)
+	svc := service.NewStreamEventsService(store, streaming.NewRegistry(twitchClient))
 
 	t.Run("SyncChannelEvents", func(t *testing.T) {
 		if err := svc.SyncChannelEvents(channelID); err != nil {
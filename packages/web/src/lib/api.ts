@@ -69,6 +69,13 @@ export async function listMatches(puuid: string, limit: number, offset: number):
   return data.matches ?? []
 }
 
+// Signed replay download link, usable without an Authorization header until it expires
+export async function createReplayLink(matchId: number): Promise<{ url: string; expiresAt: number }> {
+  const data = await request<{ url: string; expiresAt: number }>(`/riot/matches/${matchId}/replay/link`, { method: "POST" })
+  const base = import.meta.env.VITE_API_URL || window.location.origin
+  return { url: `${base}${data.url}`, expiresAt: data.expiresAt }
+}
+
 // Riot Ranks
 export async function getPlayerRankAtTime(accountId: string, queueID: number, timestamp: number): Promise<PlayerRank | null> {
   const params = new URLSearchParams({ queueID: String(queueID), timestamp: String(timestamp) })
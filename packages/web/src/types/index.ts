@@ -14,6 +14,18 @@ export interface LolMatch {
   summary: LolMatchSummary
   participants: LolMatchParticipantSummary[]
   replay?: string | null
+  vods?: LolMatchVod[]
+}
+
+export interface LolMatchVod {
+  gameId: number
+  broadcastId: number
+  streamerId: number
+  puuid: string
+  vodOffset: number
+  matchOffset: number
+  overlap: number
+  url: string
 }
 
 export interface LolMatchSummary {